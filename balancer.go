@@ -0,0 +1,294 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// endpointEjectionTime is how long an endpoint is skipped after a call to it
+// fails with CodeUnavailable.
+const endpointEjectionTime = 10 * time.Second
+
+// An Endpoint is a server address known to a load-balancing client, along
+// with its current load.
+type Endpoint struct {
+	// Address is the endpoint's address, as returned by the [Resolver].
+	Address string
+	// Outstanding is the number of requests to the endpoint that are in
+	// flight.
+	Outstanding int
+}
+
+// A Balancer chooses an endpoint for each request made by a load-balancing
+// client. Balancers must be safe to use concurrently.
+type Balancer interface {
+	// Pick returns the index of the endpoint to use. The endpoints slice is
+	// never empty, and endpoints that have recently failed with
+	// CodeUnavailable are omitted.
+	Pick(endpoints []Endpoint) int
+}
+
+// NewRoundRobinBalancer returns a [Balancer] that cycles through the
+// endpoints in order.
+func NewRoundRobinBalancer() Balancer {
+	return &roundRobinBalancer{}
+}
+
+// NewLeastOutstandingBalancer returns a [Balancer] that picks the endpoint
+// with the fewest requests in flight.
+func NewLeastOutstandingBalancer() Balancer {
+	return leastOutstandingBalancer{}
+}
+
+// NewPowerOfTwoChoicesBalancer returns a [Balancer] that picks two endpoints
+// at random and uses the one with fewer requests in flight. It spreads load
+// nearly as well as [NewLeastOutstandingBalancer], but avoids sending every
+// new request to the same endpoint when many clients share a server pool.
+func NewPowerOfTwoChoicesBalancer() Balancer {
+	return powerOfTwoChoicesBalancer{}
+}
+
+type roundRobinBalancer struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobinBalancer) Pick(endpoints []Endpoint) int {
+	return int((b.next.Add(1) - 1) % uint64(len(endpoints)))
+}
+
+type leastOutstandingBalancer struct{}
+
+func (leastOutstandingBalancer) Pick(endpoints []Endpoint) int {
+	best := 0
+	for i, endpoint := range endpoints {
+		if endpoint.Outstanding < endpoints[best].Outstanding {
+			best = i
+		}
+	}
+	return best
+}
+
+type powerOfTwoChoicesBalancer struct{}
+
+func (powerOfTwoChoicesBalancer) Pick(endpoints []Endpoint) int {
+	if len(endpoints) == 1 {
+		return 0
+	}
+	first := rand.IntN(len(endpoints))      //nolint:gosec // load balancing doesn't need a CSPRNG
+	second := rand.IntN(len(endpoints) - 1) //nolint:gosec // load balancing doesn't need a CSPRNG
+	if second >= first {
+		second++
+	}
+	if endpoints[second].Outstanding < endpoints[first].Outstanding {
+		return second
+	}
+	return first
+}
+
+// loadBalancer sends each request to an endpoint chosen by a Balancer from
+// the addresses returned by a Resolver. It's shared by all the clients
+// configured with the same option, so that they see the same load.
+type loadBalancer struct {
+	resolver Resolver
+	balancer Balancer
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointState
+}
+
+type endpointState struct {
+	outstanding  int
+	ejectedUntil time.Time
+}
+
+func newLoadBalancer(resolver Resolver, balancer Balancer) *loadBalancer {
+	if balancer == nil {
+		balancer = NewRoundRobinBalancer()
+	}
+	return &loadBalancer{
+		resolver:  resolver,
+		balancer:  balancer,
+		endpoints: make(map[string]*endpointState),
+	}
+}
+
+// pick chooses an endpoint and records the request as outstanding. Callers
+// must call done when the request ends.
+func (b *loadBalancer) pick() (string, bool) {
+	addresses := b.resolver.Addresses()
+	if len(addresses) == 0 {
+		return "", false
+	}
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(addresses)
+	candidates := make([]Endpoint, 0, len(addresses))
+	for _, address := range addresses {
+		state := b.state(address)
+		if now.Before(state.ejectedUntil) {
+			continue
+		}
+		candidates = append(candidates, Endpoint{Address: address, Outstanding: state.outstanding})
+	}
+	if len(candidates) == 0 {
+		// Every endpoint has been ejected. Rather than failing, fall back to
+		// all of them.
+		for _, address := range addresses {
+			candidates = append(candidates, Endpoint{Address: address, Outstanding: b.state(address).outstanding})
+		}
+	}
+	index := b.balancer.Pick(candidates)
+	if index < 0 || index >= len(candidates) {
+		index = 0
+	}
+	address := candidates[index].Address
+	b.state(address).outstanding++
+	return address, true
+}
+
+// done records the end of a request. If unavailable is true, the endpoint is
+// ejected.
+func (b *loadBalancer) done(address string, unavailable bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.state(address)
+	state.outstanding = max(state.outstanding-1, 0)
+	if unavailable {
+		state.ejectedUntil = time.Now().Add(endpointEjectionTime)
+	}
+}
+
+// state returns the state of an endpoint. Callers must hold the lock.
+func (b *loadBalancer) state(address string) *endpointState {
+	state, ok := b.endpoints[address]
+	if !ok {
+		state = &endpointState{}
+		b.endpoints[address] = state
+	}
+	return state
+}
+
+// prune discards the state of idle endpoints that are no longer resolved.
+// Callers must hold the lock.
+func (b *loadBalancer) prune(addresses []string) {
+	if len(b.endpoints) <= 2*len(addresses) {
+		return
+	}
+	current := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		current[address] = struct{}{}
+	}
+	for address, state := range b.endpoints {
+		if _, ok := current[address]; !ok && state.outstanding == 0 {
+			delete(b.endpoints, address)
+		}
+	}
+}
+
+// balancingHTTPClient is an HTTPClient that sends each request to an
+// endpoint chosen by a loadBalancer.
+type balancingHTTPClient struct {
+	base     HTTPClient
+	balancer *loadBalancer
+}
+
+func (c *balancingHTTPClient) Do(request *http.Request) (*http.Response, error) {
+	address, ok := c.balancer.pick()
+	if !ok {
+		return nil, errorf(CodeUnavailable, "resolver returned no addresses")
+	}
+	// RoundTrippers must not modify the request, so we copy it.
+	target := *request
+	target.URL = endpointURL(request.URL, address)
+	if request.Host == "" || request.Host == request.URL.Host {
+		target.Host = target.URL.Host
+	}
+	response, err := c.base.Do(&target)
+	if err != nil {
+		c.balancer.done(address, request.Context().Err() == nil)
+		return nil, err
+	}
+	if response.StatusCode == http.StatusServiceUnavailable || isUnavailableStatus(response.Header) {
+		c.balancer.done(address, true)
+		return response, nil
+	}
+	response.Body = &balancedBody{
+		ReadCloser: response.Body,
+		response:   response,
+		done: func(unavailable bool) {
+			c.balancer.done(address, unavailable)
+		},
+	}
+	return response, nil
+}
+
+// endpointURL returns a copy of base, sent to the given address. Addresses
+// may be host:port pairs or URLs with a scheme and host.
+func endpointURL(base *url.URL, address string) *url.URL {
+	target := *base
+	if scheme, host, ok := strings.Cut(address, "://"); ok {
+		target.Scheme = scheme
+		target.Host = strings.TrimSuffix(host, "/")
+	} else {
+		target.Host = address
+	}
+	return &target
+}
+
+// isUnavailableStatus reports whether gRPC metadata carries
+// CodeUnavailable.
+func isUnavailableStatus(metadata http.Header) bool {
+	return getHeaderCanonical(metadata, grpcHeaderStatus) == strconv.Itoa(int(CodeUnavailable))
+}
+
+// balancedBody reports the end of a request to the load balancer once the
+// response has been read or closed, so that the gRPC trailers are available.
+type balancedBody struct {
+	io.ReadCloser
+
+	response *http.Response
+	once     sync.Once
+	done     func(unavailable bool)
+}
+
+func (b *balancedBody) Read(data []byte) (int, error) {
+	n, err := b.ReadCloser.Read(data)
+	if err != nil {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *balancedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *balancedBody) finish() {
+	b.once.Do(func() {
+		b.done(isUnavailableStatus(b.response.Trailer))
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/agentio/scalpel/internal/assert"
+)
+
+func TestBalancers(t *testing.T) {
+	t.Parallel()
+	endpoints := []Endpoint{
+		{Address: "a", Outstanding: 3},
+		{Address: "b", Outstanding: 1},
+		{Address: "c", Outstanding: 2},
+	}
+	t.Run("round_robin", func(t *testing.T) {
+		t.Parallel()
+		balancer := NewRoundRobinBalancer()
+		for i := range 6 {
+			assert.Equal(t, balancer.Pick(endpoints), i%3)
+		}
+	})
+	t.Run("least_outstanding", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, NewLeastOutstandingBalancer().Pick(endpoints), 1)
+	})
+	t.Run("power_of_two_choices", func(t *testing.T) {
+		t.Parallel()
+		balancer := NewPowerOfTwoChoicesBalancer()
+		for range 100 {
+			// The busiest endpoint always loses.
+			assert.NotEqual(t, balancer.Pick(endpoints), 0)
+		}
+		assert.Equal(t, balancer.Pick(endpoints[:1]), 0)
+	})
+}
+
+func TestLoadBalancerEjection(t *testing.T) {
+	t.Parallel()
+	balancer := newLoadBalancer(NewStaticResolver("a", "b"), NewLeastOutstandingBalancer())
+	address, ok := balancer.pick()
+	assert.True(t, ok)
+	assert.Equal(t, address, "a")
+	balancer.done(address, true /* unavailable */)
+	for range 3 {
+		address, _ := balancer.pick()
+		assert.Equal(t, address, "b")
+		balancer.done(address, false)
+	}
+	address, _ = balancer.pick()
+	assert.Equal(t, address, "b")
+	balancer.done(address, true /* unavailable */)
+	// With every endpoint ejected, the balancer falls back to all of them.
+	address, ok = balancer.pick()
+	assert.True(t, ok)
+	assert.Equal(t, address, "a")
+}
+
+func TestEndpointURL(t *testing.T) {
+	t.Parallel()
+	base, err := url.Parse("http://example.com/acme.v1.Service/Method")
+	assert.Nil(t, err)
+	assert.Equal(t, endpointURL(base, "10.0.0.1:8080").String(), "http://10.0.0.1:8080/acme.v1.Service/Method")
+	assert.Equal(t, endpointURL(base, "https://10.0.0.1/").String(), "https://10.0.0.1/acme.v1.Service/Method")
+	assert.Equal(t, base.String(), "http://example.com/acme.v1.Service/Method")
+}
@@ -28,8 +28,10 @@ import (
 // Depending on the procedure's type, use the CallUnary, CallClientStream,
 // CallServerStream, or CallBidiStream method.
 //
-// Clients use the gRPC protocol with the binary Protobuf Codec,
-// ask for gzipped responses, and send uncompressed requests.
+// By default, clients use the gRPC protocol with the binary Protobuf Codec,
+// ask for gzipped responses, and send uncompressed requests. To use the
+// Connect or gRPC-Web protocols, use the [WithConnect] or [WithGRPCWeb]
+// options.
 type Client[Req, Res any] struct {
 	config         *clientConfig
 	callUnary      func(context.Context, *Request[Req]) (*Response[Res], error)
@@ -46,18 +48,29 @@ func NewClient[Req, Res any](httpClient HTTPClient, url string, options ...Clien
 		return client
 	}
 	client.config = config
+	if config.LoadBalancer != nil {
+		httpClient = &balancingHTTPClient{base: httpClient, balancer: config.LoadBalancer}
+	}
 	protocolClient, protocolErr := client.config.Protocol.NewClient(
 		&protocolClientParams{
-			Codec:          config.Codec,
-			Protobuf:       config.protobuf(),
-			HTTPClient:     httpClient,
-			URL:            config.URL,
-			BufferPool:     config.BufferPool,
-			ReadMaxBytes:   config.ReadMaxBytes,
-			SendMaxBytes:   config.SendMaxBytes,
-			EnableGet:      config.EnableGet,
-			GetURLMaxBytes: config.GetURLMaxBytes,
-			GetUseFallback: config.GetUseFallback,
+			CompressionName: config.RequestCompressionName,
+			CompressionPools: newReadOnlyCompressionPools(
+				config.CompressionPools,
+				config.CompressionNames,
+			),
+			Codec:            config.Codec,
+			Protobuf:         config.protobuf(),
+			CompressMinBytes: config.CompressMinBytes,
+			HTTPClient:       httpClient,
+			URL:              config.URL,
+			BufferPool:       config.BufferPool,
+			ReadMaxBytes:     config.ReadMaxBytes,
+			SendMaxBytes:     config.SendMaxBytes,
+			EnableGet:        config.EnableGet,
+			GetURLMaxBytes:   config.GetURLMaxBytes,
+			GetUseFallback:   config.GetUseFallback,
+			RetryPolicy:      config.RetryPolicy,
+			HedgingPolicy:    config.HedgingPolicy,
 		},
 	)
 	if protocolErr != nil {
@@ -69,12 +82,15 @@ func NewClient[Req, Res any](httpClient HTTPClient, url string, options ...Clien
 	// once at client creation.
 	unarySpec := config.newSpec(StreamTypeUnary)
 	unaryFunc := UnaryFunc(func(ctx context.Context, request AnyRequest) (AnyResponse, error) {
-		conn := client.protocolClient.NewConn(ctx, unarySpec, request.Header())
+		ctx, stats := newRPCStats(ctx, config.StatsHandlers, unarySpec)
+		stats.begin()
+		conn := stats.wrapClientConn(client.protocolClient.NewConn(ctx, unarySpec, request.Header()))
 		conn.onRequestSend(func(r *http.Request) {
 			request.setRequestMethod(r.Method)
 			callInfo, ok := clientCallInfoForContext(ctx)
 			if ok {
 				callInfo.method = r.Method
+				callInfo.attempts.Add(1)
 			}
 		})
 		// Send always returns an io.EOF unless the error is from the client-side.
@@ -96,6 +112,10 @@ func NewClient[Req, Res any](httpClient HTTPClient, url string, options ...Clien
 		}
 		return response, conn.CloseResponse()
 	})
+	if interceptor := config.Interceptor; interceptor != nil {
+		// interceptor is the full chain of all interceptors provided
+		unaryFunc = interceptor.WrapUnary(unaryFunc)
+	}
 	client.callUnary = func(ctx context.Context, request *Request[Req]) (*Response[Res], error) {
 		// To make the specification, peer, and RPC headers visible to the full
 		// interceptor chain (as though they were supplied by the caller), we'll
@@ -109,6 +129,8 @@ func NewClient[Req, Res any](httpClient HTTPClient, url string, options ...Clien
 		if callInfoOk {
 			callInfo.peer = request.Peer()
 			callInfo.spec = request.Spec()
+			callInfo.attempts.Store(0)
+			callInfo.deadline, callInfo.hasDeadline = ctx.Deadline()
 			// A client could have set request headers in the call info OR the request wrapper
 			// So if a callInfo exists in context, merge any headers from there into the request wrapper
 			// so that all headers are sent in the request
@@ -268,14 +290,28 @@ func (c *Client[Req, Res]) newConn(ctx context.Context, streamType StreamType, o
 		// the sentinel value against the call info in context. If they're different,
 		// we can stop the request. This protects against changing the context in interceptors.
 		ctx = context.WithValue(ctx, sentinelContextKey{}, callInfo)
+		callInfo.attempts.Store(0)
+		callInfo.deadline, callInfo.hasDeadline = ctx.Deadline()
 	}
 	newConn := func(ctx context.Context, spec Spec) StreamingClientConn {
 		header := make(http.Header, 8) // arbitrary power of two, prevent immediate resizing
 		c.protocolClient.WriteRequestHeader(streamType, header)
-		conn := c.protocolClient.NewConn(ctx, spec, header)
-		conn.onRequestSend(onRequestSend)
+		ctx, stats := newRPCStats(ctx, c.config.StatsHandlers, spec)
+		stats.begin()
+		conn := stats.wrapClientConn(c.protocolClient.NewConn(ctx, spec, header))
+		conn.onRequestSend(func(r *http.Request) {
+			if callInfoOk {
+				callInfo.attempts.Add(1)
+			}
+			if onRequestSend != nil {
+				onRequestSend(r)
+			}
+		})
 		return conn
 	}
+	if interceptor := c.config.Interceptor; interceptor != nil {
+		newConn = interceptor.WrapStreamingClient(newConn)
+	}
 	conn := newConn(ctx, c.config.newSpec(streamType))
 
 	// Set values in the context if there's a call info present
@@ -293,18 +329,28 @@ func (c *Client[Req, Res]) newConn(ctx context.Context, streamType StreamType, o
 }
 
 type clientConfig struct {
-	URL            *url.URL
-	Protocol       protocol
-	Procedure      string
-	Schema         any
-	Initializer    maybeInitializer
-	Codec          Codec
-	BufferPool     *bufferPool
-	ReadMaxBytes   int
-	SendMaxBytes   int
-	EnableGet      bool
-	GetURLMaxBytes int
-	GetUseFallback bool
+	URL                    *url.URL
+	Protocol               protocol
+	Procedure              string
+	Schema                 any
+	IdempotencyLevel       IdempotencyLevel
+	Initializer            maybeInitializer
+	CompressMinBytes       int
+	Interceptor            Interceptor
+	CompressionPools       map[string]*compressionPool
+	CompressionNames       []string
+	Codec                  Codec
+	RequestCompressionName string
+	BufferPool             *bufferPool
+	ReadMaxBytes           int
+	SendMaxBytes           int
+	EnableGet              bool
+	GetURLMaxBytes         int
+	GetUseFallback         bool
+	RetryPolicy            *RetryPolicy
+	HedgingPolicy          *HedgingPolicy
+	LoadBalancer           *loadBalancer
+	StatsHandlers          []StatsHandler
 }
 
 func newClientConfig(rawURL string, options []ClientOption) (*clientConfig, *Error) {
@@ -314,12 +360,14 @@ func newClientConfig(rawURL string, options []ClientOption) (*clientConfig, *Err
 	}
 	protoPath := extractProtoPath(url.Path)
 	config := clientConfig{
-		URL:        url,
-		Protocol:   &protocolGRPC{},
-		Procedure:  protoPath,
-		BufferPool: newBufferPool(),
+		URL:              url,
+		Protocol:         &protocolGRPC{},
+		Procedure:        protoPath,
+		CompressionPools: make(map[string]*compressionPool),
+		BufferPool:       newBufferPool(),
 	}
 	withProtoBinaryCodec().applyToClient(&config)
+	withGzip().applyToClient(&config)
 	for _, opt := range options {
 		opt.applyToClient(&config)
 	}
@@ -333,6 +381,24 @@ func (c *clientConfig) validate() *Error {
 	if c.Codec == nil || c.Codec.Name() == "" {
 		return errorf(CodeUnknown, "no codec configured")
 	}
+	if c.RequestCompressionName != "" && c.RequestCompressionName != compressionIdentity {
+		if _, ok := c.CompressionPools[c.RequestCompressionName]; !ok {
+			return errorf(CodeUnknown, "unknown compression %q", c.RequestCompressionName)
+		}
+	}
+	if c.RetryPolicy != nil {
+		if err := c.RetryPolicy.validate(); err != nil {
+			return NewError(CodeUnknown, err)
+		}
+	}
+	if c.LoadBalancer != nil && c.LoadBalancer.resolver == nil {
+		return errorf(CodeUnknown, "no resolver configured for load balancing")
+	}
+	if c.HedgingPolicy != nil {
+		if err := c.HedgingPolicy.validate(); err != nil {
+			return NewError(CodeUnknown, err)
+		}
+	}
 	return nil
 }
 
@@ -345,10 +411,11 @@ func (c *clientConfig) protobuf() Codec {
 
 func (c *clientConfig) newSpec(t StreamType) Spec {
 	return Spec{
-		StreamType: t,
-		Procedure:  c.Procedure,
-		Schema:     c.Schema,
-		IsClient:   true,
+		StreamType:       t,
+		Procedure:        c.Procedure,
+		Schema:           c.Schema,
+		IsClient:         true,
+		IdempotencyLevel: idempotencyLevel(c.IdempotencyLevel, c.Schema),
 	}
 }
 
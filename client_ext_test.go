@@ -670,6 +670,473 @@ func testClientDeadlineBruteForceLoop(
 	t.Logf("Issued %d RPCs.", rpcCount.Load())
 }
 
+func TestClientRetry(t *testing.T) {
+	t.Parallel()
+	policy := connect.RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    time.Millisecond,
+		MaxBackoff:        10 * time.Millisecond,
+		BackoffMultiplier: 2,
+		RetryableCodes:    []connect.Code{connect.CodeUnavailable},
+	}
+	// newClient starts a server whose handlers call fail with the attempt
+	// number. If fail returns nil, the call succeeds.
+	newClient := func(t *testing.T, fail func(attempt int) error, opts ...connect.ClientOption) pingv1connect.PingServiceClient {
+		t.Helper()
+		var calls atomic.Int32
+		check := func(ctx context.Context) error {
+			attempt := int(calls.Add(1))
+			callInfo, ok := connect.CallInfoForHandlerContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, callInfo.Attempts(), attempt)
+			return fail(attempt)
+		}
+		mux := http.NewServeMux()
+		mux.Handle(pingv1connect.NewPingServiceHandler(&pluggablePingServer{
+			ping: func(ctx context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+				if err := check(ctx); err != nil {
+					return nil, err
+				}
+				return connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber()}), nil
+			},
+			sum: func(ctx context.Context, stream *connect.ClientStream[pingv1.SumRequest]) (*connect.Response[pingv1.SumResponse], error) {
+				var sum int64
+				for stream.Receive() {
+					sum += stream.Msg().GetNumber()
+				}
+				if err := stream.Err(); err != nil {
+					return nil, err
+				}
+				if err := check(ctx); err != nil {
+					return nil, err
+				}
+				return connect.NewResponse(&pingv1.SumResponse{Sum: sum}), nil
+			},
+			countUp: func(ctx context.Context, request *connect.Request[pingv1.CountUpRequest], stream *connect.ServerStream[pingv1.CountUpResponse]) error {
+				if err := check(ctx); err != nil {
+					return err
+				}
+				for i := range request.Msg.GetNumber() {
+					if err := stream.Send(&pingv1.CountUpResponse{Number: i + 1}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}))
+		server := memhttptest.NewServer(t, mux)
+		return pingv1connect.NewPingServiceClient(server.Client(), server.URL(), opts...)
+	}
+	unavailableUntil := func(success int) func(int) error {
+		return func(attempt int) error {
+			if attempt < success {
+				return connect.NewError(connect.CodeUnavailable, errors.New("try again"))
+			}
+			return nil
+		}
+	}
+	protocols := []struct {
+		name string
+		opt  connect.ClientOption
+	}{
+		{connect.ProtocolConnect, connect.WithConnect()},
+		{connect.ProtocolGRPC, connect.WithGRPC()},
+		{connect.ProtocolGRPCWeb, connect.WithGRPCWeb()},
+	}
+	for _, protocol := range protocols {
+		t.Run(protocol.name, func(t *testing.T) {
+			t.Parallel()
+			t.Run("unary", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, unavailableUntil(3), protocol.opt, connect.WithRetryPolicy(policy))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				response, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{Number: 42}))
+				assert.Nil(t, err)
+				assert.Equal(t, response.Msg.GetNumber(), 42)
+				assert.Equal(t, callInfo.Attempts(), 3)
+			})
+			t.Run("unary_exhausted", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, unavailableUntil(4), protocol.opt, connect.WithRetryPolicy(policy))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				_, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+				assert.Equal(t, callInfo.Attempts(), 3)
+			})
+			t.Run("unary_not_retryable", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, func(int) error {
+					return connect.NewError(connect.CodeInvalidArgument, errors.New("bad request"))
+				}, protocol.opt, connect.WithRetryPolicy(policy))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				_, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeInvalidArgument)
+				assert.Equal(t, callInfo.Attempts(), 1)
+			})
+			t.Run("unary_pushback", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, func(int) error {
+					err := connect.NewError(connect.CodeUnavailable, errors.New("go away"))
+					err.Meta().Set("Grpc-Retry-Pushback-Ms", "-1")
+					return err
+				}, protocol.opt, connect.WithRetryPolicy(policy))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				_, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+				assert.Equal(t, callInfo.Attempts(), 1)
+			})
+			t.Run("unary_disabled", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, unavailableUntil(2), protocol.opt)
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				_, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+				assert.Equal(t, callInfo.Attempts(), 1)
+			})
+			t.Run("client_stream", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, unavailableUntil(2), protocol.opt, connect.WithRetryPolicy(policy))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				stream := client.Sum(ctx)
+				for i := range 10 {
+					assert.Nil(t, stream.Send(&pingv1.SumRequest{Number: int64(i)}))
+				}
+				response, err := stream.CloseAndReceive()
+				if protocol.name == connect.ProtocolConnect {
+					// Connect streams report errors at the end of the response
+					// body, after the response is committed.
+					assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+					assert.Equal(t, callInfo.Attempts(), 1)
+					return
+				}
+				assert.Nil(t, err)
+				assert.Equal(t, response.Msg.GetSum(), 45)
+				assert.Equal(t, callInfo.Attempts(), 2)
+			})
+			t.Run("client_stream_not_replayable", func(t *testing.T) {
+				t.Parallel()
+				small := policy
+				small.MaxBufferBytes = 1
+				client := newClient(t, unavailableUntil(2), protocol.opt, connect.WithRetryPolicy(small))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				stream := client.Sum(ctx)
+				assert.Nil(t, stream.Send(&pingv1.SumRequest{Number: 42}))
+				_, err := stream.CloseAndReceive()
+				assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+				assert.Equal(t, callInfo.Attempts(), 1)
+			})
+			t.Run("server_stream", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, unavailableUntil(2), protocol.opt, connect.WithRetryPolicy(policy))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				stream, err := client.CountUp(ctx, connect.NewRequest(&pingv1.CountUpRequest{Number: 3}))
+				assert.Nil(t, err)
+				var got []int64
+				for stream.Receive() {
+					got = append(got, stream.Msg().GetNumber())
+				}
+				assert.Nil(t, stream.Close())
+				// Connect streams and gRPC handlers using net/http send errors
+				// after the response headers, so they aren't retried.
+				if protocol.name != connect.ProtocolGRPCWeb {
+					assert.Equal(t, connect.CodeOf(stream.Err()), connect.CodeUnavailable)
+					assert.Equal(t, callInfo.Attempts(), 1)
+					return
+				}
+				assert.Nil(t, stream.Err())
+				assert.Equal(t, got, []int64{1, 2, 3})
+				assert.Equal(t, callInfo.Attempts(), 2)
+			})
+		})
+	}
+	t.Run("server_stream_idle", func(t *testing.T) {
+		t.Parallel()
+		// Streams that send headers and then wait before their first message
+		// aren't delayed by retries.
+		server := memhttptest.NewServer(t, http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			responseWriter.Header().Set("Content-Type", "application/grpc")
+			responseWriter.Header().Set(handlerHeader, "idle")
+			responseWriter.WriteHeader(http.StatusOK)
+			_ = http.NewResponseController(responseWriter).Flush()
+			<-request.Context().Done()
+		}))
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC(), connect.WithRetryPolicy(policy))
+		ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+		defer cancel()
+		stream, err := client.CountUp(ctx, connect.NewRequest(&pingv1.CountUpRequest{Number: 1}))
+		assert.Nil(t, err)
+		assert.Equal(t, stream.ResponseHeader().Get(handlerHeader), "idle")
+		assert.Nil(t, ctx.Err())
+		cancel()
+		assert.Nil(t, stream.Close())
+	})
+	t.Run("invalid_policy", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, unavailableUntil(1), connect.WithRetryPolicy(connect.RetryPolicy{MaxAttempts: 1}))
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestClientHedging(t *testing.T) {
+	t.Parallel()
+	// newClient starts a server whose Ping handler calls ping with the attempt
+	// number.
+	newClient := func(t *testing.T, ping func(ctx context.Context, attempt int) error, opts ...connect.ClientOption) pingv1connect.PingServiceClient {
+		t.Helper()
+		mux := http.NewServeMux()
+		mux.Handle(pingv1connect.NewPingServiceHandler(&pluggablePingServer{
+			ping: func(ctx context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+				callInfo, ok := connect.CallInfoForHandlerContext(ctx)
+				assert.True(t, ok)
+				if err := ping(ctx, callInfo.Attempts()); err != nil {
+					return nil, err
+				}
+				return connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber()}), nil
+			},
+			sum: func(ctx context.Context, stream *connect.ClientStream[pingv1.SumRequest]) (*connect.Response[pingv1.SumResponse], error) {
+				for stream.Receive() {
+				}
+				return nil, connect.NewError(connect.CodeUnavailable, errors.New("try again"))
+			},
+		}))
+		server := memhttptest.NewServer(t, mux)
+		return pingv1connect.NewPingServiceClient(server.Client(), server.URL(), opts...)
+	}
+	protocols := []struct {
+		name string
+		opt  connect.ClientOption
+	}{
+		{connect.ProtocolConnect, connect.WithConnect()},
+		{connect.ProtocolGRPC, connect.WithGRPC()},
+		{connect.ProtocolGRPCWeb, connect.WithGRPCWeb()},
+	}
+	for _, protocol := range protocols {
+		t.Run(protocol.name, func(t *testing.T) {
+			t.Parallel()
+			t.Run("slow_attempt", func(t *testing.T) {
+				t.Parallel()
+				canceled := make(chan struct{})
+				client := newClient(t, func(ctx context.Context, attempt int) error {
+					if attempt == 1 {
+						<-ctx.Done()
+						close(canceled)
+						return ctx.Err()
+					}
+					return nil
+				}, protocol.opt, connect.WithHedgingPolicy(connect.HedgingPolicy{
+					MaxAttempts:  2,
+					HedgingDelay: 10 * time.Millisecond,
+				}))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				response, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{Number: 42}))
+				assert.Nil(t, err)
+				assert.Equal(t, response.Msg.GetNumber(), 42)
+				assert.Equal(t, callInfo.Attempts(), 2)
+				select {
+				case <-canceled:
+				case <-time.After(time.Second):
+					t.Fatal("slow attempt wasn't canceled")
+				}
+			})
+			t.Run("non_fatal", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, func(_ context.Context, attempt int) error {
+					if attempt == 1 {
+						return connect.NewError(connect.CodeUnavailable, errors.New("try again"))
+					}
+					return nil
+				}, protocol.opt, connect.WithHedgingPolicy(connect.HedgingPolicy{
+					MaxAttempts:   3,
+					HedgingDelay:  time.Minute,
+					NonFatalCodes: []connect.Code{connect.CodeUnavailable},
+				}))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				_, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Nil(t, err)
+				assert.Equal(t, callInfo.Attempts(), 2)
+			})
+			t.Run("fatal", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, func(context.Context, int) error {
+					return connect.NewError(connect.CodeInvalidArgument, errors.New("bad request"))
+				}, protocol.opt, connect.WithHedgingPolicy(connect.HedgingPolicy{
+					MaxAttempts:   3,
+					HedgingDelay:  time.Minute,
+					NonFatalCodes: []connect.Code{connect.CodeUnavailable},
+				}))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				_, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeInvalidArgument)
+				assert.Equal(t, callInfo.Attempts(), 1)
+			})
+			t.Run("all_fail", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, func(context.Context, int) error {
+					return connect.NewError(connect.CodeUnavailable, errors.New("try again"))
+				}, protocol.opt, connect.WithHedgingPolicy(connect.HedgingPolicy{
+					MaxAttempts:   3,
+					NonFatalCodes: []connect.Code{connect.CodeUnavailable},
+				}))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				_, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+				assert.Equal(t, callInfo.Attempts(), 3)
+			})
+			t.Run("streaming", func(t *testing.T) {
+				t.Parallel()
+				client := newClient(t, nil, protocol.opt, connect.WithHedgingPolicy(connect.HedgingPolicy{
+					MaxAttempts: 3,
+				}))
+				ctx, callInfo := connect.NewClientContext(t.Context())
+				stream := client.Sum(ctx)
+				assert.Nil(t, stream.Send(&pingv1.SumRequest{Number: 1}))
+				_, err := stream.CloseAndReceive()
+				assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+				assert.Equal(t, callInfo.Attempts(), 1)
+			})
+		})
+	}
+}
+
+func TestClientLoadBalancing(t *testing.T) {
+	t.Parallel()
+	// newBackend starts a server that counts its calls and fails them with
+	// CodeUnavailable while unavailable is true.
+	newBackend := func(t *testing.T, unavailable *atomic.Bool) (*httptest.Server, *atomic.Int32) {
+		t.Helper()
+		var calls atomic.Int32
+		mux := http.NewServeMux()
+		mux.Handle(pingv1connect.NewPingServiceHandler(&pluggablePingServer{
+			ping: func(_ context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+				calls.Add(1)
+				if unavailable.Load() {
+					return nil, connect.NewError(connect.CodeUnavailable, errors.New("draining"))
+				}
+				return connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber()}), nil
+			},
+		}))
+		server := httptest.NewUnstartedServer(mux)
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server, &calls
+	}
+	var healthy, draining atomic.Bool
+	draining.Store(true)
+	first, firstCalls := newBackend(t, &healthy)
+	second, secondCalls := newBackend(t, &healthy)
+	third, thirdCalls := newBackend(t, &draining)
+	resolver := connect.NewStaticResolver(first.URL, strings.TrimPrefix(second.URL, "https://"))
+	t.Run("round_robin", func(t *testing.T) {
+		client := pingv1connect.NewPingServiceClient(
+			first.Client(),
+			"https://unused.example.com",
+			connect.WithConnect(),
+			connect.WithLoadBalancing(resolver, nil),
+		)
+		for range 10 {
+			_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, firstCalls.Load(), 5)
+		assert.Equal(t, secondCalls.Load(), 5)
+	})
+	t.Run("ejection", func(t *testing.T) {
+		for _, protocol := range []connect.ClientOption{connect.WithConnect(), connect.WithGRPC(), connect.WithGRPCWeb()} {
+			thirdCalls.Store(0)
+			client := pingv1connect.NewPingServiceClient(
+				first.Client(),
+				"https://unused.example.com",
+				protocol,
+				connect.WithLoadBalancing(connect.NewStaticResolver(third.URL, first.URL), connect.NewRoundRobinBalancer()),
+			)
+			_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+			assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+			for range 5 {
+				_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, thirdCalls.Load(), 1)
+		}
+	})
+	t.Run("no_addresses", func(t *testing.T) {
+		client := pingv1connect.NewPingServiceClient(
+			first.Client(),
+			"https://unused.example.com",
+			connect.WithLoadBalancing(connect.NewStaticResolver(), nil),
+		)
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+	})
+}
+
+func TestGetNoContentHeaders(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(&pingServer{}))
+	server := memhttptest.NewServer(t, http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		if len(req.Header.Values("content-type")) > 0 ||
+			len(req.Header.Values("content-encoding")) > 0 ||
+			len(req.Header.Values("content-length")) > 0 {
+			http.Error(respWriter, "GET request should not include content headers", http.StatusBadRequest)
+		}
+		mux.ServeHTTP(respWriter, req)
+	}))
+	client := pingv1connect.NewPingServiceClient(
+		server.Client(),
+		server.URL(),
+		connect.WithConnect(),
+		connect.WithHTTPGet(),
+	)
+	ctx := t.Context()
+
+	unaryReq := connect.NewRequest(&pingv1.PingRequest{})
+	_, err := client.Ping(ctx, unaryReq)
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodGet, unaryReq.HTTPMethod())
+}
+
+func TestGetNotModified(t *testing.T) {
+	t.Parallel()
+
+	const etag = "some-etag"
+	// Handlers should automatically set Vary to include request headers that are
+	// part of the RPC protocol.
+	expectVary := []string{"Accept-Encoding"}
+
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(&notModifiedPingServer{etag: etag}))
+	server := memhttptest.NewServer(t, mux)
+	client := pingv1connect.NewPingServiceClient(
+		server.Client(),
+		server.URL(),
+		connect.WithConnect(),
+		connect.WithHTTPGet(),
+	)
+	ctx := t.Context()
+	// unconditional request
+	unaryReq := connect.NewRequest(&pingv1.PingRequest{})
+	res, err := client.Ping(ctx, unaryReq)
+	assert.Nil(t, err)
+	assert.Equal(t, res.Header().Get("Etag"), etag)
+	assert.Equal(t, res.Header().Values("Vary"), expectVary)
+	assert.Equal(t, http.MethodGet, unaryReq.HTTPMethod())
+
+	unaryReq = connect.NewRequest(&pingv1.PingRequest{})
+	unaryReq.Header().Set("If-None-Match", etag)
+	_, err = client.Ping(ctx, unaryReq)
+	assert.NotNil(t, err)
+	assert.Equal(t, connect.CodeOf(err), connect.CodeUnknown)
+	assert.True(t, connect.IsNotModifiedError(err))
+	var connectErr *connect.Error
+	assert.True(t, errors.As(err, &connectErr))
+	assert.Equal(t, connectErr.Meta().Get("Etag"), etag)
+	assert.Equal(t, connectErr.Meta().Values("Vary"), expectVary)
+	assert.Equal(t, http.MethodGet, unaryReq.HTTPMethod())
+}
+
 type rpcErrors struct {
 	sendErr      error
 	recvErr      error
@@ -687,3 +1154,21 @@ type httpClientFunc func(*http.Request) (*http.Response, error)
 func (fn httpClientFunc) Do(req *http.Request) (*http.Response, error) {
 	return fn(req)
 }
+
+type notModifiedPingServer struct {
+	pingv1connect.UnimplementedPingServiceHandler
+
+	etag string
+}
+
+func (s *notModifiedPingServer) Ping(
+	_ context.Context,
+	req *connect.Request[pingv1.PingRequest],
+) (*connect.Response[pingv1.PingResponse], error) {
+	if req.HTTPMethod() == http.MethodGet && req.Header().Get("If-None-Match") == s.etag {
+		return nil, connect.NewNotModifiedError(http.Header{"Etag": []string{s.etag}})
+	}
+	resp := connect.NewResponse(&pingv1.PingResponse{})
+	resp.Header().Set("Etag", s.etag)
+	return resp, nil
+}
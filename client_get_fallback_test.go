@@ -0,0 +1,78 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+)
+
+func TestClientUnaryGetFallback(t *testing.T) {
+	t.Parallel()
+	pingMethod := pingv1.File_connect_ping_v1_ping_proto.Services().ByName("PingService").Methods().ByName("Ping")
+	mux := http.NewServeMux()
+	mux.Handle("/connect.ping.v1.PingService/Ping", NewUnaryHandler(
+		"/connect.ping.v1.PingService/Ping",
+		func(ctx context.Context, r *Request[pingv1.PingRequest]) (*Response[pingv1.PingResponse], error) {
+			response := NewResponse(&pingv1.PingResponse{
+				Number: r.Msg.GetNumber(),
+				Text:   r.Msg.GetText(),
+			})
+			response.Header().Set("Test-Method", r.HTTPMethod())
+			return response, nil
+		},
+		WithSchema(pingMethod),
+	))
+	server := memhttptest.NewServer(t, mux)
+
+	ctx := t.Context()
+
+	getClient := NewClient[pingv1.PingRequest, pingv1.PingResponse](
+		server.Client(),
+		server.URL()+"/connect.ping.v1.PingService/Ping",
+		WithConnect(),
+		WithSchema(pingMethod),
+		WithHTTPGet(),
+	)
+	r, err := getClient.CallUnary(ctx, NewRequest(&pingv1.PingRequest{Number: 42}))
+	assert.Nil(t, err)
+	assert.Equal(t, r.Msg.GetNumber(), 42)
+	assert.Equal(t, r.Header().Get("Test-Method"), http.MethodGet)
+
+	client := NewClient[pingv1.PingRequest, pingv1.PingResponse](
+		server.Client(),
+		server.URL()+"/connect.ping.v1.PingService/Ping",
+		WithConnect(),
+		WithSchema(pingMethod),
+		WithHTTPGet(),
+		WithHTTPGetMaxURLSize(1, true),
+		WithSendGzip(),
+	)
+	r, err = client.CallUnary(ctx, NewRequest[pingv1.PingRequest](nil))
+	assert.Nil(t, err)
+	assert.Equal(t, r.Header().Get("Test-Method"), http.MethodPost)
+
+	text := strings.Repeat(".", 256)
+	r, err = client.CallUnary(ctx, NewRequest(&pingv1.PingRequest{Text: text}))
+	assert.Nil(t, err)
+	assert.Equal(t, r.Msg.GetText(), text)
+	assert.Equal(t, r.Header().Get("Test-Method"), http.MethodPost)
+}
@@ -0,0 +1,290 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-scalpel-go. DO NOT EDIT.
+//
+// Source: mocks.proto
+
+package genconnect
+
+import (
+	context "context"
+	errors "errors"
+	scalpel "github.com/agentio/scalpel"
+	gen "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = scalpel.IsAtLeastVersion1_13_0
+
+const (
+	// TestServiceName is the fully-qualified name of the TestService service.
+	TestServiceName = "connect.test.mocks.TestService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// TestServiceMethodProcedure is the fully-qualified name of the TestService's Method RPC.
+	TestServiceMethodProcedure = "/connect.test.mocks.TestService/Method"
+	// TestServiceMethodClientStreamProcedure is the fully-qualified name of the TestService's
+	// MethodClientStream RPC.
+	TestServiceMethodClientStreamProcedure = "/connect.test.mocks.TestService/MethodClientStream"
+	// TestServiceMethodServerStreamProcedure is the fully-qualified name of the TestService's
+	// MethodServerStream RPC.
+	TestServiceMethodServerStreamProcedure = "/connect.test.mocks.TestService/MethodServerStream"
+	// TestServiceMethodBidiStreamProcedure is the fully-qualified name of the TestService's
+	// MethodBidiStream RPC.
+	TestServiceMethodBidiStreamProcedure = "/connect.test.mocks.TestService/MethodBidiStream"
+)
+
+// TestServiceClient is a client for the connect.test.mocks.TestService service.
+type TestServiceClient interface {
+	Method(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	MethodClientStream(context.Context) *scalpel.ClientStreamForClient[gen.Request, gen.Response]
+	MethodServerStream(context.Context, *scalpel.Request[gen.Request]) (*scalpel.ServerStreamForClient[gen.Response], error)
+	MethodBidiStream(context.Context) *scalpel.BidiStreamForClient[gen.Request, gen.Response]
+}
+
+// NewTestServiceClient constructs a client for the connect.test.mocks.TestService service. By
+// default, it uses the gRPCConnect protocol with the binary Protobuf Codec, asks for gzipped
+// responses, and sends uncompressed requests.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewTestServiceClient(httpClient scalpel.HTTPClient, baseURL string, opts ...scalpel.ClientOption) TestServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	testServiceMethods := gen.File_mocks_proto.Services().ByName("TestService").Methods()
+	return &testServiceClient{
+		method: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+TestServiceMethodProcedure,
+			scalpel.WithSchema(testServiceMethods.ByName("Method")),
+			scalpel.WithClientOptions(opts...),
+		),
+		methodClientStream: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+TestServiceMethodClientStreamProcedure,
+			scalpel.WithSchema(testServiceMethods.ByName("MethodClientStream")),
+			scalpel.WithClientOptions(opts...),
+		),
+		methodServerStream: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+TestServiceMethodServerStreamProcedure,
+			scalpel.WithSchema(testServiceMethods.ByName("MethodServerStream")),
+			scalpel.WithClientOptions(opts...),
+		),
+		methodBidiStream: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+TestServiceMethodBidiStreamProcedure,
+			scalpel.WithSchema(testServiceMethods.ByName("MethodBidiStream")),
+			scalpel.WithClientOptions(opts...),
+		),
+	}
+}
+
+// testServiceClient implements TestServiceClient.
+type testServiceClient struct {
+	method             *scalpel.Client[gen.Request, gen.Response]
+	methodClientStream *scalpel.Client[gen.Request, gen.Response]
+	methodServerStream *scalpel.Client[gen.Request, gen.Response]
+	methodBidiStream   *scalpel.Client[gen.Request, gen.Response]
+}
+
+// Method calls connect.test.mocks.TestService.Method.
+func (c *testServiceClient) Method(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return c.method.CallUnary(ctx, req)
+}
+
+// MethodClientStream calls connect.test.mocks.TestService.MethodClientStream.
+func (c *testServiceClient) MethodClientStream(ctx context.Context) *scalpel.ClientStreamForClient[gen.Request, gen.Response] {
+	return c.methodClientStream.CallClientStream(ctx)
+}
+
+// MethodServerStream calls connect.test.mocks.TestService.MethodServerStream.
+func (c *testServiceClient) MethodServerStream(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.ServerStreamForClient[gen.Response], error) {
+	return c.methodServerStream.CallServerStream(ctx, req)
+}
+
+// MethodBidiStream calls connect.test.mocks.TestService.MethodBidiStream.
+func (c *testServiceClient) MethodBidiStream(ctx context.Context) *scalpel.BidiStreamForClient[gen.Request, gen.Response] {
+	return c.methodBidiStream.CallBidiStream(ctx)
+}
+
+// TestServiceHandler is an implementation of the connect.test.mocks.TestService service.
+type TestServiceHandler interface {
+	Method(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	MethodClientStream(context.Context, *scalpel.ClientStream[gen.Request]) (*scalpel.Response[gen.Response], error)
+	MethodServerStream(context.Context, *scalpel.Request[gen.Request], *scalpel.ServerStream[gen.Response]) error
+	MethodBidiStream(context.Context, *scalpel.BidiStream[gen.Request, gen.Response]) error
+}
+
+// NewTestServiceHandler builds an HTTP handler from the service implementation. It returns the path
+// on which to mount the handler and the handler itself.
+//
+// By default, handlers support the gRPC protocol with the binary Protobuf codecs.
+func NewTestServiceHandler(svc TestServiceHandler, opts ...scalpel.HandlerOption) (string, http.Handler) {
+	testServiceMethods := gen.File_mocks_proto.Services().ByName("TestService").Methods()
+	testServiceMethodHandler := scalpel.NewUnaryHandler(
+		TestServiceMethodProcedure,
+		svc.Method,
+		scalpel.WithSchema(testServiceMethods.ByName("Method")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	testServiceMethodClientStreamHandler := scalpel.NewClientStreamHandler(
+		TestServiceMethodClientStreamProcedure,
+		svc.MethodClientStream,
+		scalpel.WithSchema(testServiceMethods.ByName("MethodClientStream")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	testServiceMethodServerStreamHandler := scalpel.NewServerStreamHandler(
+		TestServiceMethodServerStreamProcedure,
+		svc.MethodServerStream,
+		scalpel.WithSchema(testServiceMethods.ByName("MethodServerStream")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	testServiceMethodBidiStreamHandler := scalpel.NewBidiStreamHandler(
+		TestServiceMethodBidiStreamProcedure,
+		svc.MethodBidiStream,
+		scalpel.WithSchema(testServiceMethods.ByName("MethodBidiStream")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	return "/connect.test.mocks.TestService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case TestServiceMethodProcedure:
+			testServiceMethodHandler.ServeHTTP(w, r)
+		case TestServiceMethodClientStreamProcedure:
+			testServiceMethodClientStreamHandler.ServeHTTP(w, r)
+		case TestServiceMethodServerStreamProcedure:
+			testServiceMethodServerStreamHandler.ServeHTTP(w, r)
+		case TestServiceMethodBidiStreamProcedure:
+			testServiceMethodBidiStreamHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedTestServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedTestServiceHandler struct{}
+
+func (UnimplementedTestServiceHandler) Method(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.Method is not implemented"))
+}
+
+func (UnimplementedTestServiceHandler) MethodClientStream(context.Context, *scalpel.ClientStream[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.MethodClientStream is not implemented"))
+}
+
+func (UnimplementedTestServiceHandler) MethodServerStream(context.Context, *scalpel.Request[gen.Request], *scalpel.ServerStream[gen.Response]) error {
+	return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.MethodServerStream is not implemented"))
+}
+
+func (UnimplementedTestServiceHandler) MethodBidiStream(context.Context, *scalpel.BidiStream[gen.Request, gen.Response]) error {
+	return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.MethodBidiStream is not implemented"))
+}
+
+// FakeTestService is a configurable fake of the connect.test.mocks.TestService service for unit
+// tests. Each method calls the corresponding function field, or returns CodeUnimplemented if the
+// field is nil. Set the fields before making calls.
+//
+// FakeTestService implements TestServiceHandler and records every call in its Recorder. Use
+// NewFakeTestServiceClient to call the fake in memory, without a transport.
+type FakeTestService struct {
+	Recorder scalpel.FakeRecorder
+
+	MethodFunc             func(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	MethodClientStreamFunc func(context.Context, *scalpel.ClientStream[gen.Request]) (*scalpel.Response[gen.Response], error)
+	MethodServerStreamFunc func(context.Context, *scalpel.Request[gen.Request], *scalpel.ServerStream[gen.Response]) error
+	MethodBidiStreamFunc   func(context.Context, *scalpel.BidiStream[gen.Request, gen.Response]) error
+}
+
+// Method records the call and calls MethodFunc.
+func (f *FakeTestService) Method(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	f.Recorder.Record(TestServiceMethodProcedure, req.Msg)
+	if f.MethodFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.Method is not implemented"))
+	}
+	return f.MethodFunc(ctx, req)
+}
+
+// MethodClientStream records the call and calls MethodClientStreamFunc.
+func (f *FakeTestService) MethodClientStream(ctx context.Context, stream *scalpel.ClientStream[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	f.Recorder.Record(TestServiceMethodClientStreamProcedure, nil)
+	if f.MethodClientStreamFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.MethodClientStream is not implemented"))
+	}
+	return f.MethodClientStreamFunc(ctx, stream)
+}
+
+// MethodServerStream records the call and calls MethodServerStreamFunc.
+func (f *FakeTestService) MethodServerStream(ctx context.Context, req *scalpel.Request[gen.Request], stream *scalpel.ServerStream[gen.Response]) error {
+	f.Recorder.Record(TestServiceMethodServerStreamProcedure, req.Msg)
+	if f.MethodServerStreamFunc == nil {
+		return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.MethodServerStream is not implemented"))
+	}
+	return f.MethodServerStreamFunc(ctx, req, stream)
+}
+
+// MethodBidiStream records the call and calls MethodBidiStreamFunc.
+func (f *FakeTestService) MethodBidiStream(ctx context.Context, stream *scalpel.BidiStream[gen.Request, gen.Response]) error {
+	f.Recorder.Record(TestServiceMethodBidiStreamProcedure, nil)
+	if f.MethodBidiStreamFunc == nil {
+		return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.TestService.MethodBidiStream is not implemented"))
+	}
+	return f.MethodBidiStreamFunc(ctx, stream)
+}
+
+// NewFakeTestServiceClient returns a TestServiceClient that calls the fake in memory, without a
+// transport. Messages are copied rather than shared, and headers, trailers, and errors are
+// propagated as though the calls were made over the network.
+func NewFakeTestServiceClient(fake *FakeTestService) TestServiceClient {
+	return &fakeTestServiceClient{fake: fake}
+}
+
+// fakeTestServiceClient implements TestServiceClient by calling a FakeTestService.
+type fakeTestServiceClient struct {
+	fake *FakeTestService
+}
+
+// Method calls FakeTestService.Method.
+func (c *fakeTestServiceClient) Method(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return scalpel.CallFakeUnary(ctx, TestServiceMethodProcedure, req, c.fake.Method)
+}
+
+// MethodClientStream calls FakeTestService.MethodClientStream.
+func (c *fakeTestServiceClient) MethodClientStream(ctx context.Context) *scalpel.ClientStreamForClient[gen.Request, gen.Response] {
+	return scalpel.CallFakeClientStream(ctx, TestServiceMethodClientStreamProcedure, c.fake.MethodClientStream)
+}
+
+// MethodServerStream calls FakeTestService.MethodServerStream.
+func (c *fakeTestServiceClient) MethodServerStream(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.ServerStreamForClient[gen.Response], error) {
+	return scalpel.CallFakeServerStream(ctx, TestServiceMethodServerStreamProcedure, req, c.fake.MethodServerStream)
+}
+
+// MethodBidiStream calls FakeTestService.MethodBidiStream.
+func (c *fakeTestServiceClient) MethodBidiStream(ctx context.Context) *scalpel.BidiStreamForClient[gen.Request, gen.Response] {
+	return scalpel.CallFakeBidiStream(ctx, TestServiceMethodBidiStreamProcedure, c.fake.MethodBidiStream)
+}
@@ -0,0 +1,330 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-scalpel-go. DO NOT EDIT.
+//
+// Source: reserved.proto
+
+package genconnect
+
+import (
+	context "context"
+	errors "errors"
+	scalpel "github.com/agentio/scalpel"
+	gen "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = scalpel.IsAtLeastVersion1_13_0
+
+const (
+	// ReservedServiceName is the fully-qualified name of the ReservedService service.
+	ReservedServiceName = "connect.test.mocks.ReservedService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// ReservedServiceClientProcedure is the fully-qualified name of the ReservedService's Client RPC.
+	ReservedServiceClientProcedure = "/connect.test.mocks.ReservedService/Client"
+	// ReservedServiceRecordProcedure is the fully-qualified name of the ReservedService's Record RPC.
+	ReservedServiceRecordProcedure = "/connect.test.mocks.ReservedService/Record"
+	// ReservedServiceCallsProcedure is the fully-qualified name of the ReservedService's Calls RPC.
+	ReservedServiceCallsProcedure = "/connect.test.mocks.ReservedService/Calls"
+	// ReservedServiceCallsToProcedure is the fully-qualified name of the ReservedService's CallsTo RPC.
+	ReservedServiceCallsToProcedure = "/connect.test.mocks.ReservedService/CallsTo"
+	// ReservedServiceResetProcedure is the fully-qualified name of the ReservedService's Reset RPC.
+	ReservedServiceResetProcedure = "/connect.test.mocks.ReservedService/Reset"
+)
+
+// ReservedServiceClient is a client for the connect.test.mocks.ReservedService service.
+type ReservedServiceClient interface {
+	Client(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	Record(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	Calls(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	CallsTo(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	Reset(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+}
+
+// NewReservedServiceClient constructs a client for the connect.test.mocks.ReservedService service.
+// By default, it uses the gRPCConnect protocol with the binary Protobuf Codec, asks for gzipped
+// responses, and sends uncompressed requests.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewReservedServiceClient(httpClient scalpel.HTTPClient, baseURL string, opts ...scalpel.ClientOption) ReservedServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	reservedServiceMethods := gen.File_reserved_proto.Services().ByName("ReservedService").Methods()
+	return &reservedServiceClient{
+		client: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+ReservedServiceClientProcedure,
+			scalpel.WithSchema(reservedServiceMethods.ByName("Client")),
+			scalpel.WithClientOptions(opts...),
+		),
+		record: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+ReservedServiceRecordProcedure,
+			scalpel.WithSchema(reservedServiceMethods.ByName("Record")),
+			scalpel.WithClientOptions(opts...),
+		),
+		calls: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+ReservedServiceCallsProcedure,
+			scalpel.WithSchema(reservedServiceMethods.ByName("Calls")),
+			scalpel.WithClientOptions(opts...),
+		),
+		callsTo: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+ReservedServiceCallsToProcedure,
+			scalpel.WithSchema(reservedServiceMethods.ByName("CallsTo")),
+			scalpel.WithClientOptions(opts...),
+		),
+		reset: scalpel.NewClient[gen.Request, gen.Response](
+			httpClient,
+			baseURL+ReservedServiceResetProcedure,
+			scalpel.WithSchema(reservedServiceMethods.ByName("Reset")),
+			scalpel.WithClientOptions(opts...),
+		),
+	}
+}
+
+// reservedServiceClient implements ReservedServiceClient.
+type reservedServiceClient struct {
+	client  *scalpel.Client[gen.Request, gen.Response]
+	record  *scalpel.Client[gen.Request, gen.Response]
+	calls   *scalpel.Client[gen.Request, gen.Response]
+	callsTo *scalpel.Client[gen.Request, gen.Response]
+	reset   *scalpel.Client[gen.Request, gen.Response]
+}
+
+// Client calls connect.test.mocks.ReservedService.Client.
+func (c *reservedServiceClient) Client(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return c.client.CallUnary(ctx, req)
+}
+
+// Record calls connect.test.mocks.ReservedService.Record.
+func (c *reservedServiceClient) Record(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return c.record.CallUnary(ctx, req)
+}
+
+// Calls calls connect.test.mocks.ReservedService.Calls.
+func (c *reservedServiceClient) Calls(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return c.calls.CallUnary(ctx, req)
+}
+
+// CallsTo calls connect.test.mocks.ReservedService.CallsTo.
+func (c *reservedServiceClient) CallsTo(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return c.callsTo.CallUnary(ctx, req)
+}
+
+// Reset calls connect.test.mocks.ReservedService.Reset.
+func (c *reservedServiceClient) Reset(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return c.reset.CallUnary(ctx, req)
+}
+
+// ReservedServiceHandler is an implementation of the connect.test.mocks.ReservedService service.
+type ReservedServiceHandler interface {
+	Client(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	Record(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	Calls(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	CallsTo(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	Reset(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+}
+
+// NewReservedServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the gRPC protocol with the binary Protobuf codecs.
+func NewReservedServiceHandler(svc ReservedServiceHandler, opts ...scalpel.HandlerOption) (string, http.Handler) {
+	reservedServiceMethods := gen.File_reserved_proto.Services().ByName("ReservedService").Methods()
+	reservedServiceClientHandler := scalpel.NewUnaryHandler(
+		ReservedServiceClientProcedure,
+		svc.Client,
+		scalpel.WithSchema(reservedServiceMethods.ByName("Client")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	reservedServiceRecordHandler := scalpel.NewUnaryHandler(
+		ReservedServiceRecordProcedure,
+		svc.Record,
+		scalpel.WithSchema(reservedServiceMethods.ByName("Record")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	reservedServiceCallsHandler := scalpel.NewUnaryHandler(
+		ReservedServiceCallsProcedure,
+		svc.Calls,
+		scalpel.WithSchema(reservedServiceMethods.ByName("Calls")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	reservedServiceCallsToHandler := scalpel.NewUnaryHandler(
+		ReservedServiceCallsToProcedure,
+		svc.CallsTo,
+		scalpel.WithSchema(reservedServiceMethods.ByName("CallsTo")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	reservedServiceResetHandler := scalpel.NewUnaryHandler(
+		ReservedServiceResetProcedure,
+		svc.Reset,
+		scalpel.WithSchema(reservedServiceMethods.ByName("Reset")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	return "/connect.test.mocks.ReservedService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ReservedServiceClientProcedure:
+			reservedServiceClientHandler.ServeHTTP(w, r)
+		case ReservedServiceRecordProcedure:
+			reservedServiceRecordHandler.ServeHTTP(w, r)
+		case ReservedServiceCallsProcedure:
+			reservedServiceCallsHandler.ServeHTTP(w, r)
+		case ReservedServiceCallsToProcedure:
+			reservedServiceCallsToHandler.ServeHTTP(w, r)
+		case ReservedServiceResetProcedure:
+			reservedServiceResetHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedReservedServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedReservedServiceHandler struct{}
+
+func (UnimplementedReservedServiceHandler) Client(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Client is not implemented"))
+}
+
+func (UnimplementedReservedServiceHandler) Record(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Record is not implemented"))
+}
+
+func (UnimplementedReservedServiceHandler) Calls(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Calls is not implemented"))
+}
+
+func (UnimplementedReservedServiceHandler) CallsTo(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.CallsTo is not implemented"))
+}
+
+func (UnimplementedReservedServiceHandler) Reset(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Reset is not implemented"))
+}
+
+// FakeReservedService is a configurable fake of the connect.test.mocks.ReservedService service for
+// unit tests. Each method calls the corresponding function field, or returns CodeUnimplemented if
+// the field is nil. Set the fields before making calls.
+//
+// FakeReservedService implements ReservedServiceHandler and records every call in its Recorder. Use
+// NewFakeReservedServiceClient to call the fake in memory, without a transport.
+type FakeReservedService struct {
+	Recorder scalpel.FakeRecorder
+
+	ClientFunc  func(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	RecordFunc  func(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	CallsFunc   func(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	CallsToFunc func(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+	ResetFunc   func(context.Context, *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error)
+}
+
+// Client records the call and calls ClientFunc.
+func (f *FakeReservedService) Client(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	f.Recorder.Record(ReservedServiceClientProcedure, req.Msg)
+	if f.ClientFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Client is not implemented"))
+	}
+	return f.ClientFunc(ctx, req)
+}
+
+// Record records the call and calls RecordFunc.
+func (f *FakeReservedService) Record(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	f.Recorder.Record(ReservedServiceRecordProcedure, req.Msg)
+	if f.RecordFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Record is not implemented"))
+	}
+	return f.RecordFunc(ctx, req)
+}
+
+// Calls records the call and calls CallsFunc.
+func (f *FakeReservedService) Calls(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	f.Recorder.Record(ReservedServiceCallsProcedure, req.Msg)
+	if f.CallsFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Calls is not implemented"))
+	}
+	return f.CallsFunc(ctx, req)
+}
+
+// CallsTo records the call and calls CallsToFunc.
+func (f *FakeReservedService) CallsTo(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	f.Recorder.Record(ReservedServiceCallsToProcedure, req.Msg)
+	if f.CallsToFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.CallsTo is not implemented"))
+	}
+	return f.CallsToFunc(ctx, req)
+}
+
+// Reset records the call and calls ResetFunc.
+func (f *FakeReservedService) Reset(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	f.Recorder.Record(ReservedServiceResetProcedure, req.Msg)
+	if f.ResetFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.test.mocks.ReservedService.Reset is not implemented"))
+	}
+	return f.ResetFunc(ctx, req)
+}
+
+// NewFakeReservedServiceClient returns a ReservedServiceClient that calls the fake in memory,
+// without a transport. Messages are copied rather than shared, and headers, trailers, and errors
+// are propagated as though the calls were made over the network.
+func NewFakeReservedServiceClient(fake *FakeReservedService) ReservedServiceClient {
+	return &fakeReservedServiceClient{fake: fake}
+}
+
+// fakeReservedServiceClient implements ReservedServiceClient by calling a FakeReservedService.
+type fakeReservedServiceClient struct {
+	fake *FakeReservedService
+}
+
+// Client calls FakeReservedService.Client.
+func (c *fakeReservedServiceClient) Client(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return scalpel.CallFakeUnary(ctx, ReservedServiceClientProcedure, req, c.fake.Client)
+}
+
+// Record calls FakeReservedService.Record.
+func (c *fakeReservedServiceClient) Record(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return scalpel.CallFakeUnary(ctx, ReservedServiceRecordProcedure, req, c.fake.Record)
+}
+
+// Calls calls FakeReservedService.Calls.
+func (c *fakeReservedServiceClient) Calls(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return scalpel.CallFakeUnary(ctx, ReservedServiceCallsProcedure, req, c.fake.Calls)
+}
+
+// CallsTo calls FakeReservedService.CallsTo.
+func (c *fakeReservedServiceClient) CallsTo(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return scalpel.CallFakeUnary(ctx, ReservedServiceCallsToProcedure, req, c.fake.CallsTo)
+}
+
+// Reset calls FakeReservedService.Reset.
+func (c *fakeReservedServiceClient) Reset(ctx context.Context, req *scalpel.Request[gen.Request]) (*scalpel.Response[gen.Response], error) {
+	return scalpel.CallFakeUnary(ctx, ReservedServiceResetProcedure, req, c.fake.Reset)
+}
@@ -0,0 +1,181 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: mocks.proto
+
+package gen
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Request struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Request) Reset() {
+	*x = Request{}
+	mi := &file_mocks_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Request) ProtoMessage() {}
+
+func (x *Request) ProtoReflect() protoreflect.Message {
+	mi := &file_mocks_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Request.ProtoReflect.Descriptor instead.
+func (*Request) Descriptor() ([]byte, []int) {
+	return file_mocks_proto_rawDescGZIP(), []int{0}
+}
+
+type Response struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Response) Reset() {
+	*x = Response{}
+	mi := &file_mocks_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Response) ProtoMessage() {}
+
+func (x *Response) ProtoReflect() protoreflect.Message {
+	mi := &file_mocks_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Response.ProtoReflect.Descriptor instead.
+func (*Response) Descriptor() ([]byte, []int) {
+	return file_mocks_proto_rawDescGZIP(), []int{1}
+}
+
+var File_mocks_proto protoreflect.FileDescriptor
+
+const file_mocks_proto_rawDesc = "" +
+	"\n" +
+	"\vmocks.proto\x12\x12connect.test.mocks\"\t\n" +
+	"\aRequest\"\n" +
+	"\n" +
+	"\bResponse2\xd3\x02\n" +
+	"\vTestService\x12E\n" +
+	"\x06Method\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00\x12S\n" +
+	"\x12MethodClientStream\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00(\x01\x12S\n" +
+	"\x12MethodServerStream\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x000\x01\x12S\n" +
+	"\x10MethodBidiStream\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00(\x010\x01B\xe0\x01\n" +
+	"\x16com.connect.test.mocksB\n" +
+	"MocksProtoP\x01ZPgithub.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen\xa2\x02\x03CTM\xaa\x02\x12Connect.Test.Mocks\xca\x02\x12Connect\\Test\\Mocks\xe2\x02\x1eConnect\\Test\\Mocks\\GPBMetadata\xea\x02\x14Connect::Test::Mocksb\x06proto3"
+
+var (
+	file_mocks_proto_rawDescOnce sync.Once
+	file_mocks_proto_rawDescData []byte
+)
+
+func file_mocks_proto_rawDescGZIP() []byte {
+	file_mocks_proto_rawDescOnce.Do(func() {
+		file_mocks_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mocks_proto_rawDesc), len(file_mocks_proto_rawDesc)))
+	})
+	return file_mocks_proto_rawDescData
+}
+
+var file_mocks_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_mocks_proto_goTypes = []any{
+	(*Request)(nil),  // 0: connect.test.mocks.Request
+	(*Response)(nil), // 1: connect.test.mocks.Response
+}
+var file_mocks_proto_depIdxs = []int32{
+	0, // 0: connect.test.mocks.TestService.Method:input_type -> connect.test.mocks.Request
+	0, // 1: connect.test.mocks.TestService.MethodClientStream:input_type -> connect.test.mocks.Request
+	0, // 2: connect.test.mocks.TestService.MethodServerStream:input_type -> connect.test.mocks.Request
+	0, // 3: connect.test.mocks.TestService.MethodBidiStream:input_type -> connect.test.mocks.Request
+	1, // 4: connect.test.mocks.TestService.Method:output_type -> connect.test.mocks.Response
+	1, // 5: connect.test.mocks.TestService.MethodClientStream:output_type -> connect.test.mocks.Response
+	1, // 6: connect.test.mocks.TestService.MethodServerStream:output_type -> connect.test.mocks.Response
+	1, // 7: connect.test.mocks.TestService.MethodBidiStream:output_type -> connect.test.mocks.Response
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_mocks_proto_init() }
+func file_mocks_proto_init() {
+	if File_mocks_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mocks_proto_rawDesc), len(file_mocks_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mocks_proto_goTypes,
+		DependencyIndexes: file_mocks_proto_depIdxs,
+		MessageInfos:      file_mocks_proto_msgTypes,
+	}.Build()
+	File_mocks_proto = out.File
+	file_mocks_proto_goTypes = nil
+	file_mocks_proto_depIdxs = nil
+}
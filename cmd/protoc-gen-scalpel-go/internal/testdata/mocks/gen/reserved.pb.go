@@ -0,0 +1,94 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: reserved.proto
+
+package gen
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_reserved_proto protoreflect.FileDescriptor
+
+const file_reserved_proto_rawDesc = "" +
+	"\n" +
+	"\x0ereserved.proto\x12\x12connect.test.mocks\x1a\vmocks.proto2\xf3\x02\n" +
+	"\x0fReservedService\x12E\n" +
+	"\x06Client\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00\x12E\n" +
+	"\x06Record\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00\x12D\n" +
+	"\x05Calls\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00\x12F\n" +
+	"\aCallsTo\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00\x12D\n" +
+	"\x05Reset\x12\x1b.connect.test.mocks.Request\x1a\x1c.connect.test.mocks.Response\"\x00B\xe3\x01\n" +
+	"\x16com.connect.test.mocksB\rReservedProtoP\x01ZPgithub.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen\xa2\x02\x03CTM\xaa\x02\x12Connect.Test.Mocks\xca\x02\x12Connect\\Test\\Mocks\xe2\x02\x1eConnect\\Test\\Mocks\\GPBMetadata\xea\x02\x14Connect::Test::Mocksb\x06proto3"
+
+var file_reserved_proto_goTypes = []any{
+	(*Request)(nil),  // 0: connect.test.mocks.Request
+	(*Response)(nil), // 1: connect.test.mocks.Response
+}
+var file_reserved_proto_depIdxs = []int32{
+	0, // 0: connect.test.mocks.ReservedService.Client:input_type -> connect.test.mocks.Request
+	0, // 1: connect.test.mocks.ReservedService.Record:input_type -> connect.test.mocks.Request
+	0, // 2: connect.test.mocks.ReservedService.Calls:input_type -> connect.test.mocks.Request
+	0, // 3: connect.test.mocks.ReservedService.CallsTo:input_type -> connect.test.mocks.Request
+	0, // 4: connect.test.mocks.ReservedService.Reset:input_type -> connect.test.mocks.Request
+	1, // 5: connect.test.mocks.ReservedService.Client:output_type -> connect.test.mocks.Response
+	1, // 6: connect.test.mocks.ReservedService.Record:output_type -> connect.test.mocks.Response
+	1, // 7: connect.test.mocks.ReservedService.Calls:output_type -> connect.test.mocks.Response
+	1, // 8: connect.test.mocks.ReservedService.CallsTo:output_type -> connect.test.mocks.Response
+	1, // 9: connect.test.mocks.ReservedService.Reset:output_type -> connect.test.mocks.Response
+	5, // [5:10] is the sub-list for method output_type
+	0, // [0:5] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_reserved_proto_init() }
+func file_reserved_proto_init() {
+	if File_reserved_proto != nil {
+		return
+	}
+	file_mocks_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_reserved_proto_rawDesc), len(file_reserved_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_reserved_proto_goTypes,
+		DependencyIndexes: file_reserved_proto_depIdxs,
+	}.Build()
+	File_reserved_proto = out.File
+	file_reserved_proto_goTypes = nil
+	file_reserved_proto_depIdxs = nil
+}
@@ -43,6 +43,12 @@
 //   - package_suffix: To generate into a sub-package of the package containing the
 //     base .pb.go files using the given suffix. An empty suffix denotes to
 //     generate into the same package as the base pb.go files. Default is "connect".
+//   - simple: To generate client and handler interfaces with simple function
+//     signatures, which use the generated messages directly rather than
+//     connect.Request and connect.Response wrappers.
+//   - mocks: To also generate a configurable fake of each service for unit
+//     tests. The fake implements the handler interface with function fields,
+//     records its calls, and provides a client that calls it in memory.
 //
 // For example, to generate into the same package as the base .pb.go files:
 //
@@ -92,6 +98,10 @@ const (
 	defaultPackageSuffix       = "connect"
 	packageSuffixFlagName      = "package_suffix"
 	simpleFlagName             = "simple"
+	mocksFlagName              = "mocks"
+
+	// fakeRecorderField is the generated fakes' scalpel.FakeRecorder field.
+	fakeRecorderField = "Recorder"
 
 	usage = "See https://connectrpc.com/docs/go/getting-started to learn how to use this plugin.\n\nFlags:\n  -h, --help\tPrint this help and exit.\n      --version\tPrint the version and exit."
 
@@ -123,18 +133,27 @@ func main() {
 		"Generate files into a sub-package of the package containing the base .pb.go files using the given suffix. An empty suffix denotes to generate into the same package as the base pb.go files.",
 	)
 	// "simple" is a bool, but we want to support just setting "simple" without needing to set "simple=true"
-	// We do this via making the flag a string, and then parsing manually in getSimpleBool.
+	// We do this via making the flag a string, and then parsing manually in getBool.
 	simpleString := flagSet.String(
 		simpleFlagName,
 		"false",
 		"Generate client and handler interfaces with simple function signatures. This eliminates the wrapper connect.Request and connect.Response types, instead having functions directly use generated RPC request and responses. Clients and handlers will instead use context.Contexts to propagate information such as headers. Most users will be more familiar with these interfaces than the default.",
 	)
+	mocksString := flagSet.String(
+		mocksFlagName,
+		"false",
+		"Generate a configurable fake of each service for unit tests. The fake has a function field for each method, records its calls, implements the handler interface, and provides a client that calls it in memory, without a transport.",
+	)
 	protogen.Options{
 		ParamFunc: flagSet.Set,
 	}.Run(
 		func(plugin *protogen.Plugin) error {
 			plugin.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL) | uint64(pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
-			simple, err := getSimpleBool(*simpleString)
+			simple, err := getBool(simpleFlagName, *simpleString)
+			if err != nil {
+				return err
+			}
+			mocks, err := getBool(mocksFlagName, *mocksString)
 			if err != nil {
 				return err
 			}
@@ -142,7 +161,7 @@ func main() {
 			plugin.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_2024
 			for _, file := range plugin.Files {
 				if file.Generate {
-					generate(plugin, file, *packageSuffix, simple)
+					generate(plugin, file, *packageSuffix, simple, mocks)
 				}
 			}
 			return nil
@@ -150,7 +169,7 @@ func main() {
 	)
 }
 
-func generate(plugin *protogen.Plugin, file *protogen.File, packageSuffix string, simple bool) {
+func generate(plugin *protogen.Plugin, file *protogen.File, packageSuffix string, simple, mocks bool) {
 	if len(file.Services) == 0 {
 		return
 	}
@@ -180,10 +199,18 @@ func generate(plugin *protogen.Plugin, file *protogen.File, packageSuffix string
 	if packageSuffix != "" {
 		generatedFile.Import(file.GoImportPath)
 	}
+	if mocks {
+		for _, service := range file.Services {
+			if err := checkFakeNames(service); err != nil {
+				plugin.Error(err)
+				return
+			}
+		}
+	}
 	generatePreamble(generatedFile, file)
 	generateServiceNameConstants(generatedFile, file.Services)
 	for _, service := range file.Services {
-		generateService(generatedFile, file, service, simple)
+		generateService(generatedFile, file, service, simple, mocks)
 	}
 }
 
@@ -278,13 +305,16 @@ func generateServiceMethodsVar(g *protogen.GeneratedFile, file *protogen.File, s
 		`.Services().ByName("`, service.Desc.Name(), `").Methods()`)
 }
 
-func generateService(g *protogen.GeneratedFile, file *protogen.File, service *protogen.Service, simple bool) {
+func generateService(g *protogen.GeneratedFile, file *protogen.File, service *protogen.Service, simple, mocks bool) {
 	names := newNames(service)
 	generateClientInterface(g, service, names, simple)
 	generateClientImplementation(g, file, service, names, simple)
 	generateServerInterface(g, service, names, simple)
 	generateServerConstructor(g, file, service, names, simple)
 	generateUnimplementedServerImplementation(g, service, names, simple)
+	if mocks {
+		generateFake(g, service, names, simple)
+	}
 }
 
 func generateClientInterface(g *protogen.GeneratedFile, service *protogen.Service, names names, simple bool) {
@@ -338,6 +368,9 @@ func generateClientImplementation(g *protogen.GeneratedFile, file *protogen.File
 		g.P("httpClient,")
 		g.P(`baseURL + `, procedureConstName(method), `,`)
 		g.P(connectPackage.Ident("WithSchema"), "(", procedureVarMethodDescriptor(method), "),")
+		if idempotency := methodIdempotency(method); idempotency != "" {
+			g.P(connectPackage.Ident("WithIdempotency"), "(", connectPackage.Ident(idempotency), "),")
+		}
 		g.P(connectPackage.Ident("WithClientOptions"), "(opts...),")
 		g.P("),")
 	}
@@ -517,6 +550,9 @@ func generateServerConstructor(g *protogen.GeneratedFile, file *protogen.File, s
 		g.P(procedureConstName(method), `,`)
 		g.P("svc.", method.GoName, ",")
 		g.P(connectPackage.Ident("WithSchema"), "(", procedureVarMethodDescriptor(method), "),")
+		if idempotency := methodIdempotency(method); idempotency != "" {
+			g.P(connectPackage.Ident("WithIdempotency"), "(", connectPackage.Ident(idempotency), "),")
+		}
 		g.P(connectPackage.Ident("WithHandlerOptions"), "(opts...),")
 		g.P(")")
 	}
@@ -555,6 +591,91 @@ func generateUnimplementedServerImplementation(g *protogen.GeneratedFile, servic
 	g.P()
 }
 
+func generateFake(g *protogen.GeneratedFile, service *protogen.Service, names names, simple bool) {
+	wrapComments(g, names.Fake, " is a configurable fake of the ", service.Desc.FullName(),
+		" service for unit tests. Each method calls the corresponding function field, or returns ",
+		"CodeUnimplemented if the field is nil. Set the fields before making calls.")
+	g.P("//")
+	wrapComments(g, names.Fake, " implements ", names.Server, " and records every call in its Recorder. ",
+		"Use ", names.FakeClientConstructor, " to call the fake in memory, without a transport.")
+	g.P("type ", names.Fake, " struct {")
+	g.P(fakeRecorderField, " ", connectPackage.Ident("FakeRecorder"))
+	g.P()
+	for _, method := range service.Methods {
+		g.P(fakeFuncName(method), " func", serverSignatureParams(g, method, false /* named */, simple))
+	}
+	g.P("}")
+	g.P()
+	for _, method := range service.Methods {
+		isStreamingClient := method.Desc.IsStreamingClient()
+		isStreamingServer := method.Desc.IsStreamingServer()
+		wrapComments(g, method.GoName, " records the call and calls ", fakeFuncName(method), ".")
+		g.P("func (f *", names.Fake, ") ", method.GoName, serverSignatureParams(g, method, true /* named */, simple), " {")
+		var request, args string
+		switch {
+		case isStreamingClient:
+			request, args = "nil", "ctx, stream"
+		case isStreamingServer:
+			request, args = "req.Msg", "ctx, req, stream"
+		default:
+			request, args = "req.Msg", "ctx, req"
+		}
+		if simple && !isStreamingClient {
+			request = "req"
+		}
+		g.P("f.", fakeRecorderField, ".Record(", procedureConstName(method), ", ", request, ")")
+		g.P("if f.", fakeFuncName(method), " == nil {")
+		unimplemented := g.QualifiedGoIdent(connectPackage.Ident("NewError")) + "(" +
+			g.QualifiedGoIdent(connectPackage.Ident("CodeUnimplemented")) + ", " +
+			g.QualifiedGoIdent(errorsPackage.Ident("New")) + `("` + string(method.Desc.FullName()) + ` is not implemented"))`
+		if isStreamingServer {
+			g.P("return ", unimplemented)
+		} else {
+			g.P("return nil, ", unimplemented)
+		}
+		g.P("}")
+		g.P("return f.", fakeFuncName(method), "(", args, ")")
+		g.P("}")
+		g.P()
+	}
+
+	wrapComments(g, names.FakeClientConstructor, " returns a ", names.Client, " that calls the fake in memory, ",
+		"without a transport. Messages are copied rather than shared, and headers, trailers, and errors ",
+		"are propagated as though the calls were made over the network.")
+	g.P("func ", names.FakeClientConstructor, "(fake *", names.Fake, ") ", names.Client, " {")
+	g.P("return &", names.FakeClientImpl, "{fake: fake}")
+	g.P("}")
+	g.P()
+	wrapComments(g, names.FakeClientImpl, " implements ", names.Client, " by calling a ", names.Fake, ".")
+	g.P("type ", names.FakeClientImpl, " struct {")
+	g.P("fake *", names.Fake)
+	g.P("}")
+	g.P()
+	for _, method := range service.Methods {
+		isStreamingClient := method.Desc.IsStreamingClient()
+		isStreamingServer := method.Desc.IsStreamingServer()
+		var call, request string
+		switch {
+		case isStreamingClient && !isStreamingServer:
+			call = "CallFakeClientStream"
+		case !isStreamingClient && isStreamingServer:
+			call, request = "CallFakeServerStream", "req, "
+		case isStreamingClient && isStreamingServer:
+			call = "CallFakeBidiStream"
+		default:
+			call, request = "CallFakeUnary", "req, "
+		}
+		if simple {
+			call += "Simple"
+		}
+		wrapComments(g, method.GoName, " calls ", names.Fake, ".", method.GoName, ".")
+		g.P("func (c *", names.FakeClientImpl, ") ", clientSignature(g, method, true /* named */, simple), " {")
+		g.P("return ", connectPackage.Ident(call), "(ctx, ", procedureConstName(method), ", ", request, "c.fake.", method.GoName, ")")
+		g.P("}")
+		g.P()
+	}
+}
+
 func serverSignature(g *protogen.GeneratedFile, method *protogen.Method, simple bool) string {
 	return method.GoName + serverSignatureParams(g, method, false /* named */, simple)
 }
@@ -622,6 +743,28 @@ func procedureConstName(m *protogen.Method) string {
 	return fmt.Sprintf("%s%sProcedure", m.Parent.GoName, m.GoName)
 }
 
+func fakeFuncName(m *protogen.Method) string {
+	return m.GoName + "Func"
+}
+
+// checkFakeNames reports an error if the fields of a service's generated fake
+// would collide with its methods.
+func checkFakeNames(service *protogen.Service) error {
+	fields := map[string]bool{fakeRecorderField: true}
+	for _, method := range service.Methods {
+		fields[fakeFuncName(method)] = true
+	}
+	for _, method := range service.Methods {
+		if fields[method.GoName] {
+			return fmt.Errorf(
+				"cannot generate a fake of %s: method %s collides with a field of the fake; generate without the mocks option",
+				service.Desc.FullName(), method.Desc.Name(),
+			)
+		}
+	}
+	return nil
+}
+
 func procedureHandlerName(m *protogen.Method) string {
 	return fmt.Sprintf("%s%sHandler", unexport(m.Parent.GoName), m.GoName)
 }
@@ -645,6 +788,23 @@ func isDeprecatedMethod(method *protogen.Method) bool {
 	return ok && methodOptions.GetDeprecated()
 }
 
+// methodIdempotency returns the name of the scalpel.IdempotencyLevel constant
+// for the method's idempotency_level option, or "" if the level is unknown.
+func methodIdempotency(method *protogen.Method) string {
+	methodOptions, ok := method.Desc.Options().(*descriptorpb.MethodOptions)
+	if !ok {
+		return ""
+	}
+	switch methodOptions.GetIdempotencyLevel() {
+	case descriptorpb.MethodOptions_NO_SIDE_EFFECTS:
+		return "IdempotencyNoSideEffects"
+	case descriptorpb.MethodOptions_IDEMPOTENT:
+		return "IdempotencyIdempotent"
+	default:
+		return ""
+	}
+}
+
 // Raggedy comments in the generated code are driving me insane. This
 // word-wrapping function is ruinously inefficient, but it gets the job done.
 func wrapComments(g *protogen.GeneratedFile, elems ...any) {
@@ -711,38 +871,44 @@ func unexport(s string) string {
 }
 
 type names struct {
-	Base                string
-	Client              string
-	ClientConstructor   string
-	ClientImpl          string
-	ClientExposeMethod  string
-	Server              string
-	ServerConstructor   string
-	UnimplementedServer string
+	Base                  string
+	Client                string
+	ClientConstructor     string
+	ClientImpl            string
+	ClientExposeMethod    string
+	Server                string
+	ServerConstructor     string
+	UnimplementedServer   string
+	Fake                  string
+	FakeClientConstructor string
+	FakeClientImpl        string
 }
 
 func newNames(service *protogen.Service) names {
 	base := service.GoName
 	return names{
-		Base:                base,
-		Client:              fmt.Sprintf("%sClient", base),
-		ClientConstructor:   fmt.Sprintf("New%sClient", base),
-		ClientImpl:          fmt.Sprintf("%sClient", unexport(base)),
-		Server:              fmt.Sprintf("%sHandler", base),
-		ServerConstructor:   fmt.Sprintf("New%sHandler", base),
-		UnimplementedServer: fmt.Sprintf("Unimplemented%sHandler", base),
+		Base:                  base,
+		Client:                fmt.Sprintf("%sClient", base),
+		ClientConstructor:     fmt.Sprintf("New%sClient", base),
+		ClientImpl:            fmt.Sprintf("%sClient", unexport(base)),
+		Server:                fmt.Sprintf("%sHandler", base),
+		ServerConstructor:     fmt.Sprintf("New%sHandler", base),
+		UnimplementedServer:   fmt.Sprintf("Unimplemented%sHandler", base),
+		Fake:                  fmt.Sprintf("Fake%s", base),
+		FakeClientConstructor: fmt.Sprintf("NewFake%sClient", base),
+		FakeClientImpl:        fmt.Sprintf("fake%sClient", base),
 	}
 }
 
-// "simple" is a bool, but we want to support just setting "simple" without needing to set "simple=true"
-// We do this via making the flag a string, and then parsing manually here.
-func getSimpleBool(simpleString string) (bool, error) {
-	switch simpleString {
+// "simple" and "mocks" are bools, but we want to support just setting "simple" without needing to set "simple=true"
+// We do this via making the flags strings, and then parsing manually here.
+func getBool(name, value string) (bool, error) {
+	switch value {
 	case "", "true":
 		return true, nil
 	case "false":
 		return false, nil
 	default:
-		return false, fmt.Errorf(`unknown value for option "simple" (must be one of "", "true", "false"): %q`, simpleString)
+		return false, fmt.Errorf(`unknown value for option %q (must be one of "", "true", "false"): %q`, name, value)
 	}
 }
@@ -41,6 +41,8 @@ import (
 	defaultpackageconnect "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/defaultpackage/gen/genconnect"
 	diffpackage "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/diffpackage/gen"
 	diffpackagediff "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/diffpackage/gen/gendiff"
+	mocks "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen"
+	mocksconnect "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen/genconnect"
 	noservice "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/noservice/gen"
 	samepackage "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/samepackage/gen"
 	simple "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/simple/gen"
@@ -200,6 +202,82 @@ func TestGenerate(t *testing.T) {
 			testCmpToTestdata(t, file.GetContent(), "internal/testdata/simple/gen/genconnect/simple.connect.go")
 		}
 	})
+	t.Run("mocks.proto", func(t *testing.T) {
+		t.Parallel()
+		mocksFileDesc := protodesc.ToFileDescriptorProto(mocks.File_mocks_proto)
+		for _, parameter := range []string{"mocks", "mocks=true"} {
+			req := &pluginpb.CodeGeneratorRequest{
+				FileToGenerate:        []string{"mocks.proto"},
+				Parameter:             ptr(parameter),
+				ProtoFile:             []*descriptorpb.FileDescriptorProto{mocksFileDesc},
+				SourceFileDescriptors: []*descriptorpb.FileDescriptorProto{mocksFileDesc},
+				CompilerVersion:       compilerVersion,
+			}
+			rsp := testGenerate(t, req)
+			assert.Nil(t, rsp.Error)
+
+			assert.Equal(t, len(rsp.File), 1)
+			file := rsp.File[0]
+			assert.Equal(t, file.GetName(), "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen/genconnect/mocks.connect.go")
+			testCmpToTestdata(t, file.GetContent(), "internal/testdata/mocks/gen/genconnect/mocks.connect.go")
+		}
+	})
+	// Methods may share names with the fake's API.
+	t.Run("reserved.proto", func(t *testing.T) {
+		t.Parallel()
+		mocksFileDesc := protodesc.ToFileDescriptorProto(mocks.File_mocks_proto)
+		reservedFileDesc := protodesc.ToFileDescriptorProto(mocks.File_reserved_proto)
+		req := &pluginpb.CodeGeneratorRequest{
+			FileToGenerate:        []string{"reserved.proto"},
+			Parameter:             ptr("mocks"),
+			ProtoFile:             []*descriptorpb.FileDescriptorProto{mocksFileDesc, reservedFileDesc},
+			SourceFileDescriptors: []*descriptorpb.FileDescriptorProto{reservedFileDesc},
+			CompilerVersion:       compilerVersion,
+		}
+		rsp := testGenerate(t, req)
+		assert.Nil(t, rsp.Error)
+
+		assert.Equal(t, len(rsp.File), 1)
+		file := rsp.File[0]
+		assert.Equal(t, file.GetName(), "github.com/agentio/scalpel/cmd/protoc-gen-scalpel-go/internal/testdata/mocks/gen/genconnect/reserved.connect.go")
+		testCmpToTestdata(t, file.GetContent(), "internal/testdata/mocks/gen/genconnect/reserved.connect.go")
+	})
+	// Methods can't share names with the fake's fields.
+	t.Run("mocks.proto:colliding_methods", func(t *testing.T) {
+		t.Parallel()
+		for _, name := range []string{"MethodFunc", "Recorder"} {
+			mocksFileDesc := protodesc.ToFileDescriptorProto(mocks.File_mocks_proto)
+			service := mocksFileDesc.GetService()[0]
+			method := proto.CloneOf(service.GetMethod()[0])
+			method.Name = ptr(name)
+			service.Method = append(service.Method, method)
+			req := &pluginpb.CodeGeneratorRequest{
+				FileToGenerate:        []string{"mocks.proto"},
+				Parameter:             ptr("mocks"),
+				ProtoFile:             []*descriptorpb.FileDescriptorProto{mocksFileDesc},
+				SourceFileDescriptors: []*descriptorpb.FileDescriptorProto{mocksFileDesc},
+				CompilerVersion:       compilerVersion,
+			}
+			rsp := testGenerate(t, req)
+			assert.NotNil(t, rsp.Error)
+			assert.Equal(t, *rsp.Error, "cannot generate a fake of connect.test.mocks.TestService: method "+name+
+				" collides with a field of the fake; generate without the mocks option")
+		}
+	})
+	t.Run("mocks.proto:invalid_mocks", func(t *testing.T) {
+		t.Parallel()
+		mocksFileDesc := protodesc.ToFileDescriptorProto(mocks.File_mocks_proto)
+		req := &pluginpb.CodeGeneratorRequest{
+			FileToGenerate:        []string{"mocks.proto"},
+			Parameter:             ptr("mocks=maybe"),
+			ProtoFile:             []*descriptorpb.FileDescriptorProto{mocksFileDesc},
+			SourceFileDescriptors: []*descriptorpb.FileDescriptorProto{mocksFileDesc},
+			CompilerVersion:       compilerVersion,
+		}
+		rsp := testGenerate(t, req)
+		assert.NotNil(t, rsp.Error)
+		assert.Equal(t, *rsp.Error, `unknown value for option "mocks" (must be one of "", "true", "false"): "maybe"`)
+	})
 }
 
 func TestClientHandler(t *testing.T) {
@@ -238,6 +316,49 @@ func TestClientHandler(t *testing.T) {
 		assert.Nil(t, err)
 		assert.NotNil(t, rsp)
 	})
+	t.Run("mocks.proto", func(t *testing.T) {
+		t.Parallel()
+		fake := &mocksconnect.FakeTestService{
+			MethodFunc: func(context.Context, *connect.Request[mocks.Request]) (*connect.Response[mocks.Response], error) {
+				return connect.NewResponse(&mocks.Response{}), nil
+			},
+		}
+		// The fake works both in memory and as a handler.
+		mux := http.NewServeMux()
+		mux.Handle(mocksconnect.NewTestServiceHandler(fake))
+		server := httptest.NewServer(mux)
+		clients := []mocksconnect.TestServiceClient{
+			mocksconnect.NewFakeTestServiceClient(fake),
+			mocksconnect.NewTestServiceClient(server.Client(), server.URL),
+		}
+		for _, client := range clients {
+			rsp, err := client.Method(ctx, connect.NewRequest(&mocks.Request{}))
+			assert.Nil(t, err)
+			assert.NotNil(t, rsp)
+			_, err = client.MethodClientStream(ctx).CloseAndReceive()
+			assert.Equal(t, connect.CodeOf(err), connect.CodeUnimplemented)
+		}
+		calls := fake.Recorder.CallsTo(mocksconnect.TestServiceMethodProcedure)
+		assert.Equal(t, len(calls), 2)
+		assert.Equal(t, len(fake.Recorder.Calls()), 4)
+	})
+	t.Run("reserved.proto", func(t *testing.T) {
+		t.Parallel()
+		fake := &mocksconnect.FakeReservedService{
+			RecordFunc: func(context.Context, *connect.Request[mocks.Request]) (*connect.Response[mocks.Response], error) {
+				return connect.NewResponse(&mocks.Response{}), nil
+			},
+		}
+		client := mocksconnect.NewFakeReservedServiceClient(fake)
+		_, err := client.Record(ctx, connect.NewRequest(&mocks.Request{}))
+		assert.Nil(t, err)
+		_, err = client.Reset(ctx, connect.NewRequest(&mocks.Request{}))
+		assert.Equal(t, connect.CodeOf(err), connect.CodeUnimplemented)
+		calls := fake.Recorder.Calls()
+		assert.Equal(t, len(calls), 2)
+		assert.Equal(t, calls[0].Procedure, mocksconnect.ReservedServiceRecordProcedure)
+		assert.Equal(t, calls[1].Procedure, mocksconnect.ReservedServiceResetProcedure)
+	})
 }
 
 func testCmpToTestdata(t *testing.T, content, path string) {
@@ -0,0 +1,257 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/agentio/scalpel"
+	reflectionv1 "github.com/agentio/scalpel/internal/gen/connectext/grpc/reflection/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The reflection service's names. The v1alpha API is the same as v1, so it's
+// only a fallback for older servers.
+var reflectionProcedures = []string{ //nolint:gochecknoglobals
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// loadProtosets reads serialized FileDescriptorSets, like those written by
+// "buf build -o" or "protoc --descriptor_set_out --include_imports".
+func loadProtosets(paths []string) (*protoregistry.Files, error) {
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var set descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, file := range set.GetFile() {
+			if !seen[file.GetName()] {
+				seen[file.GetName()] = true
+				fileSet.File = append(fileSet.File, file)
+			}
+		}
+	}
+	files, err := protodesc.NewFiles(fileSet)
+	if err != nil {
+		return nil, fmt.Errorf("protoset: %w", err)
+	}
+	return files, nil
+}
+
+// serviceNames returns the names of the services in files, sorted.
+func serviceNames(files *protoregistry.Files) []string {
+	var names []string
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := range file.Services().Len() {
+			names = append(names, string(file.Services().Get(i).FullName()))
+		}
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// reflectionClient fetches descriptors from a server's gRPC reflection
+// service, over a single bidirectional stream.
+type reflectionClient struct {
+	stream *scalpel.BidiStreamForClient[reflectionv1.ServerReflectionRequest, reflectionv1.ServerReflectionResponse]
+	files  map[string]*descriptorpb.FileDescriptorProto
+	order  []string
+}
+
+func newReflectionClient(
+	ctx context.Context,
+	httpClient scalpel.HTTPClient,
+	baseURL string,
+	header http.Header,
+	options []scalpel.ClientOption,
+) (*reflectionClient, error) {
+	var err error
+	for _, procedure := range reflectionProcedures {
+		client := scalpel.NewClient[reflectionv1.ServerReflectionRequest, reflectionv1.ServerReflectionResponse](
+			httpClient,
+			strings.TrimRight(baseURL, "/")+procedure,
+			options...,
+		)
+		reflection := &reflectionClient{
+			stream: client.CallBidiStream(ctx),
+			files:  make(map[string]*descriptorpb.FileDescriptorProto),
+		}
+		for key, values := range header {
+			reflection.stream.RequestHeader()[key] = values
+		}
+		// Check that the server implements this version of the API.
+		_, err = reflection.listServices()
+		if err == nil {
+			return reflection, nil
+		}
+		reflection.close()
+		if scalpel.CodeOf(err) != scalpel.CodeUnimplemented {
+			break
+		}
+	}
+	return nil, fmt.Errorf("server reflection: %w", err)
+}
+
+func (c *reflectionClient) listServices() ([]string, error) {
+	response, err := c.send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		names = append(names, service.GetName())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// resolve fetches the files defining the symbols, and all of their
+// dependencies.
+func (c *reflectionClient) resolve(symbols ...string) (*protoregistry.Files, error) {
+	for _, symbol := range symbols {
+		response, err := c.send(&reflectionv1.ServerReflectionRequest{
+			MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", symbol, err)
+		}
+		if err := c.addFiles(response); err != nil {
+			return nil, err
+		}
+	}
+	// The server usually sends dependencies along with the files that import
+	// them, but it may skip files it thinks we already have.
+	for i := 0; i < len(c.order); i++ {
+		for _, dependency := range c.files[c.order[i]].GetDependency() {
+			if _, ok := c.files[dependency]; ok {
+				continue
+			}
+			response, err := c.send(&reflectionv1.ServerReflectionRequest{
+				MessageRequest: &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+			})
+			if err != nil {
+				// Fall back to the descriptors compiled into this binary, which
+				// include the well-known types.
+				file, globalErr := protoregistry.GlobalFiles.FindFileByPath(dependency)
+				if globalErr != nil {
+					return nil, fmt.Errorf("resolve %s: %w", dependency, err)
+				}
+				c.add(protodesc.ToFileDescriptorProto(file))
+				continue
+			}
+			if err := c.addFiles(response); err != nil {
+				return nil, err
+			}
+		}
+	}
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	for _, name := range c.order {
+		fileSet.File = append(fileSet.File, c.files[name])
+	}
+	files, err := protodesc.NewFiles(fileSet)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	return files, nil
+}
+
+func (c *reflectionClient) close() {
+	_ = c.stream.CloseRequest()
+	_ = c.stream.CloseResponse()
+}
+
+func (c *reflectionClient) send(request *reflectionv1.ServerReflectionRequest) (*reflectionv1.ServerReflectionResponse, error) {
+	if err := c.stream.Send(request); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	response, err := c.stream.Receive()
+	if err != nil {
+		return nil, err
+	}
+	if errResponse := response.GetErrorResponse(); errResponse != nil {
+		return nil, scalpel.NewError(scalpel.Code(errResponse.GetErrorCode()), errors.New(errResponse.GetErrorMessage()))
+	}
+	return response, nil
+}
+
+func (c *reflectionClient) addFiles(response *reflectionv1.ServerReflectionResponse) error {
+	for _, data := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(data, file); err != nil {
+			return fmt.Errorf("server reflection: %w", err)
+		}
+		c.add(file)
+	}
+	return nil
+}
+
+func (c *reflectionClient) add(file *descriptorpb.FileDescriptorProto) {
+	if _, ok := c.files[file.GetName()]; !ok {
+		c.files[file.GetName()] = file
+		c.order = append(c.order, file.GetName())
+	}
+}
+
+// findMethod finds a method named like "acme.foo.v1.FooService/Bar" or
+// "acme.foo.v1.FooService.Bar".
+func findMethod(files *protoregistry.Files, name string) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, ok := splitMethodName(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid method %q: want a name like acme.foo.v1.FooService/Bar", name)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("service %s has no method %s", serviceName, methodName)
+	}
+	return method, nil
+}
+
+func splitMethodName(name string) (service, method string, ok bool) {
+	name = strings.TrimPrefix(name, "/")
+	index := strings.LastIndexAny(name, "/.")
+	if index <= 0 || index == len(name)-1 {
+		return "", "", false
+	}
+	return name[:index], name[index+1:], true
+}
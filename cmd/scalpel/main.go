@@ -0,0 +1,411 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// scalpel calls RPCs from the command line, much like grpcurl. It's built on
+// [scalpel.DynamicClient], so it speaks the gRPC, gRPC-Web, and Connect
+// protocols and behaves exactly like a Go client of the same server.
+//
+// Usage:
+//
+//	scalpel [flags] URL list
+//	scalpel [flags] URL METHOD
+//
+// The first form lists the server's services and their methods. The second
+// calls a method, named like acme.foo.v1.FooService/Bar. Schemas are loaded
+// from the files given with -protoset or, by default, from the server's gRPC
+// reflection service.
+//
+// Requests are JSON. With -d, they're read from the flag's value, or from
+// standard input if it's "@"; streaming methods take any number of
+// whitespace-separated requests. Without -d, unary and server streaming
+// methods are sent an empty request. Responses are printed to standard output
+// as they arrive. With -v, response headers and trailers are printed to
+// standard error. Errors are printed to standard error with their code,
+// message, and details, including the standard google.rpc details built with
+// the errdetails package.
+//
+// For example:
+//
+//	scalpel -d '{"number": 42}' -H 'Authorization: Bearer token' \
+//	  http://localhost:8080 connect.ping.v1.PingService/Ping
+//
+// Plaintext http:// URLs use HTTP/2 without TLS, which gRPC requires. To use
+// the Connect or gRPC-Web protocols with a server that only supports
+// HTTP/1.1, add -http1.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/errdetails"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	usage = `Usage:
+  scalpel [flags] URL list
+  scalpel [flags] URL METHOD
+
+Flags:
+`
+	exitError = 1
+	exitUsage = 2
+)
+
+// grpcErrorHeaders carry gRPC errors, which are printed separately.
+var grpcErrorHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"} //nolint:gochecknoglobals
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cmd := &command{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	exitCode := cmd.run(ctx, os.Args[1:])
+	stop()
+	os.Exit(exitCode)
+}
+
+// command is a single run of the CLI. Its fields are set by tests.
+type command struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	// httpClient overrides the client built from the flags.
+	httpClient scalpel.HTTPClient
+}
+
+func (c *command) run(ctx context.Context, args []string) int {
+	var (
+		flags     = flag.NewFlagSet("scalpel", flag.ContinueOnError)
+		protosets stringsFlag
+		headers   stringsFlag
+	)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprint(c.stderr, usage)
+		flags.PrintDefaults()
+	}
+	flags.Var(&protosets, "protoset", "load schemas from a serialized FileDescriptorSet, rather than using server reflection (repeatable)")
+	flags.Var(&headers, "H", `send a request header, like "Name: value" (repeatable)`)
+	data := flags.String("d", "", `JSON request data, or "@" to read requests from standard input`)
+	protocol := flags.String("protocol", "grpc", "protocol to use: grpc, grpcweb, or connect")
+	timeout := flags.Duration("timeout", 0, "time limit for the RPC, sent to the server (for example, 5s)")
+	http1 := flags.Bool("http1", false, "use HTTP/1.1 for plaintext URLs, rather than HTTP/2 without TLS")
+	verbose := flags.Bool("v", false, "print response headers, trailers, and error metadata")
+	version := flags.Bool("version", false, "print the version and exit")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitUsage
+	}
+	if *version {
+		fmt.Fprintln(c.stdout, scalpel.Version)
+		return 0
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitUsage
+	}
+	baseURL, target := flags.Arg(0), flags.Arg(1)
+	options, err := protocolOptions(*protocol)
+	if err != nil {
+		return c.fail(err)
+	}
+	header, err := parseHeaders(headers)
+	if err != nil {
+		return c.fail(err)
+	}
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = newHTTPClient(*http1)
+	}
+
+	var (
+		files      *protoregistry.Files
+		reflection *reflectionClient
+	)
+	if len(protosets) > 0 {
+		files, err = loadProtosets(protosets)
+	} else {
+		reflection, err = newReflectionClient(ctx, httpClient, baseURL, header, options)
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+	if reflection != nil {
+		defer reflection.close()
+	}
+
+	if target == "list" {
+		return c.list(files, reflection)
+	}
+	if reflection != nil {
+		serviceName, _, ok := splitMethodName(target)
+		if !ok {
+			return c.fail(fmt.Errorf("invalid method %q: want a name like acme.foo.v1.FooService/Bar", target))
+		}
+		if files, err = reflection.resolve(serviceName); err != nil {
+			return c.fail(err)
+		}
+		reflection.close()
+	}
+	method, err := findMethod(files, target)
+	if err != nil {
+		return c.fail(err)
+	}
+	var requests *json.Decoder
+	switch *data {
+	case "":
+	case "@":
+		requests = json.NewDecoder(c.stdin)
+	default:
+		requests = json.NewDecoder(strings.NewReader(*data))
+	}
+	client := scalpel.NewDynamicClient(httpClient, baseURL, method, options...)
+	responseHeader, responseTrailer, err := client.CallJSON(ctx, header, requests, func(message json.RawMessage) error {
+		return c.printJSON(message)
+	})
+	if err != nil {
+		c.printError(err, files, *verbose)
+		return exitError
+	}
+	if *verbose {
+		c.printHeaders("Response headers", responseHeader)
+		c.printHeaders("Response trailers", responseTrailer)
+	}
+	return 0
+}
+
+func (c *command) list(files *protoregistry.Files, reflection *reflectionClient) int {
+	var names []string
+	if reflection != nil {
+		var err error
+		if names, err = reflection.listServices(); err != nil {
+			return c.fail(err)
+		}
+	} else {
+		names = serviceNames(files)
+	}
+	for _, name := range names {
+		fmt.Fprintln(c.stdout, name)
+		if reflection != nil {
+			// Some servers can't describe every service they list, so the
+			// methods are omitted if the service can't be resolved.
+			var err error
+			if files, err = reflection.resolve(name); err != nil {
+				continue
+			}
+		}
+		desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			continue
+		}
+		if service, ok := desc.(protoreflect.ServiceDescriptor); ok {
+			for i := range service.Methods().Len() {
+				fmt.Fprintf(c.stdout, "  %s/%s\n", name, service.Methods().Get(i).Name())
+			}
+		}
+	}
+	return 0
+}
+
+func (c *command) printJSON(message json.RawMessage) error {
+	// Protobuf's JSON output deliberately varies its whitespace, so normalize
+	// it.
+	var compact, indented bytes.Buffer
+	if err := json.Compact(&compact, message); err != nil {
+		return err
+	}
+	if err := json.Indent(&indented, compact.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	indented.WriteByte('\n')
+	_, err := c.stdout.Write(indented.Bytes())
+	return err
+}
+
+func (c *command) printError(err error, files *protoregistry.Files, verbose bool) {
+	var connectErr *scalpel.Error
+	if !errors.As(err, &connectErr) {
+		fmt.Fprintf(c.stderr, "Error: %v\n", err)
+		return
+	}
+	fmt.Fprintf(c.stderr, "Error:\n  Code: %s\n  Message: %s\n", connectErr.Code(), connectErr.Message())
+	if details := connectErr.Details(); len(details) > 0 {
+		fmt.Fprintln(c.stderr, "  Details:")
+		for _, detail := range details {
+			fmt.Fprintf(c.stderr, "    %s: %s\n", detail.Type(), formatDetail(detail, files))
+		}
+	}
+	if verbose {
+		meta := connectErr.Meta().Clone()
+		for _, key := range grpcErrorHeaders {
+			meta.Del(key)
+		}
+		c.printHeaders("Error metadata", meta)
+	}
+}
+
+func (c *command) printHeaders(title string, header http.Header) {
+	fmt.Fprintf(c.stderr, "%s:\n", title)
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(c.stderr, "  %s: %s\n", strings.ToLower(key), value)
+		}
+	}
+}
+
+func (c *command) fail(err error) int {
+	fmt.Fprintf(c.stderr, "scalpel: %v\n", err)
+	return exitError
+}
+
+// formatDetail formats an error detail as JSON if its type is known, either
+// from the loaded schemas or because it's compiled into this program.
+// Standard google.rpc details are decoded with the errdetails package. Unknown
+// details are printed in base64.
+func formatDetail(detail *scalpel.ErrorDetail, files *protoregistry.Files) string {
+	var candidates []protoreflect.MessageType
+	if files != nil {
+		if messageType, err := dynamicpb.NewTypes(files).FindMessageByName(protoreflect.FullName(detail.Type())); err == nil {
+			candidates = append(candidates, messageType)
+		}
+	}
+	if messageType, ok := standardDetailType(detail.Type()); ok {
+		candidates = append(candidates, messageType)
+	}
+	if messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(detail.Type())); err == nil {
+		candidates = append(candidates, messageType)
+	}
+	for _, messageType := range candidates {
+		msg := messageType.New().Interface()
+		if err := proto.Unmarshal(detail.Bytes(), msg); err != nil {
+			continue
+		}
+		data, err := protojson.Marshal(msg)
+		if err != nil {
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			continue
+		}
+		return compact.String()
+	}
+	return scalpel.EncodeBinaryHeader(detail.Bytes())
+}
+
+// standardDetailType returns the errdetails type for a standard google.rpc
+// detail, given the name it's sent with.
+func standardDetailType(name string) (protoreflect.MessageType, bool) {
+	for _, msg := range []proto.Message{
+		&errdetails.ErrorInfo{},
+		&errdetails.RetryInfo{},
+		&errdetails.DebugInfo{},
+		&errdetails.QuotaFailure{},
+		&errdetails.PreconditionFailure{},
+		&errdetails.BadRequest{},
+		&errdetails.RequestInfo{},
+		&errdetails.ResourceInfo{},
+		&errdetails.Help{},
+		&errdetails.LocalizedMessage{},
+	} {
+		messageType := msg.ProtoReflect().Type()
+		if name == "google.rpc."+string(messageType.Descriptor().Name()) {
+			return messageType, true
+		}
+	}
+	return nil, false
+}
+
+func protocolOptions(protocol string) ([]scalpel.ClientOption, error) {
+	switch protocol {
+	case "grpc":
+		return []scalpel.ClientOption{scalpel.WithGRPC()}, nil
+	case "grpcweb", "grpc-web":
+		return []scalpel.ClientOption{scalpel.WithGRPCWeb()}, nil
+	case "connect":
+		return []scalpel.ClientOption{scalpel.WithConnect()}, nil
+	default:
+		return nil, fmt.Errorf("unknown protocol %q: want grpc, grpcweb, or connect", protocol)
+	}
+}
+
+func parseHeaders(values []string) (http.Header, error) {
+	header := make(http.Header, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid header %q: want a value like \"Name: value\"", value)
+		}
+		header.Add(strings.TrimSpace(key), strings.TrimSpace(val))
+	}
+	return header, nil
+}
+
+func newHTTPClient(http1 bool) *http.Client {
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetHTTP1(true)
+	transport.Protocols.SetHTTP2(true)
+	if !http1 {
+		// Without HTTP/1.1, plaintext requests use HTTP/2 with prior
+		// knowledge.
+		transport.Protocols.SetHTTP1(false)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	}
+	return &http.Client{Transport: transport}
+}
+
+// stringsFlag is a repeatable string flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
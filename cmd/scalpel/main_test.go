@@ -0,0 +1,283 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/errdetails"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"github.com/agentio/scalpel/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const pingMethod = "connect.ping.v1.PingService/"
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+	server := newTestServer(t)
+	protoset := writeProtoset(t)
+	t.Run("unary", func(t *testing.T) {
+		t.Parallel()
+		for _, protocol := range []string{"grpc", "grpcweb", "connect"} {
+			stdout, stderr, code := runCommand(t, server, "",
+				"-protocol", protocol, "-v", "-H", "Echo: hello",
+				"-d", `{"number": 42, "text": "hi"}`,
+				server.URL(), pingMethod+"Ping",
+			)
+			assert.Equal(t, code, 0, assert.Sprintf("protocol %s: %s", protocol, stderr))
+			assert.Equal(t, stdout, "{\n  \"number\": \"42\",\n  \"text\": \"hi\"\n}\n")
+			assert.True(t, strings.Contains(stderr, "Response headers:\n"))
+			assert.True(t, strings.Contains(stderr, "  echo: hello\n"))
+			assert.True(t, strings.Contains(stderr, "Response trailers:\n  echo: trailer\n"))
+		}
+	})
+	t.Run("protoset", func(t *testing.T) {
+		t.Parallel()
+		stdout, stderr, code := runCommand(t, server, "", "-protoset", protoset, server.URL(), pingMethod+"Ping")
+		assert.Equal(t, code, 0, assert.Sprintf("stderr: %s", stderr))
+		assert.Equal(t, stdout, "{}\n")
+	})
+	t.Run("client_stream", func(t *testing.T) {
+		t.Parallel()
+		stdout, stderr, code := runCommand(t, server, `{"number": 1}
+{"number": 2}`, "-d", "@", server.URL(), pingMethod+"Sum")
+		assert.Equal(t, code, 0, assert.Sprintf("stderr: %s", stderr))
+		assert.Equal(t, stdout, "{\n  \"sum\": \"3\"\n}\n")
+	})
+	t.Run("server_stream", func(t *testing.T) {
+		t.Parallel()
+		stdout, stderr, code := runCommand(t, server, "", "-d", `{"number": 2}`, server.URL(), pingMethod+"CountUp")
+		assert.Equal(t, code, 0, assert.Sprintf("stderr: %s", stderr))
+		assert.Equal(t, stdout, "{\n  \"number\": \"1\"\n}\n{\n  \"number\": \"2\"\n}\n")
+	})
+	t.Run("bidi_stream", func(t *testing.T) {
+		t.Parallel()
+		stdout, stderr, code := runCommand(t, server, `{"number": 1} {"number": 2}`, "-d", "@", server.URL(), pingMethod+"CumSum")
+		assert.Equal(t, code, 0, assert.Sprintf("stderr: %s", stderr))
+		assert.Equal(t, stdout, "{\n  \"sum\": \"1\"\n}\n{\n  \"sum\": \"3\"\n}\n")
+	})
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		stdout, stderr, code := runCommand(t, server, "", "-v", "-d", `{"code": 8}`, server.URL(), pingMethod+"Fail")
+		assert.Equal(t, code, exitError)
+		assert.Equal(t, stdout, "")
+		assert.True(t, strings.HasPrefix(stderr, `Error:
+  Code: resource_exhausted
+  Message: slow down
+  Details:
+    google.rpc.RetryInfo: {"retryDelay":"2s"}
+    connect.ping.v1.FailRequest: {"code":8}
+Error metadata:
+`), assert.Sprintf("stderr: %s", stderr))
+		assert.True(t, strings.Contains(stderr, "  reason: requested\n"))
+		// The error is already printed, so its protocol encoding isn't.
+		assert.False(t, strings.Contains(stderr, "grpc-status"))
+	})
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		_, stderr, code := runCommand(t, server, "", "-timeout", "50ms", "-d", `{"text": "block"}`, server.URL(), pingMethod+"Ping")
+		assert.Equal(t, code, exitError)
+		assert.True(t, strings.Contains(stderr, "Code: deadline_exceeded"), assert.Sprintf("stderr: %s", stderr))
+	})
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+		want := "connect.ping.v1.PingService\n" +
+			"  connect.ping.v1.PingService/Ping\n" +
+			"  connect.ping.v1.PingService/Fail\n" +
+			"  connect.ping.v1.PingService/Sum\n" +
+			"  connect.ping.v1.PingService/CountUp\n" +
+			"  connect.ping.v1.PingService/CumSum\n"
+		stdout, stderr, code := runCommand(t, server, "", "-protoset", protoset, server.URL(), "list")
+		assert.Equal(t, code, 0, assert.Sprintf("stderr: %s", stderr))
+		assert.Equal(t, stdout, want)
+		stdout, stderr, code = runCommand(t, server, "", server.URL(), "list")
+		assert.Equal(t, code, 0, assert.Sprintf("stderr: %s", stderr))
+		assert.True(t, strings.HasPrefix(stdout, want), assert.Sprintf("stdout: %s", stdout))
+		assert.True(t, strings.Contains(stdout, "grpc.reflection.v1.ServerReflection\n"))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, _, code := runCommand(t, server, "", server.URL())
+		assert.Equal(t, code, exitUsage)
+		_, _, code = runCommand(t, server, "", "-unknown", server.URL(), "list")
+		assert.Equal(t, code, exitUsage)
+		for _, args := range [][]string{
+			{"-protocol", "http", server.URL(), pingMethod + "Ping"},
+			{"-H", "no colon", server.URL(), pingMethod + "Ping"},
+			{server.URL(), "Ping"},
+			{server.URL(), pingMethod + "Unknown"},
+			{"-d", `{"unknown": 1}`, server.URL(), pingMethod + "Ping"},
+			{"-protoset", filepath.Join(t.TempDir(), "missing.binpb"), server.URL(), "list"},
+		} {
+			_, stderr, code := runCommand(t, server, "", args...)
+			assert.Equal(t, code, exitError, assert.Sprintf("args %v", args))
+			assert.NotZero(t, stderr)
+		}
+	})
+}
+
+func TestHTTPClient(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(&pingv1connect.FakePingService{
+		PingFunc: func(context.Context, *scalpel.Request[pingv1.PingRequest]) (*scalpel.Response[pingv1.PingResponse], error) {
+			return scalpel.NewResponse(&pingv1.PingResponse{}), nil
+		},
+	}))
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	t.Cleanup(server.Close)
+	// gRPC requires HTTP/2, so plaintext URLs use HTTP/2 without TLS by
+	// default. The other protocols also work over HTTP/1.1.
+	protoset := writeProtoset(t)
+	for _, args := range [][]string{
+		{"-protocol", "grpc"},
+		{"-protocol", "connect", "-http1"},
+		{"-protocol", "grpcweb", "-http1"},
+	} {
+		var stdout, stderr strings.Builder
+		cmd := &command{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+		args = append(args, "-protoset", protoset, server.URL, pingMethod+"Ping")
+		assert.Equal(t, cmd.run(t.Context(), args), 0, assert.Sprintf("args %v: %s", args, stderr.String()))
+		assert.Equal(t, stdout.String(), "{}\n")
+	}
+}
+
+func TestSplitMethodName(t *testing.T) {
+	t.Parallel()
+	for name, want := range map[string][2]string{
+		"acme.foo.v1.FooService/Bar":  {"acme.foo.v1.FooService", "Bar"},
+		"/acme.foo.v1.FooService/Bar": {"acme.foo.v1.FooService", "Bar"},
+		"acme.foo.v1.FooService.Bar":  {"acme.foo.v1.FooService", "Bar"},
+		"Bar":                         {},
+		"acme.foo.v1.FooService/":     {},
+	} {
+		service, method, ok := splitMethodName(name)
+		assert.Equal(t, ok, want != [2]string{}, assert.Sprintf("name %q", name))
+		assert.Equal(t, [2]string{service, method}, want)
+	}
+}
+
+func runCommand(tb testing.TB, server *memhttp.Server, stdin string, args ...string) (string, string, int) {
+	tb.Helper()
+	var stdout, stderr strings.Builder
+	cmd := &command{
+		stdin:      strings.NewReader(stdin),
+		stdout:     &stdout,
+		stderr:     &stderr,
+		httpClient: server.Client(),
+	}
+	code := cmd.run(tb.Context(), args)
+	return stdout.String(), stderr.String(), code
+}
+
+func newTestServer(tb testing.TB) *memhttp.Server {
+	tb.Helper()
+	ping := &pingv1connect.FakePingService{
+		PingFunc: func(ctx context.Context, request *scalpel.Request[pingv1.PingRequest]) (*scalpel.Response[pingv1.PingResponse], error) {
+			if request.Msg.GetText() == "block" {
+				<-ctx.Done()
+				return nil, scalpel.NewError(scalpel.CodeDeadlineExceeded, ctx.Err())
+			}
+			response := scalpel.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber(), Text: request.Msg.GetText()})
+			if echo := request.Header().Get("Echo"); echo != "" {
+				response.Header().Set("Echo", echo)
+				response.Trailer().Set("Echo", "trailer")
+			}
+			return response, nil
+		},
+		FailFunc: func(_ context.Context, request *scalpel.Request[pingv1.FailRequest]) (*scalpel.Response[pingv1.FailResponse], error) {
+			err := errdetails.AddRetryInfo(scalpel.NewError(scalpel.Code(request.Msg.GetCode()), errors.New("slow down")), 2*time.Second)
+			detail, detailErr := scalpel.NewErrorDetail(request.Msg)
+			if detailErr != nil {
+				return nil, detailErr
+			}
+			err.AddDetail(detail)
+			err.Meta().Set("Reason", "requested")
+			return nil, err
+		},
+		SumFunc: func(_ context.Context, stream *scalpel.ClientStream[pingv1.SumRequest]) (*scalpel.Response[pingv1.SumResponse], error) {
+			var sum int64
+			for stream.Receive() {
+				sum += stream.Msg().GetNumber()
+			}
+			return scalpel.NewResponse(&pingv1.SumResponse{Sum: sum}), stream.Err()
+		},
+		CountUpFunc: func(_ context.Context, request *scalpel.Request[pingv1.CountUpRequest], stream *scalpel.ServerStream[pingv1.CountUpResponse]) error {
+			for i := range request.Msg.GetNumber() {
+				if err := stream.Send(&pingv1.CountUpResponse{Number: i + 1}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		CumSumFunc: func(_ context.Context, stream *scalpel.BidiStream[pingv1.CumSumRequest, pingv1.CumSumResponse]) error {
+			var sum int64
+			for {
+				request, err := stream.Receive()
+				if errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				sum += request.GetNumber()
+				if err := stream.Send(&pingv1.CumSumResponse{Sum: sum}); err != nil {
+					return err
+				}
+			}
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(ping))
+	mux.Handle(reflection.NewHandlerV1(reflection.NewStaticReflector(
+		pingv1connect.PingServiceName,
+		reflection.ReflectV1ServiceName,
+	)))
+	return memhttptest.NewServer(tb, mux)
+}
+
+// writeProtoset writes the ping service's schema to a file.
+func writeProtoset(tb testing.TB) string {
+	tb.Helper()
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(pingv1.File_connect_ping_v1_ping_proto),
+		},
+	})
+	assert.Nil(tb, err)
+	path := filepath.Join(tb.TempDir(), "ping.binpb")
+	assert.Nil(tb, os.WriteFile(path, data, 0o600))
+	return path
+}
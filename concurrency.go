@@ -0,0 +1,269 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultAdaptiveMaxLimit is the upper bound of an adaptive limit if the
+	// policy doesn't set one.
+	defaultAdaptiveMaxLimit = 1000
+	// defaultAdaptiveTolerance is how much the recent latency may exceed the
+	// baseline before an adaptive limit shrinks.
+	defaultAdaptiveTolerance = 1.5
+	// The adaptive limit compares a fast-moving average of latency, covering
+	// roughly the last 10 RPCs, with a slow-moving baseline covering roughly
+	// the last 600.
+	adaptiveShortAlpha = 2.0 / (10 + 1)
+	adaptiveLongAlpha  = 2.0 / (600 + 1)
+	// adaptiveSmoothing is the weight given to each new estimate of the limit.
+	adaptiveSmoothing = 0.2
+)
+
+// A ConcurrencyPolicy configures a [ConcurrencyLimiter].
+type ConcurrencyPolicy struct {
+	// Limit is the maximum number of RPCs in flight. If the policy is
+	// adaptive, it's the initial limit. Values less than one are treated as
+	// one.
+	Limit int
+	// MaxQueue is the number of RPCs that may wait for another to finish once
+	// the limit is reached. RPCs beyond the limit and the queue are rejected
+	// immediately. If zero, RPCs are never queued.
+	MaxQueue int
+	// MaxQueueWait bounds how long an RPC waits in the queue. If zero, RPCs
+	// wait until their deadline.
+	MaxQueueWait time.Duration
+	// Code is the code of the error returned to rejected RPCs. It defaults to
+	// CodeResourceExhausted; CodeUnavailable is the other usual choice, since
+	// clients usually retry it.
+	Code Code
+	// RetryPushback, if non-zero, is sent to rejected clients as the gRPC
+	// retry pushback: the time that retrying clients should wait before trying
+	// again. If negative, it asks clients not to retry.
+	RetryPushback time.Duration
+	// Adaptive, if non-nil, tunes the limit from observed latency.
+	Adaptive *AdaptiveConcurrency
+}
+
+// AdaptiveConcurrency configures a [ConcurrencyLimiter] that tunes its limit
+// from the latency of unary RPCs, in the style of the gradient limiters used
+// for TCP congestion control and Netflix's concurrency-limits library.
+//
+// The limiter keeps a long-term baseline of latency and an average of recent
+// latency. While recent latency stays within Tolerance of the baseline, the
+// limit grows by roughly its square root; as the service saturates and
+// latency rises, the limit shrinks in proportion. The limit only grows while
+// at least half of it is in use.
+type AdaptiveConcurrency struct {
+	// MinLimit and MaxLimit bound the limit. MinLimit defaults to one, and
+	// MaxLimit defaults to 1000.
+	MinLimit int
+	MaxLimit int
+	// Tolerance is the ratio of recent latency to the baseline that's
+	// accepted before the limit shrinks. It defaults to 1.5, and values less
+	// than one are treated as one.
+	Tolerance float64
+}
+
+// A ConcurrencyLimiter caps the number of RPCs that handlers serve at once,
+// shedding load once the limit is reached instead of letting latency grow
+// without bound. Handlers configured with the same limiter share its limit:
+// see [WithConcurrencyLimit].
+//
+// ConcurrencyLimiters are safe to use concurrently.
+type ConcurrencyLimiter struct {
+	policy ConcurrencyPolicy
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	queue    []*concurrencyWaiter
+	shortRTT float64
+	longRTT  float64
+}
+
+// NewConcurrencyLimiter constructs a [ConcurrencyLimiter].
+func NewConcurrencyLimiter(policy ConcurrencyPolicy) *ConcurrencyLimiter {
+	policy.Limit = max(policy.Limit, 1)
+	policy.MaxQueue = max(policy.MaxQueue, 0)
+	if policy.Code == 0 {
+		policy.Code = CodeResourceExhausted
+	}
+	if adaptive := policy.Adaptive; adaptive != nil {
+		adaptive := *adaptive
+		adaptive.MinLimit = max(adaptive.MinLimit, 1)
+		if adaptive.MaxLimit <= 0 {
+			adaptive.MaxLimit = defaultAdaptiveMaxLimit
+		}
+		adaptive.MaxLimit = max(adaptive.MaxLimit, adaptive.MinLimit)
+		if adaptive.Tolerance == 0 {
+			adaptive.Tolerance = defaultAdaptiveTolerance
+		}
+		adaptive.Tolerance = max(adaptive.Tolerance, 1)
+		policy.Limit = min(max(policy.Limit, adaptive.MinLimit), adaptive.MaxLimit)
+		policy.Adaptive = &adaptive
+	}
+	return &ConcurrencyLimiter{
+		policy: policy,
+		limit:  float64(policy.Limit),
+	}
+}
+
+// Limit returns the current limit. It changes over time only if the limiter
+// is adaptive.
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of RPCs currently admitted by the limiter.
+func (l *ConcurrencyLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// concurrencyWaiter is an RPC waiting in the queue. Its channel is closed
+// when it's admitted.
+type concurrencyWaiter struct {
+	ready chan struct{}
+}
+
+// acquire admits an RPC, waiting in the queue if necessary. If it returns a
+// nil error, the caller must call release when the RPC ends.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	if len(l.queue) >= l.policy.MaxQueue {
+		l.mu.Unlock()
+		return l.rejectError()
+	}
+	waiter := &concurrencyWaiter{ready: make(chan struct{})}
+	l.queue = append(l.queue, waiter)
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if l.policy.MaxQueueWait > 0 {
+		timer := time.NewTimer(l.policy.MaxQueueWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	var err error
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-timeout:
+		err = l.rejectError()
+	case <-ctx.Done():
+		err = wrapIfContextError(ctx.Err())
+	}
+	l.mu.Lock()
+	for i, queued := range l.queue {
+		if queued == waiter {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			l.mu.Unlock()
+			return err
+		}
+	}
+	l.mu.Unlock()
+	// We were admitted while giving up, so pass the slot on.
+	l.release(0)
+	return err
+}
+
+// release ends an RPC admitted by acquire. If latency is positive, it's used
+// to tune an adaptive limit.
+func (l *ConcurrencyLimiter) release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if latency > 0 && l.policy.Adaptive != nil {
+		l.sample(latency)
+	}
+	l.inFlight--
+	for len(l.queue) > 0 && l.inFlight < int(l.limit) {
+		waiter := l.queue[0]
+		l.queue[0] = nil
+		l.queue = l.queue[1:]
+		l.inFlight++
+		close(waiter.ready)
+	}
+}
+
+// sample updates an adaptive limit with the latency of an RPC that's just
+// finished. Callers must hold the lock.
+func (l *ConcurrencyLimiter) sample(latency time.Duration) {
+	adaptive := l.policy.Adaptive
+	rtt := float64(latency)
+	if l.longRTT == 0 {
+		l.shortRTT, l.longRTT = rtt, rtt
+	}
+	l.shortRTT += (rtt - l.shortRTT) * adaptiveShortAlpha
+	l.longRTT += (rtt - l.longRTT) * adaptiveLongAlpha
+	if l.longRTT > 2*l.shortRTT {
+		// Latency has dropped a lot, so let the baseline catch up quickly.
+		l.longRTT *= 0.95
+	}
+	if float64(l.inFlight) < l.limit/2 {
+		// The limit isn't what's holding back throughput, so latency says
+		// nothing about whether it's too high.
+		return
+	}
+	gradient := min(max(adaptive.Tolerance*l.longRTT/l.shortRTT, 0.5), 1)
+	estimate := l.limit*gradient + math.Sqrt(l.limit)
+	limit := l.limit*(1-adaptiveSmoothing) + estimate*adaptiveSmoothing
+	l.limit = min(max(limit, float64(adaptive.MinLimit)), float64(adaptive.MaxLimit))
+}
+
+func (l *ConcurrencyLimiter) rejectError() *Error {
+	err := errorf(l.policy.Code, "concurrency limit exceeded")
+	if pushback := l.policy.RetryPushback; pushback != 0 {
+		value := "-1"
+		if pushback > 0 {
+			value = strconv.FormatInt(pushback.Milliseconds(), 10 /* base */)
+		}
+		err.Meta().Set(grpcHeaderRetryPushback, value)
+	}
+	return err
+}
+
+// acquireConcurrency admits an RPC through each of the limiters in turn. If
+// it returns a nil error, the caller must call the returned function when the
+// RPC ends, passing the latency to sample or zero.
+func acquireConcurrency(ctx context.Context, limiters []*ConcurrencyLimiter) (func(time.Duration), error) {
+	for i, limiter := range limiters {
+		if err := limiter.acquire(ctx); err != nil {
+			for _, acquired := range limiters[:i] {
+				acquired.release(0)
+			}
+			return nil, err
+		}
+	}
+	return func(latency time.Duration) {
+		for _, limiter := range limiters {
+			limiter.release(latency)
+		}
+	}, nil
+}
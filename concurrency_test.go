@@ -0,0 +1,138 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agentio/scalpel/internal/assert"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Parallel()
+	t.Run("reject", func(t *testing.T) {
+		t.Parallel()
+		limiter := NewConcurrencyLimiter(ConcurrencyPolicy{
+			Limit:         1,
+			Code:          CodeUnavailable,
+			RetryPushback: 250 * time.Millisecond,
+		})
+		assert.Nil(t, limiter.acquire(t.Context()))
+		err := limiter.acquire(t.Context())
+		assert.Equal(t, CodeOf(err), CodeUnavailable)
+		pushback, ok := retryPushback(err)
+		assert.True(t, ok)
+		assert.Equal(t, pushback, 250*time.Millisecond)
+		limiter.release(0)
+		assert.Equal(t, limiter.InFlight(), 0)
+		assert.Nil(t, limiter.acquire(t.Context()))
+	})
+	t.Run("queue", func(t *testing.T) {
+		t.Parallel()
+		limiter := NewConcurrencyLimiter(ConcurrencyPolicy{Limit: 1, MaxQueue: 1})
+		assert.Nil(t, limiter.acquire(t.Context()))
+		admitted := make(chan error, 1)
+		go func() {
+			admitted <- limiter.acquire(t.Context())
+		}()
+		for {
+			limiter.mu.Lock()
+			queued := len(limiter.queue)
+			limiter.mu.Unlock()
+			if queued == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		// The queue is full.
+		assert.Equal(t, CodeOf(limiter.acquire(t.Context())), CodeResourceExhausted)
+		limiter.release(0)
+		assert.Nil(t, <-admitted)
+		assert.Equal(t, limiter.InFlight(), 1)
+	})
+	t.Run("queue_wait", func(t *testing.T) {
+		t.Parallel()
+		limiter := NewConcurrencyLimiter(ConcurrencyPolicy{
+			Limit:        1,
+			MaxQueue:     1,
+			MaxQueueWait: 10 * time.Millisecond,
+		})
+		assert.Nil(t, limiter.acquire(t.Context()))
+		assert.Equal(t, CodeOf(limiter.acquire(t.Context())), CodeResourceExhausted)
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		limiter.policy.MaxQueueWait = 0
+		assert.Equal(t, CodeOf(limiter.acquire(ctx)), CodeDeadlineExceeded)
+		limiter.release(0)
+		assert.Equal(t, limiter.InFlight(), 0)
+		assert.Equal(t, len(limiter.queue), 0)
+	})
+	t.Run("multiple", func(t *testing.T) {
+		t.Parallel()
+		group := NewConcurrencyLimiter(ConcurrencyPolicy{Limit: 1})
+		procedure := NewConcurrencyLimiter(ConcurrencyPolicy{Limit: 2})
+		release, err := acquireConcurrency(t.Context(), []*ConcurrencyLimiter{procedure, group})
+		assert.Nil(t, err)
+		_, err = acquireConcurrency(t.Context(), []*ConcurrencyLimiter{procedure, group})
+		assert.NotNil(t, err)
+		// The failed call must not hold on to the procedure's slot.
+		assert.Equal(t, procedure.InFlight(), 1)
+		release(0)
+		assert.Equal(t, procedure.InFlight(), 0)
+		assert.Equal(t, group.InFlight(), 0)
+	})
+}
+
+func TestAdaptiveConcurrencyLimiter(t *testing.T) {
+	t.Parallel()
+	limiter := NewConcurrencyLimiter(ConcurrencyPolicy{
+		Limit:    10,
+		Adaptive: &AdaptiveConcurrency{MinLimit: 2, MaxLimit: 50},
+	})
+	saturate := func(latency time.Duration, rounds int) {
+		for range rounds {
+			limit := limiter.Limit()
+			for range limit {
+				assert.Nil(t, limiter.acquire(t.Context()))
+			}
+			for range limit {
+				limiter.release(latency)
+			}
+		}
+	}
+	// With steady latency, the limit grows until it reaches the maximum.
+	saturate(10*time.Millisecond, 20)
+	assert.Equal(t, limiter.Limit(), 50)
+	// As latency rises, the limit shrinks.
+	saturate(100*time.Millisecond, 5)
+	assert.True(t, limiter.Limit() < 50)
+	// Under sustained overload, the limit settles a little above the square
+	// root headroom it always allows.
+	saturate(time.Second, 20)
+	assert.True(t, limiter.Limit() <= 5)
+
+	// An idle limiter doesn't grow.
+	idle := NewConcurrencyLimiter(ConcurrencyPolicy{
+		Limit:    10,
+		Adaptive: &AdaptiveConcurrency{},
+	})
+	for range 100 {
+		assert.Nil(t, idle.acquire(t.Context()))
+		idle.release(time.Millisecond)
+	}
+	assert.Equal(t, idle.Limit(), 10)
+}
@@ -13,7 +13,8 @@
 // limitations under the License.
 
 // Package connect is a slim RPC framework built on Protocol Buffers and
-// [net/http].
+// [net/http]. In addition to supporting its own protocol, Connect handlers and
+// clients are wire-compatible with gRPC and gRPC-Web, including streaming.
 //
 // This documentation is intended to explain each type and function in
 // isolation. Walkthroughs, FAQs, and other narrative docs are available on the
@@ -68,8 +69,51 @@ func (s StreamType) String() string {
 	return fmt.Sprintf("stream_%d", s)
 }
 
+// An IdempotencyLevel declares whether an RPC is safe to call more than once.
+// Clients and handlers use it to decide, for example, whether a call may be
+// hedged or sent as an HTTP GET.
+//
+// The levels match the idempotency_level option on Protobuf methods:
+//
+//	rpc Ping(PingRequest) returns (PingResponse) {
+//	  option idempotency_level = NO_SIDE_EFFECTS;
+//	}
+type IdempotencyLevel int
+
+const (
+	// IdempotencyUnknown is the default level. The procedure may have side
+	// effects, so it isn't safe to call more than once.
+	IdempotencyUnknown IdempotencyLevel = 0
+	// IdempotencyNoSideEffects marks procedures that don't change any state,
+	// like the "safe" HTTP methods in RFC 9110. They're idempotent, and the
+	// Connect protocol may call them with HTTP GET.
+	IdempotencyNoSideEffects IdempotencyLevel = 1
+	// IdempotencyIdempotent marks procedures that may have side effects, but
+	// have the same effect if they're called more than once with the same
+	// request.
+	IdempotencyIdempotent IdempotencyLevel = 2
+)
+
+func (i IdempotencyLevel) String() string {
+	switch i {
+	case IdempotencyUnknown:
+		return "idempotency_unknown"
+	case IdempotencyNoSideEffects:
+		return "no_side_effects"
+	case IdempotencyIdempotent:
+		return "idempotent"
+	}
+	return fmt.Sprintf("idempotency_%d", i)
+}
+
+// IsIdempotent reports whether the level allows calling a procedure more than
+// once: either IdempotencyNoSideEffects or IdempotencyIdempotent.
+func (i IdempotencyLevel) IsIdempotent() bool {
+	return i == IdempotencyNoSideEffects || i == IdempotencyIdempotent
+}
+
 // StreamingHandlerConn is the server's view of a bidirectional message
-// exchange
+// exchange. Interceptors for streaming RPCs may wrap StreamingHandlerConns.
 //
 // Like the standard library's [http.ResponseWriter], StreamingHandlerConns write
 // response headers to the network with the first call to Send. Any subsequent
@@ -121,6 +165,8 @@ type StreamingHandlerConn interface {
 // In order to support bidirectional streaming RPCs, all StreamingClientConn
 // implementations must support limited concurrent use. See the comments on
 // each group of methods for details.
+//
+// Interceptors for streaming RPCs may wrap StreamingClientConns.
 type StreamingClientConn interface {
 	// Spec and Peer must be safe to call concurrently with all other methods.
 	Spec() Spec
@@ -311,11 +357,16 @@ type HTTPClient interface {
 //
 // If you're using Protobuf, protoc-gen-connect-go generates a constant for the
 // fully-qualified Procedure corresponding to each RPC in your schema.
+//
+// The IdempotencyLevel is set with [WithIdempotency]. If it isn't set, it's
+// taken from the idempotency_level option of a Protobuf schema passed to
+// [WithSchema].
 type Spec struct {
-	StreamType StreamType
-	Schema     any    // for protobuf RPCs, a protoreflect.MethodDescriptor
-	Procedure  string // for example, "/acme.foo.v1.FooService/Bar"
-	IsClient   bool   // otherwise we're in a handler
+	StreamType       StreamType
+	Schema           any    // for protobuf RPCs, a protoreflect.MethodDescriptor
+	Procedure        string // for example, "/acme.foo.v1.FooService/Bar"
+	IsClient         bool   // otherwise we're in a handler
+	IdempotencyLevel IdempotencyLevel
 }
 
 // Peer describes the other party to an RPC.
@@ -325,7 +376,8 @@ type Spec struct {
 // in IP:port format.
 //
 // On both the client and the server, Protocol is the RPC protocol in use.
-// Currently, it's [ProtocolGRPC].
+// Currently, it's either [ProtocolConnect], [ProtocolGRPC], or
+// [ProtocolGRPCWeb].
 //
 // Query contains the query parameters for the request. For the server, this
 // will reflect the actual query parameters sent. For the client, it is unset.
@@ -435,3 +487,44 @@ func receiveUnaryMessage[T any](conn receiveConn, initializer maybeInitializer,
 	}
 	return &msg, nil
 }
+
+// errStreamingClientConn is a sentinel error implementation of StreamingClientConn.
+type errStreamingClientConn struct {
+	err error
+}
+
+func (c *errStreamingClientConn) Receive(msg any) error {
+	return c.err
+}
+
+func (c *errStreamingClientConn) Spec() Spec {
+	return Spec{}
+}
+
+func (c *errStreamingClientConn) Peer() Peer {
+	return Peer{}
+}
+
+func (c *errStreamingClientConn) Send(msg any) error {
+	return c.err
+}
+
+func (c *errStreamingClientConn) CloseRequest() error {
+	return c.err
+}
+
+func (c *errStreamingClientConn) CloseResponse() error {
+	return c.err
+}
+
+func (c *errStreamingClientConn) RequestHeader() http.Header {
+	return make(http.Header)
+}
+
+func (c *errStreamingClientConn) ResponseHeader() http.Header {
+	return make(http.Header)
+}
+
+func (c *errStreamingClientConn) ResponseTrailer() http.Header {
+	return make(http.Header)
+}
@@ -16,16 +16,21 @@ package scalpel_test
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
 	rand "math/rand/v2"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -651,10 +656,62 @@ func TestServer(t *testing.T) {
 			testCumSum(t, client, bidi)
 			testErrors(t, client)
 		}
+		t.Run("connect", func(t *testing.T) {
+			t.Run("proto", func(t *testing.T) {
+				run(t, connect.WithConnect())
+			})
+			t.Run("proto_gzip", func(t *testing.T) {
+				run(t, connect.WithConnect(), connect.WithSendGzip())
+			})
+			t.Run("json_gzip", func(t *testing.T) {
+				run(
+					t,
+					connect.WithConnect(),
+					connect.WithProtoJSON(),
+					connect.WithSendGzip(),
+				)
+			})
+			t.Run("json_get", func(t *testing.T) {
+				run(
+					t,
+					connect.WithConnect(),
+					connect.WithProtoJSON(),
+					connect.WithHTTPGet(),
+					connect.WithHTTPGetMaxURLSize(1024, true),
+				)
+			})
+		})
 		t.Run("grpc", func(t *testing.T) {
 			t.Run("proto", func(t *testing.T) {
 				run(t, connect.WithGRPC())
 			})
+			t.Run("proto_gzip", func(t *testing.T) {
+				run(t, connect.WithGRPC(), connect.WithSendGzip())
+			})
+			t.Run("json_gzip", func(t *testing.T) {
+				run(
+					t,
+					connect.WithGRPC(),
+					connect.WithProtoJSON(),
+					connect.WithSendGzip(),
+				)
+			})
+		})
+		t.Run("grpcweb", func(t *testing.T) {
+			t.Run("proto", func(t *testing.T) {
+				run(t, connect.WithGRPCWeb())
+			})
+			t.Run("proto_gzip", func(t *testing.T) {
+				run(t, connect.WithGRPCWeb(), connect.WithSendGzip())
+			})
+			t.Run("json_gzip", func(t *testing.T) {
+				run(
+					t,
+					connect.WithGRPCWeb(),
+					connect.WithProtoJSON(),
+					connect.WithSendGzip(),
+				)
+			})
 		})
 	}
 
@@ -1114,7 +1171,7 @@ func TestHandlerWithReadMaxBytes(t *testing.T) {
 			return options
 		}),
 	))
-	readMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient) {
+	readMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient, compressed bool) {
 		t.Helper()
 		t.Run("equal_read_max", func(t *testing.T) {
 			t.Parallel()
@@ -1129,6 +1186,10 @@ func TestHandlerWithReadMaxBytes(t *testing.T) {
 			// Serializes to readMaxBytes+1 (1025) - expect invalid argument.
 			// This will be over the limit after decompression but under with compression.
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("a", 1022)}
+			if compressed {
+				compressedSize := gzipCompressedSize(t, pingRequest)
+				assert.True(t, compressedSize < readMaxBytes, assert.Sprintf("expected compressed size %d < %d", compressedSize, readMaxBytes))
+			}
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
 			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
 			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
@@ -1143,6 +1204,10 @@ func TestHandlerWithReadMaxBytes(t *testing.T) {
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("abcde", 1024*1024)}
 			expectedSize := proto.Size(pingRequest)
 			// With gzip request compression, the error should indicate the envelope size (before decompression) is too large.
+			if compressed {
+				expectedSize = gzipCompressedSize(t, pingRequest)
+				assert.True(t, expectedSize > readMaxBytes, assert.Sprintf("expected compressed size %d > %d", expectedSize, readMaxBytes))
+			}
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
 			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
 			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
@@ -1158,7 +1223,13 @@ func TestHandlerWithReadMaxBytes(t *testing.T) {
 		t.Parallel()
 		server := newHTTP2Server(t)
 		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC())
-		readMaxBytesMatrix(t, client)
+		readMaxBytesMatrix(t, client, false)
+	})
+	t.Run("grpc_gzip", func(t *testing.T) {
+		t.Parallel()
+		server := newHTTP2Server(t)
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC(), connect.WithSendGzip())
+		readMaxBytesMatrix(t, client, true)
 	})
 }
 
@@ -1170,7 +1241,7 @@ func TestHandlerWithHTTPMaxBytes(t *testing.T) {
 	mux := http.NewServeMux()
 	pingRoute, pingHandler := pingv1connect.NewPingServiceHandler(pingServer{})
 	mux.Handle(pingRoute, http.MaxBytesHandler(pingHandler, readMaxBytes))
-	run := func(t *testing.T, client pingv1connect.PingServiceClient) {
+	run := func(t *testing.T, client pingv1connect.PingServiceClient, compressed bool) {
 		t.Helper()
 		t.Run("below_read_max", func(t *testing.T) {
 			t.Parallel()
@@ -1181,6 +1252,12 @@ func TestHandlerWithHTTPMaxBytes(t *testing.T) {
 			t.Parallel()
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("a", readMaxBytes*10)}
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
+			if compressed {
+				compressedSize := gzipCompressedSize(t, pingRequest)
+				assert.True(t, compressedSize < readMaxBytes, assert.Sprintf("expected compressed size %d < %d", compressedSize, readMaxBytes))
+				assert.Nil(t, err)
+				return
+			}
 			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
 			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
 		})
@@ -1190,6 +1267,10 @@ func TestHandlerWithHTTPMaxBytes(t *testing.T) {
 				t.Skipf("skipping %s test in short mode", t.Name())
 			}
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("abcde", 1024*1024)}
+			if compressed {
+				expectedSize := gzipCompressedSize(t, pingRequest)
+				assert.True(t, expectedSize > readMaxBytes, assert.Sprintf("expected compressed size %d > %d", expectedSize, readMaxBytes))
+			}
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
 			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
 			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
@@ -1199,22 +1280,35 @@ func TestHandlerWithHTTPMaxBytes(t *testing.T) {
 		t.Parallel()
 		server := memhttptest.NewServer(t, mux)
 		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC())
-		run(t, client)
+		run(t, client, false)
+	})
+	t.Run("grpc_gzip", func(t *testing.T) {
+		t.Parallel()
+		server := memhttptest.NewServer(t, mux)
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC(), connect.WithSendGzip())
+		run(t, client, true)
 	})
 }
 
 func TestClientWithReadMaxBytes(t *testing.T) {
 	t.Parallel()
-	createServer := func(tb testing.TB, _ bool) *memhttp.Server {
+	createServer := func(tb testing.TB, enableCompression bool) *memhttp.Server {
 		tb.Helper()
 		mux := http.NewServeMux()
-		mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}))
+		var compressionOption connect.HandlerOption
+		if enableCompression {
+			compressionOption = connect.WithCompressMinBytes(1)
+		} else {
+			compressionOption = connect.WithCompressMinBytes(math.MaxInt)
+		}
+		mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}, compressionOption))
 		server := memhttptest.NewServer(t, mux)
 		return server
 	}
 	serverUncompressed := createServer(t, false)
+	serverCompressed := createServer(t, true)
 	readMaxBytes := 1024
-	readMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient) {
+	readMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient, compressed bool) {
 		t.Helper()
 		t.Run("equal_read_max", func(t *testing.T) {
 			t.Parallel()
@@ -1242,6 +1336,11 @@ func TestClientWithReadMaxBytes(t *testing.T) {
 			// Serializes to much larger than readMaxBytes (5 MiB)
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("abcde", 1024*1024)}
 			expectedSize := proto.Size(pingRequest)
+			// With gzip response compression, the error should indicate the envelope size (before decompression) is too large.
+			if compressed {
+				expectedSize = gzipCompressedSize(t, pingRequest)
+				assert.True(t, expectedSize > readMaxBytes, assert.Sprintf("expected compressed size %d > %d", expectedSize, readMaxBytes))
+			}
 			assert.True(t, expectedSize > readMaxBytes, assert.Sprintf("expected compressed size %d > %d", expectedSize, readMaxBytes))
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
 			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
@@ -1252,14 +1351,19 @@ func TestClientWithReadMaxBytes(t *testing.T) {
 	t.Run("grpc", func(t *testing.T) {
 		t.Parallel()
 		client := pingv1connect.NewPingServiceClient(serverUncompressed.Client(), serverUncompressed.URL(), connect.WithReadMaxBytes(readMaxBytes), connect.WithGRPC())
-		readMaxBytesMatrix(t, client)
+		readMaxBytesMatrix(t, client, false)
+	})
+	t.Run("grpc_gzip", func(t *testing.T) {
+		t.Parallel()
+		client := pingv1connect.NewPingServiceClient(serverCompressed.Client(), serverCompressed.URL(), connect.WithReadMaxBytes(readMaxBytes), connect.WithGRPC())
+		readMaxBytesMatrix(t, client, true)
 	})
 }
 
 func TestHandlerWithSendMaxBytes(t *testing.T) {
 	t.Parallel()
 	sendMaxBytes := 1024
-	sendMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient) {
+	sendMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient, compressed bool) {
 		t.Helper()
 		t.Run("equal_send_max", func(t *testing.T) {
 			t.Parallel()
@@ -1274,10 +1378,18 @@ func TestHandlerWithSendMaxBytes(t *testing.T) {
 			// Serializes to sendMaxBytes+1 (1025) - expect invalid argument.
 			// This will be over the limit after decompression but under with compression.
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("a", 1022)}
+			if compressed {
+				compressedSize := gzipCompressedSize(t, pingRequest)
+				assert.True(t, compressedSize < sendMaxBytes, assert.Sprintf("expected compressed size %d < %d", compressedSize, sendMaxBytes))
+			}
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
-			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
-			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
-			assert.True(t, strings.HasSuffix(err.Error(), fmt.Sprintf("message size %d exceeds sendMaxBytes %d", proto.Size(pingRequest), sendMaxBytes)))
+			if compressed {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
+				assert.True(t, strings.HasSuffix(err.Error(), fmt.Sprintf("message size %d exceeds sendMaxBytes %d", proto.Size(pingRequest), sendMaxBytes)))
+			}
 		})
 		t.Run("send_max_large", func(t *testing.T) {
 			t.Parallel()
@@ -1287,16 +1399,30 @@ func TestHandlerWithSendMaxBytes(t *testing.T) {
 			// Serializes to much larger than sendMaxBytes (5 MiB)
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("abcde", 1024*1024)}
 			expectedSize := proto.Size(pingRequest)
+			// With gzip request compression, the error should indicate the envelope size (before decompression) is too large.
+			if compressed {
+				expectedSize = gzipCompressedSize(t, pingRequest)
+				assert.True(t, expectedSize > sendMaxBytes, assert.Sprintf("expected compressed size %d > %d", expectedSize, sendMaxBytes))
+			}
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
 			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
 			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
-			assert.Equal(t, err.Error(), fmt.Sprintf("resource_exhausted: message size %d exceeds sendMaxBytes %d", expectedSize, sendMaxBytes))
+			if compressed {
+				assert.Equal(t, err.Error(), fmt.Sprintf("resource_exhausted: compressed message size %d exceeds sendMaxBytes %d", expectedSize, sendMaxBytes))
+			} else {
+				assert.Equal(t, err.Error(), fmt.Sprintf("resource_exhausted: message size %d exceeds sendMaxBytes %d", expectedSize, sendMaxBytes))
+			}
 		})
 	}
-	newHTTP2Server := func(t *testing.T, sendMaxBytes int) *memhttp.Server {
+	newHTTP2Server := func(t *testing.T, compressed bool, sendMaxBytes int) *memhttp.Server {
 		t.Helper()
 		mux := http.NewServeMux()
 		options := []connect.HandlerOption{connect.WithSendMaxBytes(sendMaxBytes)}
+		if compressed {
+			options = append(options, connect.WithCompressMinBytes(1))
+		} else {
+			options = append(options, connect.WithCompressMinBytes(math.MaxInt))
+		}
 		mux.Handle(pingv1connect.NewPingServiceHandler(
 			pingServer{},
 			options...,
@@ -1306,9 +1432,15 @@ func TestHandlerWithSendMaxBytes(t *testing.T) {
 	}
 	t.Run("grpc", func(t *testing.T) {
 		t.Parallel()
-		server := newHTTP2Server(t, sendMaxBytes)
+		server := newHTTP2Server(t, false, sendMaxBytes)
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC())
+		sendMaxBytesMatrix(t, client, false)
+	})
+	t.Run("grpc_gzip", func(t *testing.T) {
+		t.Parallel()
+		server := newHTTP2Server(t, true, sendMaxBytes)
 		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC())
-		sendMaxBytesMatrix(t, client)
+		sendMaxBytesMatrix(t, client, true)
 	})
 }
 
@@ -1317,7 +1449,7 @@ func TestClientWithSendMaxBytes(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}))
 	server := memhttptest.NewServer(t, mux)
-	sendMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient, sendMaxBytes int) {
+	sendMaxBytesMatrix := func(t *testing.T, client pingv1connect.PingServiceClient, sendMaxBytes int, compressed bool) {
 		t.Helper()
 		t.Run("equal_send_max", func(t *testing.T) {
 			t.Parallel()
@@ -1333,9 +1465,14 @@ func TestClientWithSendMaxBytes(t *testing.T) {
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("a", 1022)}
 			assert.Equal(t, proto.Size(pingRequest), sendMaxBytes+1)
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
-			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
-			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
-			assert.True(t, strings.HasSuffix(err.Error(), fmt.Sprintf("message size %d exceeds sendMaxBytes %d", proto.Size(pingRequest), sendMaxBytes)))
+			if compressed {
+				assert.True(t, gzipCompressedSize(t, pingRequest) < sendMaxBytes)
+				assert.Nil(t, err, assert.Sprintf("expected nil error for compressed message < sendMaxBytes"))
+			} else {
+				assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
+				assert.True(t, strings.HasSuffix(err.Error(), fmt.Sprintf("message size %d exceeds sendMaxBytes %d", proto.Size(pingRequest), sendMaxBytes)))
+			}
 		})
 		t.Run("send_max_large", func(t *testing.T) {
 			t.Parallel()
@@ -1345,21 +1482,222 @@ func TestClientWithSendMaxBytes(t *testing.T) {
 			// Serializes to much larger than sendMaxBytes (5 MiB)
 			pingRequest := &pingv1.PingRequest{Text: strings.Repeat("abcde", 1024*1024)}
 			expectedSize := proto.Size(pingRequest)
+			// With gzip response compression, the error should indicate the envelope size (before decompression) is too large.
+			if compressed {
+				expectedSize = gzipCompressedSize(t, pingRequest)
+			}
 			assert.True(t, expectedSize > sendMaxBytes)
 			_, err := client.Ping(t.Context(), connect.NewRequest(pingRequest))
 			assert.NotNil(t, err, assert.Sprintf("expected non-nil error for large message"))
 			assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
-			assert.Equal(t, err.Error(), fmt.Sprintf("resource_exhausted: message size %d exceeds sendMaxBytes %d", expectedSize, sendMaxBytes))
+			if compressed {
+				assert.Equal(t, err.Error(), fmt.Sprintf("resource_exhausted: compressed message size %d exceeds sendMaxBytes %d", expectedSize, sendMaxBytes))
+			} else {
+				assert.Equal(t, err.Error(), fmt.Sprintf("resource_exhausted: message size %d exceeds sendMaxBytes %d", expectedSize, sendMaxBytes))
+			}
 		})
 	}
 	t.Run("grpc", func(t *testing.T) {
 		t.Parallel()
 		sendMaxBytes := 1024
 		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithSendMaxBytes(sendMaxBytes), connect.WithGRPC())
-		sendMaxBytesMatrix(t, client, sendMaxBytes)
+		sendMaxBytesMatrix(t, client, sendMaxBytes, false)
+	})
+	t.Run("grpc_gzip", func(t *testing.T) {
+		t.Parallel()
+		sendMaxBytes := 1024
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithSendMaxBytes(sendMaxBytes), connect.WithGRPC(), connect.WithSendGzip())
+		sendMaxBytesMatrix(t, client, sendMaxBytes, true)
+	})
+}
+
+func TestCompressMinBytesClient(t *testing.T) {
+	t.Parallel()
+	assertRequestFlags := func(tb testing.TB, text string, expectCompressed bool) {
+		tb.Helper()
+		mux := http.NewServeMux()
+		pingRoute, pingHandler := pingv1connect.NewPingServiceHandler(pingServer{})
+		mux.Handle(pingRoute, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			body, err := io.ReadAll(request.Body)
+			assert.Nil(tb, err)
+			assert.True(tb, len(body) >= 5)
+			assert.Equal(tb, body[0]&1 == 1, expectCompressed)
+			request.Body = io.NopCloser(bytes.NewReader(body))
+			pingHandler.ServeHTTP(writer, request)
+		}))
+		server := memhttptest.NewServer(tb, mux)
+		_, err := pingv1connect.NewPingServiceClient(
+			server.Client(),
+			server.URL(),
+			connect.WithSendGzip(),
+			connect.WithCompressMinBytes(8),
+		).Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Text: text}))
+		assert.Nil(tb, err)
+	}
+	t.Run("request_uncompressed", func(t *testing.T) {
+		t.Parallel()
+		assertRequestFlags(t, "ping", false)
+	})
+	t.Run("request_compressed", func(t *testing.T) {
+		t.Parallel()
+		assertRequestFlags(t, strings.Repeat("ping", 2), true)
+	})
+}
+
+func TestCompressMinBytes(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(
+		pingServer{},
+		connect.WithCompressMinBytes(8),
+	))
+	server := memhttptest.NewServer(t, mux)
+
+	getPingResponseBody := func(t *testing.T, pingText string) (http.Header, []byte) {
+		t.Helper()
+		protoBytes, err := proto.Marshal(&pingv1.PingRequest{Text: pingText})
+		assert.Nil(t, err)
+		prefix := [5]byte{}
+		binary.BigEndian.PutUint32(prefix[1:5], uint32(len(protoBytes)))
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodPost,
+			server.URL()+pingv1connect.PingServicePingProcedure,
+			bytes.NewReader(append(prefix[:], protoBytes...)),
+		)
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("Grpc-Accept-Encoding", "gzip")
+		res, err := server.Client().Do(req)
+		assert.Nil(t, err)
+		body, err := io.ReadAll(res.Body)
+		assert.Nil(t, err)
+		assert.Nil(t, res.Body.Close())
+		assert.True(t, len(body) >= 5)
+		return res.Header, body
+	}
+
+	t.Run("response_uncompressed", func(t *testing.T) {
+		t.Parallel()
+		header, body := getPingResponseBody(t, "ping")
+		assert.Equal(t, header.Get("Grpc-Encoding"), "gzip")
+		assert.Equal(t, body[0], 0)
+	})
+	t.Run("response_compressed", func(t *testing.T) {
+		t.Parallel()
+		header, body := getPingResponseBody(t, strings.Repeat("ping", 2))
+		assert.Equal(t, header.Get("Grpc-Encoding"), "gzip")
+		assert.Equal(t, body[0], 1)
 	})
 }
 
+func TestUnknownRequestCompression(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}))
+	server := memhttptest.NewServer(t, mux)
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		server.URL()+pingv1connect.PingServicePingProcedure,
+		bytes.NewReader([]byte{1, 0, 0, 0, 0}),
+	)
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Grpc-Encoding", "br")
+	res, err := server.Client().Do(req)
+	assert.Nil(t, err)
+	_, err = io.Copy(io.Discard, res.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, res.Body.Close())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, res.Header.Get("Grpc-Accept-Encoding"), "gzip")
+	status := res.Trailer.Get("Grpc-Status")
+	if status == "" {
+		status = res.Header.Get("Grpc-Status")
+	}
+	assert.Equal(t, status, strconv.Itoa(int(connect.CodeUnimplemented)))
+}
+
+func TestCustomCompression(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	compressionName := "deflate"
+	decompressor := func() connect.Decompressor {
+		// Need to instantiate with a reader - before decompressing Reset(io.Reader) is called
+		return newDeflateReader(strings.NewReader(""))
+	}
+	compressor := func() connect.Compressor {
+		w, err := flate.NewWriter(&strings.Builder{}, flate.DefaultCompression)
+		if err != nil {
+			t.Fatalf("failed to create flate writer: %v", err)
+		}
+		return w
+	}
+	mux.Handle(pingv1connect.NewPingServiceHandler(
+		pingServer{},
+		connect.WithCompression(compressionName, decompressor, compressor),
+	))
+	server := memhttptest.NewServer(t, mux)
+	client := pingv1connect.NewPingServiceClient(server.Client(),
+		server.URL(),
+		connect.WithAcceptCompression(compressionName, decompressor, compressor),
+		connect.WithSendCompression(compressionName),
+	)
+	request := &pingv1.PingRequest{Text: "testing 1..2..3.."}
+	response, err := client.Ping(t.Context(), connect.NewRequest(request))
+	assert.Nil(t, err)
+	assert.Equal(t, response.Msg, &pingv1.PingResponse{Text: request.GetText()})
+}
+
+func TestClientWithoutGzipSupport(t *testing.T) {
+	// Some sidecars can't handle asymmetric compression, so clients must be
+	// able to opt out of gzip entirely.
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}))
+	server := memhttptest.NewServer(t, mux)
+	client := pingv1connect.NewPingServiceClient(server.Client(),
+		server.URL(),
+		connect.WithAcceptCompression("gzip", nil, nil),
+		connect.WithSendGzip(),
+	)
+	request := &pingv1.PingRequest{Text: "gzip me!"}
+	_, err := client.Ping(t.Context(), connect.NewRequest(request))
+	assert.NotNil(t, err)
+	assert.Equal(t, connect.CodeOf(err), connect.CodeUnknown)
+	assert.True(t, strings.Contains(err.Error(), "unknown compression"))
+}
+
+func TestFailCompression(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	compressorName := "fail"
+	compressor := func() connect.Compressor { return failCompressor{} }
+	decompressor := func() connect.Decompressor { return failDecompressor{} }
+	mux.Handle(
+		pingv1connect.NewPingServiceHandler(
+			pingServer{},
+			connect.WithCompression(compressorName, decompressor, compressor),
+		),
+	)
+	server := memhttptest.NewServer(t, mux)
+	pingclient := pingv1connect.NewPingServiceClient(
+		server.Client(),
+		server.URL(),
+		connect.WithAcceptCompression(compressorName, decompressor, compressor),
+		connect.WithSendCompression(compressorName),
+	)
+	_, err := pingclient.Ping(
+		t.Context(),
+		connect.NewRequest(&pingv1.PingRequest{
+			Text: "ping",
+		}),
+	)
+	assert.NotNil(t, err)
+	assert.Equal(t, connect.CodeOf(err), connect.CodeInternal)
+}
+
 func TestBidiStreamServerSendsFirstMessage(t *testing.T) {
 	t.Parallel()
 	run := func(t *testing.T, opts ...connect.ClientOption) {
@@ -1770,6 +2108,224 @@ func TestSetProtocolHeaders(t *testing.T) {
 	}
 }
 
+func TestConnectHTTPErrorCodes(t *testing.T) {
+	t.Parallel()
+	checkHTTPStatus := func(t *testing.T, connectCode connect.Code, wantHttpStatus int) {
+		t.Helper()
+		mux := http.NewServeMux()
+		pluggableServer := &pluggablePingServer{
+			ping: func(_ context.Context, _ *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+				return nil, connect.NewError(connectCode, errors.New("error"))
+			},
+		}
+		mux.Handle(pingv1connect.NewPingServiceHandler(pluggableServer))
+		server := memhttptest.NewServer(t, mux)
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodPost,
+			server.URL()+pingv1connect.PingServicePingProcedure,
+			strings.NewReader("{}"),
+		)
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.Client().Do(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, wantHttpStatus, resp.StatusCode)
+		connectClient := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithConnect())
+		connectResp, err := connectClient.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assert.NotNil(t, err)
+		assert.Nil(t, connectResp)
+	}
+	t.Run("CodeCanceled-499", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeCanceled, 499)
+	})
+	t.Run("CodeUnknown-500", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeUnknown, 500)
+	})
+	t.Run("CodeInvalidArgument-400", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeInvalidArgument, 400)
+	})
+	t.Run("CodeDeadlineExceeded-504", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeDeadlineExceeded, 504)
+	})
+	t.Run("CodeNotFound-404", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeNotFound, 404)
+	})
+	t.Run("CodeAlreadyExists-409", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeAlreadyExists, 409)
+	})
+	t.Run("CodePermissionDenied-403", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodePermissionDenied, 403)
+	})
+	t.Run("CodeResourceExhausted-429", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeResourceExhausted, 429)
+	})
+	t.Run("CodeFailedPrecondition-400", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeFailedPrecondition, 400)
+	})
+	t.Run("CodeAborted-409", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeAborted, 409)
+	})
+	t.Run("CodeOutOfRange-400", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeOutOfRange, 400)
+	})
+	t.Run("CodeUnimplemented-501", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeUnimplemented, 501)
+	})
+	t.Run("CodeInternal-500", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeInternal, 500)
+	})
+	t.Run("CodeUnavailable-503", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeUnavailable, 503)
+	})
+	t.Run("CodeDataLoss-500", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeDataLoss, 500)
+	})
+	t.Run("CodeUnauthenticated-401", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, connect.CodeUnauthenticated, 401)
+	})
+	t.Run("100-500", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, 100, 500)
+	})
+	t.Run("0-500", func(t *testing.T) {
+		t.Parallel()
+		checkHTTPStatus(t, 0, 500)
+	})
+}
+
+func TestConnectProtocolHeaderRequired(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(
+		pingServer{},
+		connect.WithRequireConnectProtocolHeader(),
+	))
+	server := memhttptest.NewServer(t, mux)
+
+	tests := []struct {
+		headers http.Header
+	}{
+		{http.Header{}},
+		{http.Header{"Connect-Protocol-Version": []string{"0"}}},
+	}
+	for _, tcase := range tests {
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodPost,
+			server.URL()+pingv1connect.PingServicePingProcedure,
+			strings.NewReader("{}"),
+		)
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		maps.Copy(req.Header, tcase.headers)
+		response, err := server.Client().Do(req)
+		assert.Nil(t, err)
+		assert.Nil(t, response.Body.Close())
+		assert.Equal(t, response.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestConnectProtocolHeaderSentByDefault(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}, connect.WithRequireConnectProtocolHeader()))
+	server := memhttptest.NewServer(t, mux)
+
+	client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithConnect())
+	_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+	assert.Nil(t, err)
+
+	stream := client.CumSum(t.Context())
+	assert.Nil(t, stream.Send(&pingv1.CumSumRequest{}))
+	_, err = stream.Receive()
+	assert.Nil(t, err)
+	assert.Nil(t, stream.CloseRequest())
+	assert.Nil(t, stream.CloseResponse())
+}
+
+func TestSpecIdempotencyLevel(t *testing.T) {
+	t.Parallel()
+	methods := pingv1.File_connect_ping_v1_ping_proto.Services().ByName("PingService").Methods()
+	testCases := []struct {
+		name    string
+		options []connect.Option
+		want    connect.IdempotencyLevel
+	}{
+		{name: "default", want: connect.IdempotencyUnknown},
+		{
+			name:    "schema",
+			options: []connect.Option{connect.WithSchema(methods.ByName("Ping"))},
+			want:    connect.IdempotencyNoSideEffects,
+		},
+		{
+			name:    "schema_without_level",
+			options: []connect.Option{connect.WithSchema(methods.ByName("Fail"))},
+			want:    connect.IdempotencyUnknown,
+		},
+		{
+			name:    "explicit",
+			options: []connect.Option{connect.WithIdempotency(connect.IdempotencyIdempotent)},
+			want:    connect.IdempotencyIdempotent,
+		},
+		{
+			// The explicit level wins, regardless of the order of the options.
+			name: "explicit_overrides_schema",
+			options: []connect.Option{
+				connect.WithIdempotency(connect.IdempotencyIdempotent),
+				connect.WithSchema(methods.ByName("Ping")),
+			},
+			want: connect.IdempotencyIdempotent,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			var handlerLevel connect.IdempotencyLevel
+			mux := http.NewServeMux()
+			mux.Handle(pingv1connect.PingServicePingProcedure, connect.NewUnaryHandler(
+				pingv1connect.PingServicePingProcedure,
+				func(_ context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+					handlerLevel = request.Spec().IdempotencyLevel
+					return connect.NewResponse(&pingv1.PingResponse{}), nil
+				},
+				connect.WithOptions(testCase.options...),
+			))
+			server := memhttptest.NewServer(t, mux)
+			client := connect.NewClient[pingv1.PingRequest, pingv1.PingResponse](
+				server.Client(),
+				server.URL()+pingv1connect.PingServicePingProcedure,
+				connect.WithOptions(testCase.options...),
+			)
+			request := connect.NewRequest(&pingv1.PingRequest{})
+			_, err := client.CallUnary(t.Context(), request)
+			assert.Nil(t, err)
+			assert.Equal(t, request.Spec().IdempotencyLevel, testCase.want)
+			assert.Equal(t, handlerLevel, testCase.want)
+		})
+	}
+	assert.Equal(t, connect.IdempotencyNoSideEffects.String(), "no_side_effects")
+	assert.True(t, connect.IdempotencyIdempotent.IsIdempotent())
+	assert.False(t, connect.IdempotencyUnknown.IsIdempotent())
+}
+
 type unflushableWriter struct {
 	w http.ResponseWriter
 }
@@ -1778,6 +2334,57 @@ func (w *unflushableWriter) Header() http.Header         { return w.w.Header() }
 func (w *unflushableWriter) Write(b []byte) (int, error) { return w.w.Write(b) }
 func (w *unflushableWriter) WriteHeader(code int)        { w.w.WriteHeader(code) }
 
+func gzipCompressedSize(tb testing.TB, message proto.Message) int {
+	tb.Helper()
+	uncompressed, err := proto.Marshal(message)
+	assert.Nil(tb, err)
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	_, err = gzipWriter.Write(uncompressed)
+	assert.Nil(tb, err)
+	assert.Nil(tb, gzipWriter.Close())
+	return buf.Len()
+}
+
+type deflateReader struct {
+	r io.ReadCloser
+}
+
+func newDeflateReader(r io.Reader) *deflateReader {
+	return &deflateReader{r: flate.NewReader(r)}
+}
+
+func (d *deflateReader) Read(p []byte) (int, error) {
+	return d.r.Read(p)
+}
+
+func (d *deflateReader) Close() error {
+	return d.r.Close()
+}
+
+func (d *deflateReader) Reset(reader io.Reader) error {
+	if resetter, ok := d.r.(flate.Resetter); ok {
+		return resetter.Reset(reader, nil)
+	}
+	return errors.New("flate reader should implement flate.Resetter")
+}
+
+type failDecompressor struct {
+	connect.Decompressor
+}
+
+type failCompressor struct{}
+
+func (failCompressor) Write([]byte) (int, error) {
+	return 0, errors.New("failCompressor")
+}
+
+func (failCompressor) Close() error {
+	return errors.New("failCompressor")
+}
+
+func (failCompressor) Reset(io.Writer) {}
+
 type failCodec struct{}
 
 func (c failCodec) Name() string {
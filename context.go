@@ -17,6 +17,8 @@ package scalpel
 import (
 	"context"
 	"net/http"
+	"sync/atomic"
+	"time"
 )
 
 // CallInfo represents information relevant to an RPC call.
@@ -59,6 +61,20 @@ type CallInfo interface {
 	// if the request was never actually sent to the server (and thus no
 	// determination ever made about the HTTP method).
 	HTTPMethod() string
+	// Attempts returns the number of attempts made for this call, counting the
+	// original request. It's greater than one only when a client configured
+	// with [WithRetryPolicy] or [WithHedgingPolicy] retries or hedges the
+	// call. On the server, it's the attempt number reported by the client.
+	Attempts() int
+	// Deadline returns the time at which the call is canceled, if it has a
+	// deadline. On the server, it's the deadline the handler enforces after
+	// applying [WithDefaultTimeout] and [WithMaxTimeout]. On the client, it's
+	// the deadline of the context used to make the call.
+	Deadline() (time.Time, bool)
+	// DeadlineClamped reports whether the handler shortened the client's
+	// deadline to the limit set by [WithMaxTimeout]. It's always false on the
+	// client.
+	DeadlineClamped() bool
 
 	internalOnly()
 }
@@ -84,6 +100,7 @@ func CallInfoForHandlerContext(ctx context.Context) (CallInfo, bool) {
 
 // handlerCallInfo is a CallInfo implementation used for unary handlers.
 type handlerCallInfo struct {
+	handlerDeadline
 	spec            Spec
 	peer            Peer
 	method          string
@@ -125,11 +142,17 @@ func (c *handlerCallInfo) HTTPMethod() string {
 	return c.method
 }
 
+func (c *handlerCallInfo) Attempts() int {
+	return attemptsFromHeader(c.requestHeader)
+}
+
 // internalOnly implements CallInfo.
 func (c *handlerCallInfo) internalOnly() {}
 
 // streamingHandlerCallInfo is a CallInfo implementation used for streaming RPC handlers.
 type streamingHandlerCallInfo struct {
+	handlerDeadline
+
 	conn StreamingHandlerConn
 }
 
@@ -158,6 +181,10 @@ func (c *streamingHandlerCallInfo) HTTPMethod() string {
 	return http.MethodPost
 }
 
+func (c *streamingHandlerCallInfo) Attempts() int {
+	return attemptsFromHeader(c.conn.RequestHeader())
+}
+
 // internalOnly implements CallInfo.
 func (c *streamingHandlerCallInfo) internalOnly() {}
 
@@ -168,6 +195,9 @@ type clientCallInfo struct {
 	peer          Peer
 	method        string
 	requestHeader http.Header
+	attempts      atomic.Int32
+	deadline      time.Time
+	hasDeadline   bool
 }
 
 func (c *clientCallInfo) Spec() Spec {
@@ -203,6 +233,18 @@ func (c *clientCallInfo) HTTPMethod() string {
 	return c.method
 }
 
+func (c *clientCallInfo) Attempts() int {
+	return int(c.attempts.Load())
+}
+
+func (c *clientCallInfo) Deadline() (time.Time, bool) {
+	return c.deadline, c.hasDeadline
+}
+
+func (c *clientCallInfo) DeadlineClamped() bool {
+	return false
+}
+
 // internalOnly implements CallInfo.
 func (c *clientCallInfo) internalOnly() {}
 
@@ -224,6 +266,31 @@ type sentinelContextKey struct{}
 // handlerCallInfoContextKey is the key used to store handler call info in context.
 type handlerCallInfoContextKey struct{}
 
+// deadlineClampedContextKey is the key used to record, in a handler context,
+// that the client's deadline was shortened by WithMaxTimeout.
+type deadlineClampedContextKey struct{}
+
+// handlerDeadline implements the deadline methods of CallInfo for handlers.
+type handlerDeadline struct {
+	deadline    time.Time
+	hasDeadline bool
+	clamped     bool
+}
+
+func newHandlerDeadline(ctx context.Context) handlerDeadline {
+	deadline, ok := ctx.Deadline()
+	clamped, _ := ctx.Value(deadlineClampedContextKey{}).(bool)
+	return handlerDeadline{deadline: deadline, hasDeadline: ok, clamped: clamped}
+}
+
+func (d handlerDeadline) Deadline() (time.Time, bool) {
+	return d.deadline, d.hasDeadline
+}
+
+func (d handlerDeadline) DeadlineClamped() bool {
+	return d.clamped
+}
+
 // responseSource indicates a type that manages response headers and trailers.
 type responseSource interface {
 	ResponseHeader() http.Header
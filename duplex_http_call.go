@@ -20,8 +20,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // duplexHTTPCall is a full-duplex stream between the client and server. The
@@ -35,6 +37,17 @@ type duplexHTTPCall struct {
 	streamType       StreamType
 	onRequestSend    func(*http.Request)
 	validateResponse func(*http.Response) *Error
+	peekError        func(*http.Response) *Error
+	retryPolicy      *RetryPolicy
+	hedgingPolicy    *HedgingPolicy
+	stats            *rpcStats
+
+	// onRequestSendMu serializes calls to onRequestSend from hedged attempts.
+	onRequestSendMu sync.Mutex
+
+	// replay buffers the request body of streaming calls for retries. If
+	// retries are disabled, it's nil.
+	replay *replayBuffer
 
 	// io.Pipe is used to implement the request body for client streaming calls.
 	// If the request is unary, requestBodyWriter is nil.
@@ -58,6 +71,8 @@ func newDuplexHTTPCall(
 	url *url.URL,
 	spec Spec,
 	header http.Header,
+	retryPolicy *RetryPolicy,
+	hedgingPolicy *HedgingPolicy,
 ) *duplexHTTPCall {
 	// ensure we make a copy of the url before we pass along to the
 	// Request. This ensures if a transport out of our control wants
@@ -81,13 +96,21 @@ func newDuplexHTTPCall(
 		GetBody:    getNoBody,
 		Host:       url.Host,
 	}).WithContext(ctx)
-	return &duplexHTTPCall{
+	call := &duplexHTTPCall{
 		ctx:           ctx,
 		httpClient:    httpClient,
 		streamType:    spec.StreamType,
+		retryPolicy:   retryPolicy,
+		stats:         statsFromContext(ctx, true /* client */),
 		request:       request,
 		responseReady: make(chan struct{}),
 	}
+	if hedgingPolicy != nil && spec.StreamType == StreamTypeUnary && spec.IdempotencyLevel.IsIdempotent() {
+		// Hedging takes the place of retries for idempotent methods.
+		call.hedgingPolicy = hedgingPolicy
+		call.retryPolicy = nil
+	}
+	return call
 }
 
 // Send sends a message to the server.
@@ -103,6 +126,12 @@ func (d *duplexHTTPCall) Send(payload messagePayload) (int64, error) {
 		d.requestBodyWriter = pipeWriter
 		d.request.Body = pipeReader
 		d.request.GetBody = nil // GetBody not supported for client streaming
+		if d.retryPolicy != nil {
+			// Buffer the request body so that we can replay it.
+			d.replay = newReplayBuffer(pipeReader, d.retryPolicy.maxBufferBytes())
+			d.request.Body, _ = d.replay.NewReader()
+			d.request.GetBody = d.replay.NewReader
+		}
 		d.request.ContentLength = -1
 		go d.makeRequest() // concurrent request
 	}
@@ -133,21 +162,36 @@ func (d *duplexHTTPCall) sendUnary(payload messagePayload) (int64, error) {
 		return 0, errors.New("request already sent")
 	}
 	payloadLength := int64(payload.Len())
-	if payloadLength > 0 {
+	if payloadLength > 0 && d.hedgingPolicy != nil {
+		// Hedged attempts may be sent concurrently, so each needs its own body.
+		clones := newPayloadClones(payload)
+		body, err := clones.NewBody()
+		if err != nil {
+			return 0, err
+		}
+		d.request.Body = body
+		d.request.ContentLength = payloadLength
+		d.request.GetBody = clones.NewBody
+		defer clones.Release()
+	} else if payloadLength > 0 {
 		// Build the request body from the payload.
 		payloadBody := newPayloadCloser(payload)
 		d.request.Body = payloadBody
 		d.request.ContentLength = payloadLength
 		d.request.GetBody = func() (io.ReadCloser, error) {
-			if !payloadBody.Rewind() {
+			// A previous attempt may still be reading its body, so each
+			// attempt gets its own reader.
+			rewound := payloadBody.Rewind()
+			if rewound == nil {
 				return nil, errors.New("payload cannot be retried")
 			}
+			payloadBody = rewound
 			return payloadBody, nil
 		}
 		// Release the payload ensuring that after Send returns the
 		// payload is safe to be reused. See [http.RoundTripper] for
 		// more details.
-		defer payloadBody.Release()
+		defer func() { payloadBody.Release() }()
 	}
 	d.makeRequest() // synchronous request
 	if d.responseErr != nil {
@@ -276,6 +320,13 @@ func (d *duplexHTTPCall) SetValidateResponse(validate func(*http.Response) *Erro
 	d.validateResponse = validate
 }
 
+// SetPeekError sets a function that extracts an RPC error carried entirely in
+// the headers of a valid response, without reading the body. It's only used
+// to decide whether to retry. The function runs in a background goroutine.
+func (d *duplexHTTPCall) SetPeekError(peek func(*http.Response) *Error) {
+	d.peekError = peek
+}
+
 // BlockUntilResponseReady returns when the response is ready or reports an
 // error from initializing the request.
 func (d *duplexHTTPCall) BlockUntilResponseReady() error {
@@ -287,20 +338,173 @@ func (d *duplexHTTPCall) makeRequest() {
 	// This runs concurrently with Write and CloseWrite. Read and CloseRead wait
 	// on d.responseReady, so we can't race with them.
 	defer close(d.responseReady)
+	if d.replay != nil {
+		// Once we've committed to a response, we'll never replay the body.
+		defer d.replay.Commit()
+	}
 
 	// Promote the header Host to the request object.
 	if host := getHeaderCanonical(d.request.Header, headerHost); len(host) > 0 {
 		d.request.Host = host
 	}
-	if d.onRequestSend != nil {
-		d.onRequestSend(d.request)
+	if d.hedgingPolicy != nil {
+		d.makeHedgedRequest()
+		return
+	}
+	request := d.request
+	for attempt := 1; ; attempt++ {
+		response, err := d.do(request)
+		if err == nil {
+			if validateErr := d.validateResponse(response); validateErr != nil {
+				err = validateErr
+			}
+		}
+		if d.retryPolicy == nil {
+			d.setResponse(response, err)
+			return
+		}
+		retryErr := err
+		if retryErr == nil && d.peekError != nil {
+			if peekErr := d.peekError(response); peekErr != nil {
+				retryErr = peekErr
+			}
+		}
+		if retryErr == nil {
+			d.retryPolicy.Throttle.onSuccess()
+			d.setResponse(response, nil)
+			return
+		}
+		delay, ok := d.retryPolicy.retryDelay(d.ctx, retryErr, attempt)
+		var next *http.Request
+		if ok {
+			next, ok = d.newAttempt(attempt)
+		}
+		if !ok {
+			// Errors peeked from the response headers are reported by the
+			// protocol when the caller reads the response.
+			d.setResponse(response, err)
+			return
+		}
+		if response != nil {
+			_ = response.Body.Close()
+		}
+		if err := sleepContext(d.ctx, delay); err != nil {
+			d.setResponse(nil, wrapIfContextError(err))
+			return
+		}
+		request = next
+	}
+}
+
+// makeHedgedRequest sends copies of a unary request according to the hedging
+// policy, and keeps the first response that succeeds or fails fatally.
+func (d *duplexHTTPCall) makeHedgedRequest() {
+	policy := d.hedgingPolicy
+	results := make(chan hedgeResult, policy.maxAttempts())
+	cancels := make([]context.CancelFunc, 0, policy.maxAttempts())
+	var pending int
+	stopped := false
+	canSend := func() bool {
+		return !stopped && len(cancels) < policy.maxAttempts()
+	}
+	send := func() {
+		attempts := len(cancels)
+		request := d.request
+		if attempts > 0 {
+			next, ok := d.newAttempt(attempts)
+			if !ok {
+				stopped = true
+				return
+			}
+			request = next
+		}
+		pending++
+		ctx, cancel := context.WithCancel(d.ctx)
+		cancels = append(cancels, cancel)
+		request = request.WithContext(ctx)
+		go func() {
+			response, err := d.do(request)
+			results <- hedgeResult{response: response, err: err, attempt: attempts}
+		}()
+	}
+	send()
+	timer := time.NewTimer(policy.HedgingDelay)
+	defer timer.Stop()
+	var last hedgeResult
+	for pending > 0 {
+		var hedge <-chan time.Time
+		if canSend() {
+			hedge = timer.C
+		}
+		var result hedgeResult
+		select {
+		case <-hedge:
+			send()
+			timer.Reset(policy.HedgingDelay)
+			continue
+		case result = <-results:
+			pending--
+		}
+		// Validation updates the protocol's state, so it runs here rather than
+		// concurrently in each attempt.
+		retryErr := result.err
+		if retryErr == nil {
+			if validateErr := d.validateResponse(result.response); validateErr != nil {
+				result.err = validateErr
+				retryErr = validateErr
+			} else if d.peekError != nil {
+				if peekErr := d.peekError(result.response); peekErr != nil {
+					retryErr = peekErr
+				}
+			}
+		}
+		if retryErr == nil || policy.isFatal(retryErr) {
+			last.close()
+			last = result
+			break
+		}
+		last.close()
+		last = result
+		if canSend() {
+			// Don't wait to replace an attempt that's already failed.
+			send()
+			timer.Reset(policy.HedgingDelay)
+		}
+	}
+	// Cancel the attempts that are still in flight, and discard their
+	// responses.
+	for attempt, cancel := range cancels {
+		if attempt != last.attempt {
+			cancel()
+		}
+	}
+	go func(pending int) {
+		for range pending {
+			result := <-results
+			result.close()
+		}
+	}(pending)
+	if last.response != nil {
+		last.response.Body = &cancelOnClose{ReadCloser: last.response.Body, cancel: cancels[last.attempt]}
+	} else {
+		cancels[last.attempt]()
+	}
+	d.setResponse(last.response, last.err)
+}
+
+// do sends a single attempt of the request.
+func (d *duplexHTTPCall) do(request *http.Request) (*http.Response, error) {
+	if d.onRequestSend != nil || d.stats != nil {
+		d.onRequestSendMu.Lock()
+		if d.onRequestSend != nil {
+			d.onRequestSend(request)
+		}
+		d.stats.outHeader(request.Header, attemptsFromHeader(request.Header))
+		d.onRequestSendMu.Unlock()
 	}
 	// Once we send a message to the server, they send a message back and
 	// establish the receive side of the stream.
-	// On error, we close the request body using the Write side of the pipe.
-	// This ensures HTTP2 streams receive an io.EOF from the Read side of the
-	// pipe. Write's check for io.ErrClosedPipe and will convert this to io.EOF.
-	response, err := d.httpClient.Do(d.request) //nolint:bodyclose
+	response, err := d.httpClient.Do(request) //nolint:bodyclose
 	if err != nil {
 		if errors.Is(err, io.EOF) {
 			// We use io.EOF as a sentinel in many places and don't want this
@@ -308,24 +512,30 @@ func (d *duplexHTTPCall) makeRequest() {
 			err = io.ErrUnexpectedEOF
 		}
 		err = wrapIfContextError(err)
-		err = wrapIfLikelyH2CNotConfiguredError(d.request, err)
+		err = wrapIfLikelyH2CNotConfiguredError(request, err)
 		err = wrapIfLikelyWithGRPCNotUsedError(err)
 		err = wrapIfRSTError(err)
 		if _, ok := asError(err); !ok {
 			err = NewError(CodeUnavailable, err)
 		}
-		d.responseErr = err
-		_ = d.CloseWrite()
-		return
+		return nil, err
 	}
-	// We've got a response. We can now read from the response body.
+	return response, nil
+}
+
+// setResponse records the outcome of the final attempt.
+func (d *duplexHTTPCall) setResponse(response *http.Response, err error) {
 	// Closing the response body is delegated to the caller even on error.
 	d.response = response
-	if err := d.validateResponse(response); err != nil {
+	if err != nil {
+		// On error, we close the request body using the Write side of the pipe.
+		// This ensures HTTP2 streams receive an io.EOF from the Read side of the
+		// pipe. Write's check for io.ErrClosedPipe and will convert this to io.EOF.
 		d.responseErr = err
 		_ = d.CloseWrite()
 		return
 	}
+	d.stats.inHeader(response.Header)
 	if (d.streamType&StreamTypeBidi) == StreamTypeBidi && response.ProtoMajor < 2 {
 		// If we somehow dialed an HTTP/1.x server, fail with an explicit message
 		// rather than returning a more cryptic error later on.
@@ -340,6 +550,34 @@ func (d *duplexHTTPCall) makeRequest() {
 	}
 }
 
+// newAttempt prepares a copy of the request for a retry. It returns false if
+// the request body can't be replayed.
+func (d *duplexHTTPCall) newAttempt(previousAttempts int) (*http.Request, bool) {
+	if d.request.GetBody == nil {
+		return nil, false
+	}
+	body, err := d.request.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	request := d.request.Clone(d.ctx)
+	request.Body = body
+	setHeaderCanonical(request.Header, grpcHeaderPreviousAttempts, strconv.Itoa(previousAttempts))
+	return request, true
+}
+
+// sleepContext waits for the delay to elapse or the context to be done.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // getNoBody is a GetBody function for http.NoBody.
 func getNoBody() (io.ReadCloser, error) {
 	return http.NoBody, nil
@@ -448,18 +686,21 @@ func (p *payloadCloser) Close() error {
 	return nil
 }
 
-// Rewind rewinds the payload to the beginning. It returns false if the
-// payload has been discarded from a previous call to Release.
-func (p *payloadCloser) Rewind() bool {
+// Rewind moves the payload, positioned at the beginning, to a new
+// payloadCloser. Afterwards, p reads as empty. It returns nil if the payload
+// has been discarded from a previous call to Release.
+func (p *payloadCloser) Rewind() *payloadCloser {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if p.payload == nil {
-		return false
+		return nil
 	}
 	if _, err := p.payload.Seek(0, io.SeekStart); err != nil {
-		return false
+		return nil
 	}
-	return true
+	rewound := newPayloadCloser(p.payload)
+	p.payload = nil
+	return rewound
 }
 
 // Release discards the payload. After Release is called, the payload cannot be
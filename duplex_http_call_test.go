@@ -53,6 +53,8 @@ func TestHTTPCallGetBody(t *testing.T) {
 			serverURL,
 			Spec{StreamType: StreamTypeUnary},
 			http.Header{},
+			nil, /* retryPolicy */
+			nil, /* hedgingPolicy */
 		)
 		getBodyCalled := false
 		call.onRequestSend = func(*http.Request) {
@@ -0,0 +1,363 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DynamicClient is a reusable, concurrency-safe client for a procedure chosen
+// at runtime. Rather than generated types, it uses [dynamicpb] messages built
+// from the procedure's [protoreflect.MethodDescriptor], so it can call any
+// procedure whose schema is available: for example, from a
+// [descriptorpb.FileDescriptorSet] or from server reflection.
+//
+// A DynamicClient wraps a [Client], so it accepts the same options and sends
+// the same headers, timeouts, and errors. Depending on the procedure's type,
+// use the CallUnary, CallClientStream, CallServerStream, or CallBidiStream
+// method, or use CallJSON to call any procedure with JSON requests and
+// responses.
+//
+// [descriptorpb.FileDescriptorSet]: https://pkg.go.dev/google.golang.org/protobuf/types/descriptorpb#FileDescriptorSet
+type DynamicClient struct {
+	method protoreflect.MethodDescriptor
+	client *Client[dynamicpb.Message, dynamicpb.Message]
+}
+
+// NewDynamicClient constructs a new DynamicClient for the method. The
+// procedure's URL is the baseURL followed by the method's full service name
+// and method name, as in generated clients.
+func NewDynamicClient(httpClient HTTPClient, baseURL string, method protoreflect.MethodDescriptor, options ...ClientOption) *DynamicClient {
+	procedure := "/" + string(method.Parent().FullName()) + "/" + string(method.Name())
+	return &DynamicClient{
+		method: method,
+		client: NewClient[dynamicpb.Message, dynamicpb.Message](
+			httpClient,
+			strings.TrimRight(baseURL, "/")+procedure,
+			WithClientOptions(options...),
+			WithSchema(method),
+			WithResponseInitializer(func(_ Spec, message any) error {
+				if dynamic, ok := message.(*dynamicpb.Message); ok {
+					*dynamic = *dynamicpb.NewMessage(method.Output())
+				}
+				return nil
+			}),
+		),
+	}
+}
+
+// Method returns the descriptor of the procedure that the client calls.
+func (c *DynamicClient) Method() protoreflect.MethodDescriptor {
+	return c.method
+}
+
+// StreamType returns the procedure's StreamType.
+func (c *DynamicClient) StreamType() StreamType {
+	var streamType StreamType
+	if c.method.IsStreamingClient() {
+		streamType |= StreamTypeClient
+	}
+	if c.method.IsStreamingServer() {
+		streamType |= StreamTypeServer
+	}
+	return streamType
+}
+
+// CallUnary calls a request-response procedure.
+func (c *DynamicClient) CallUnary(ctx context.Context, request *Request[dynamicpb.Message]) (*Response[dynamicpb.Message], error) {
+	return c.client.CallUnary(ctx, request)
+}
+
+// CallClientStream calls a client streaming procedure.
+func (c *DynamicClient) CallClientStream(ctx context.Context) *ClientStreamForClient[dynamicpb.Message, dynamicpb.Message] {
+	return c.client.CallClientStream(ctx)
+}
+
+// CallServerStream calls a server streaming procedure.
+func (c *DynamicClient) CallServerStream(ctx context.Context, request *Request[dynamicpb.Message]) (*ServerStreamForClient[dynamicpb.Message], error) {
+	return c.client.CallServerStream(ctx, request)
+}
+
+// CallBidiStream calls a bidirectional streaming procedure.
+func (c *DynamicClient) CallBidiStream(ctx context.Context) *BidiStreamForClient[dynamicpb.Message, dynamicpb.Message] {
+	return c.client.CallBidiStream(ctx)
+}
+
+// CallJSON calls the procedure, whatever its StreamType, with requests and
+// responses in the Protobuf JSON format.
+//
+// Requests are decoded from requests and sent as they're decoded, so it may
+// wrap a stream like [os.Stdin]. Unary and server streaming procedures take
+// exactly one request; if requests is nil, they're sent an empty message.
+// Each response is passed to onResponse as it arrives. If onResponse returns
+// an error, the call is canceled and CallJSON returns the error.
+//
+// For bidirectional streaming procedures, requests are decoded and sent in a
+// separate goroutine, so responses can arrive while CallJSON waits for the
+// next request. If the call ends while that goroutine is blocked in
+// requests.Decode, CallJSON returns without waiting for Decode to return:
+// the decoded request is discarded, and nothing else is decoded or sent.
+// Otherwise, the goroutine has exited by the time CallJSON returns.
+//
+// CallJSON returns the response headers and trailers, which are also
+// available from errors returned by the server using [Error.Meta].
+func (c *DynamicClient) CallJSON(
+	ctx context.Context,
+	header http.Header,
+	requests *json.Decoder,
+	onResponse func(json.RawMessage) error,
+) (responseHeader, responseTrailer http.Header, _ error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	switch c.StreamType() {
+	case StreamTypeUnary:
+		msg, err := c.decodeSingleRequest(requests)
+		if err != nil {
+			return nil, nil, err
+		}
+		request := NewRequest(msg)
+		mergeHeaders(request.Header(), header)
+		response, err := c.CallUnary(ctx, request)
+		if err != nil {
+			return nil, nil, err
+		}
+		return response.Header(), response.Trailer(), c.handleResponse(response.Msg, onResponse)
+	case StreamTypeClient:
+		stream := c.CallClientStream(ctx)
+		mergeHeaders(stream.RequestHeader(), header)
+		for {
+			msg, err := c.decodeRequest(requests)
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, nil, err
+			}
+			if err := stream.Send(msg); err != nil {
+				// The server has ended the RPC, so the real error is returned
+				// when receiving the response.
+				break
+			}
+		}
+		response, err := stream.CloseAndReceive()
+		if err != nil {
+			return nil, nil, err
+		}
+		return response.Header(), response.Trailer(), c.handleResponse(response.Msg, onResponse)
+	case StreamTypeServer:
+		msg, err := c.decodeSingleRequest(requests)
+		if err != nil {
+			return nil, nil, err
+		}
+		request := NewRequest(msg)
+		mergeHeaders(request.Header(), header)
+		stream, err := c.CallServerStream(ctx, request)
+		if err != nil {
+			return nil, nil, err
+		}
+		for stream.Receive() {
+			if err := c.handleResponse(stream.Msg(), onResponse); err != nil {
+				// Don't wait for the rest of the responses.
+				cancel()
+				_ = stream.Close()
+				return stream.ResponseHeader(), nil, err
+			}
+		}
+		if err := stream.Err(); err != nil {
+			_ = stream.Close()
+			return nil, nil, err
+		}
+		return stream.ResponseHeader(), stream.ResponseTrailer(), stream.Close()
+	default:
+		return c.callBidiJSON(ctx, cancel, header, requests, onResponse)
+	}
+}
+
+func (c *DynamicClient) callBidiJSON(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	header http.Header,
+	requests *json.Decoder,
+	onResponse func(json.RawMessage) error,
+) (http.Header, http.Header, error) {
+	stream := c.CallBidiStream(ctx)
+	mergeHeaders(stream.RequestHeader(), header)
+	// Requests are sent concurrently with receiving responses, so interactive
+	// streams work.
+	sender := &jsonSender{done: make(chan struct{})}
+	go func() {
+		defer close(sender.done)
+		for {
+			msg, err := sender.decode(c, requests)
+			if errors.Is(err, errSenderStopped) {
+				return
+			} else if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				sender.err = err
+				cancel()
+				return
+			}
+			if err := stream.Send(msg); err != nil {
+				return
+			}
+		}
+		_ = stream.CloseRequest()
+	}()
+	var err error
+	for {
+		var msg *dynamicpb.Message
+		msg, err = stream.Receive()
+		if err != nil {
+			break
+		}
+		if err = c.handleResponse(msg, onResponse); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		// Don't wait for the rest of the responses.
+		cancel()
+	}
+	closeErr := stream.CloseResponse()
+	if sender.stop(cancel) && sender.err != nil {
+		return nil, nil, sender.err
+	}
+	if errors.Is(err, io.EOF) {
+		err = closeErr
+	}
+	return stream.ResponseHeader(), stream.ResponseTrailer(), err
+}
+
+// errSenderStopped is returned by jsonSender.decode once the call has ended.
+var errSenderStopped = errors.New("sender stopped")
+
+// jsonSender coordinates the goroutine that sends bidirectional streaming
+// requests with the end of the call. The goroutine can't be interrupted while
+// it's blocked decoding a request, so the call only waits for it at other
+// times.
+type jsonSender struct {
+	done chan struct{} // closed when the goroutine exits
+	err  error         // decoding error, set before done is closed
+
+	mu       sync.Mutex
+	stopped  bool
+	decoding bool
+}
+
+// decode decodes the next request, unless the call has ended.
+func (s *jsonSender) decode(c *DynamicClient, requests *json.Decoder) (*dynamicpb.Message, error) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil, errSenderStopped
+	}
+	s.decoding = true
+	s.mu.Unlock()
+	msg, err := c.decodeRequest(requests)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decoding = false
+	if s.stopped {
+		return nil, errSenderStopped
+	}
+	return msg, err
+}
+
+// stop ends the goroutine and waits for it to exit, unless it's blocked
+// decoding a request. It reports whether the goroutine has exited.
+func (s *jsonSender) stop(cancel context.CancelFunc) bool {
+	s.mu.Lock()
+	s.stopped = true
+	decoding := s.decoding
+	s.mu.Unlock()
+	if decoding {
+		select {
+		case <-s.done:
+			return true
+		default:
+			return false
+		}
+	}
+	// Cancel any blocked sends.
+	cancel()
+	<-s.done
+	return true
+}
+
+// decodeRequest decodes the next request, returning io.EOF if there are no
+// more.
+func (c *DynamicClient) decodeRequest(requests *json.Decoder) (*dynamicpb.Message, error) {
+	if requests == nil {
+		return nil, io.EOF
+	}
+	var data json.RawMessage
+	if err := requests.Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, errorf(CodeInvalidArgument, "read request: %w", err)
+	}
+	msg := dynamicpb.NewMessage(c.method.Input())
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, errorf(CodeInvalidArgument, "unmarshal %s: %w", c.method.Input().FullName(), err)
+	}
+	return msg, nil
+}
+
+// decodeSingleRequest decodes the only request for unary and server streaming
+// procedures.
+func (c *DynamicClient) decodeSingleRequest(requests *json.Decoder) (*dynamicpb.Message, error) {
+	if requests == nil {
+		return dynamicpb.NewMessage(c.method.Input()), nil
+	}
+	msg, err := c.decodeRequest(requests)
+	if errors.Is(err, io.EOF) {
+		return nil, errorf(CodeInvalidArgument, "%s requires a request", c.StreamType())
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := c.decodeRequest(requests); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errorf(CodeInvalidArgument, "%s takes only one request", c.StreamType())
+	}
+	return msg, nil
+}
+
+func (c *DynamicClient) handleResponse(msg *dynamicpb.Message, onResponse func(json.RawMessage) error) error {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return errorf(CodeInternal, "marshal %s: %w", c.method.Output().FullName(), err)
+	}
+	if onResponse == nil {
+		return nil
+	}
+	if err := onResponse(data); err != nil {
+		return fmt.Errorf("handle response: %w", err)
+	}
+	return nil
+}
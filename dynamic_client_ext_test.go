@@ -0,0 +1,203 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestNewDynamicClient(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}))
+	server := memhttptest.NewServer(t, mux)
+	methods := pingv1.File_connect_ping_v1_ping_proto.Services().ByName("PingService").Methods()
+	protocols := map[string][]connect.ClientOption{
+		"connect": {connect.WithProtoJSON()},
+		"grpc":    {connect.WithGRPC()},
+		"grpcweb": {connect.WithGRPCWeb()},
+	}
+	for name, options := range protocols {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			newClient := func(method protoreflect.Name) *connect.DynamicClient {
+				return connect.NewDynamicClient(server.Client(), server.URL()+"/", methods.ByName(method), options...)
+			}
+			header := http.Header{}
+			header.Set(clientHeader, "dynamic")
+			t.Run("unary", func(t *testing.T) {
+				t.Parallel()
+				client := newClient("Ping")
+				assert.Equal(t, client.StreamType(), connect.StreamTypeUnary)
+				responses, responseHeader, responseTrailer, err := callJSON(t, client, header, `{"number": 42, "text": "hi"}`)
+				assert.Nil(t, err)
+				assert.Equal(t, responses, []string{`{"number":"42","text":"hi"}`})
+				assert.Equal(t, responseHeader.Get(handlerHeader), "dynamic")
+				assert.Equal(t, responseTrailer.Get(handlerTrailer), "dynamic")
+				// Unary procedures take exactly one request.
+				_, _, _, err = callJSON(t, client, nil, `{} {}`)
+				assert.Equal(t, connect.CodeOf(err), connect.CodeInvalidArgument)
+				_, _, _, err = callJSON(t, client, nil, `{"unknown": 1}`)
+				assert.Equal(t, connect.CodeOf(err), connect.CodeInvalidArgument)
+			})
+			t.Run("client_stream", func(t *testing.T) {
+				t.Parallel()
+				client := newClient("Sum")
+				assert.Equal(t, client.StreamType(), connect.StreamTypeClient)
+				responses, responseHeader, _, err := callJSON(t, client, header, `{"number": 1} {"number": 2} {"number": 3}`)
+				assert.Nil(t, err)
+				assert.Equal(t, responses, []string{`{"sum":"6"}`})
+				assert.Equal(t, responseHeader.Get(handlerHeader), "dynamic")
+			})
+			t.Run("server_stream", func(t *testing.T) {
+				t.Parallel()
+				client := newClient("CountUp")
+				assert.Equal(t, client.StreamType(), connect.StreamTypeServer)
+				responses, _, responseTrailer, err := callJSON(t, client, header, `{"number": 3}`)
+				assert.Nil(t, err)
+				assert.Equal(t, responses, []string{`{"number":"1"}`, `{"number":"2"}`, `{"number":"3"}`})
+				assert.Equal(t, responseTrailer.Get(handlerTrailer), "dynamic")
+				_, _, _, err = callJSON(t, client, nil, `{"number": 0}`)
+				assert.Equal(t, connect.CodeOf(err), connect.CodeInvalidArgument)
+			})
+			t.Run("bidi_stream", func(t *testing.T) {
+				t.Parallel()
+				client := newClient("CumSum")
+				assert.Equal(t, client.StreamType(), connect.StreamTypeBidi)
+				responses, responseHeader, _, err := callJSON(t, client, header, `{"number": 1} {"number": 2} {"number": 3}`)
+				assert.Nil(t, err)
+				assert.Equal(t, responses, []string{`{"sum":"1"}`, `{"sum":"3"}`, `{"sum":"6"}`})
+				assert.Equal(t, responseHeader.Get(handlerHeader), "dynamic")
+			})
+			t.Run("error", func(t *testing.T) {
+				t.Parallel()
+				client := newClient("Fail")
+				_, _, _, err := callJSON(t, client, header, `{"code": 9}`)
+				assert.Equal(t, connect.CodeOf(err), connect.CodeFailedPrecondition)
+				var connectErr *connect.Error
+				assert.True(t, errors.As(err, &connectErr))
+				assert.Equal(t, connectErr.Message(), errorMessage)
+				assert.Equal(t, connectErr.Meta().Get(handlerHeader), "dynamic")
+			})
+		})
+	}
+	t.Run("typed", func(t *testing.T) {
+		t.Parallel()
+		client := connect.NewDynamicClient(server.Client(), server.URL(), methods.ByName("Ping"))
+		assert.Equal(t, client.Method().FullName(), "connect.ping.v1.PingService.Ping")
+		// Deadlines are sent as with any other client.
+		ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+		defer cancel()
+		request := connect.NewRequest(dynamicMessage(t, client.Method().Input(), `{"number": 7}`))
+		response, err := client.CallUnary(ctx, request)
+		assert.Nil(t, err)
+		assert.Equal(t, response.Msg.Get(client.Method().Output().Fields().ByName("number")).Int(), 7)
+		assert.Equal(t, request.Spec().Procedure, pingv1connect.PingServicePingProcedure)
+	})
+	t.Run("on_response_error", func(t *testing.T) {
+		t.Parallel()
+		client := connect.NewDynamicClient(server.Client(), server.URL(), methods.ByName("CountUp"))
+		errStop := errors.New("stop")
+		_, _, err := client.CallJSON(t.Context(), nil, json.NewDecoder(strings.NewReader(`{"number": 100}`)), func(json.RawMessage) error {
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+	})
+	t.Run("bidi_sender", func(t *testing.T) {
+		t.Parallel()
+		client := connect.NewDynamicClient(server.Client(), server.URL(), methods.ByName("CumSum"))
+		errStop := errors.New("stop")
+		stopAfterFirst := func(json.RawMessage) error { return errStop }
+
+		// Once CallJSON returns, requests are no longer read, except to finish a
+		// call to Decode that was already in progress.
+		requests := &countingReader{reader: strings.NewReader(strings.Repeat(`{"number": 1} `, 10000))}
+		_, _, err := client.CallJSON(t.Context(), nil, json.NewDecoder(requests), stopAfterFirst)
+		assert.ErrorIs(t, err, errStop)
+		time.Sleep(10 * time.Millisecond)
+		read := requests.count.Load()
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, requests.count.Load(), read)
+
+		// CallJSON doesn't wait for requests that never arrive.
+		pipeReader, pipeWriter := io.Pipe()
+		defer pipeWriter.Close()
+		go func() {
+			_, _ = pipeWriter.Write([]byte(`{"number": 1}`))
+		}()
+		_, _, err = client.CallJSON(t.Context(), nil, json.NewDecoder(pipeReader), stopAfterFirst)
+		assert.ErrorIs(t, err, errStop)
+	})
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (r *countingReader) Read(data []byte) (int, error) {
+	n, err := r.reader.Read(data)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+func callJSON(tb testing.TB, client *connect.DynamicClient, header http.Header, requests string) ([]string, http.Header, http.Header, error) {
+	tb.Helper()
+	var responses []string
+	responseHeader, responseTrailer, err := client.CallJSON(
+		tb.Context(),
+		header,
+		json.NewDecoder(strings.NewReader(requests)),
+		func(message json.RawMessage) error {
+			// Protobuf JSON output isn't stable, so compact it for comparison.
+			var compacted strings.Builder
+			encoder := json.NewEncoder(&compacted)
+			var value any
+			if err := json.Unmarshal(message, &value); err != nil {
+				return err
+			}
+			if err := encoder.Encode(value); err != nil {
+				return err
+			}
+			responses = append(responses, strings.TrimSpace(compacted.String()))
+			return nil
+		},
+	)
+	return responses, responseHeader, responseTrailer, err
+}
+
+func dynamicMessage(tb testing.TB, desc protoreflect.MessageDescriptor, data string) *dynamicpb.Message {
+	tb.Helper()
+	msg := dynamicpb.NewMessage(desc)
+	assert.Nil(tb, protojson.Unmarshal([]byte(data), msg))
+	return msg
+}
@@ -125,11 +125,14 @@ func (e *envelope) Len() int {
 }
 
 type envelopeWriter struct {
-	ctx          context.Context //nolint:containedctx
-	sender       messageSender
-	codec        Codec
-	bufferPool   *bufferPool
-	sendMaxBytes int
+	ctx              context.Context //nolint:containedctx
+	sender           messageSender
+	codec            Codec
+	compressMinBytes int
+	compressionPool  *compressionPool
+	bufferPool       *bufferPool
+	sendMaxBytes     int
+	stats            *rpcStats
 }
 
 func (w *envelopeWriter) Marshal(message any) *Error {
@@ -153,10 +156,27 @@ func (w *envelopeWriter) Marshal(message any) *Error {
 // Write writes the enveloped message, compressing as necessary. It doesn't
 // retain any references to the supplied envelope or its underlying data.
 func (w *envelopeWriter) Write(env *envelope) *Error {
-	if w.sendMaxBytes > 0 && env.Data.Len() > w.sendMaxBytes {
-		return errorf(CodeResourceExhausted, "message size %d exceeds sendMaxBytes %d", env.Data.Len(), w.sendMaxBytes)
+	length := env.Data.Len()
+	if env.IsSet(flagEnvelopeCompressed) ||
+		w.compressionPool == nil ||
+		env.Data.Len() < w.compressMinBytes {
+		if w.sendMaxBytes > 0 && env.Data.Len() > w.sendMaxBytes {
+			return errorf(CodeResourceExhausted, "message size %d exceeds sendMaxBytes %d", env.Data.Len(), w.sendMaxBytes)
+		}
+		return w.write(env, length)
+	}
+	data := w.bufferPool.Get()
+	defer w.bufferPool.Put(data)
+	if err := w.compressionPool.Compress(data, env.Data); err != nil {
+		return err
+	}
+	if w.sendMaxBytes > 0 && data.Len() > w.sendMaxBytes {
+		return errorf(CodeResourceExhausted, "compressed message size %d exceeds sendMaxBytes %d", data.Len(), w.sendMaxBytes)
 	}
-	return w.write(env)
+	return w.write(&envelope{
+		Data:  data,
+		Flags: env.Flags | flagEnvelopeCompressed,
+	}, length)
 }
 
 func (w *envelopeWriter) marshalAppend(message any, codec marshalAppender) *Error {
@@ -197,7 +217,10 @@ func (w *envelopeWriter) marshal(message any) *Error {
 	return w.Write(envelope)
 }
 
-func (w *envelopeWriter) write(env *envelope) *Error {
+// write sends an envelope. The length is the size of the message before
+// compression, for stats.
+func (w *envelopeWriter) write(env *envelope, length int) *Error {
+	wireLength := 5 + env.Data.Len()
 	if _, err := w.sender.Send(env); err != nil {
 		err = wrapIfContextDone(w.ctx, err)
 		if connectErr, ok := asError(err); ok {
@@ -205,17 +228,23 @@ func (w *envelopeWriter) write(env *envelope) *Error {
 		}
 		return errorf(CodeUnknown, "write envelope: %w", err)
 	}
+	if env.Flags&^flagEnvelopeCompressed == 0 {
+		// Protocol-specific flags mark the end of the stream, not a message.
+		w.stats.outPayload(length, wireLength, env.IsSet(flagEnvelopeCompressed))
+	}
 	return nil
 }
 
 type envelopeReader struct {
-	ctx          context.Context //nolint:containedctx
-	reader       io.Reader
-	bytesRead    int64 // detect trailers-only gRPC responses
-	codec        Codec
-	last         envelope
-	bufferPool   *bufferPool
-	readMaxBytes int
+	ctx             context.Context //nolint:containedctx
+	reader          io.Reader
+	bytesRead       int64 // detect trailers-only gRPC responses
+	codec           Codec
+	last            envelope
+	compressionPool *compressionPool
+	bufferPool      *bufferPool
+	readMaxBytes    int
+	stats           *rpcStats
 }
 
 func (r *envelopeReader) Unmarshal(message any) *Error {
@@ -230,7 +259,7 @@ func (r *envelopeReader) Unmarshal(message any) *Error {
 	env := &envelope{Data: buffer}
 	err := r.Read(env)
 	switch {
-	case err == nil && env.IsSet(flagEnvelopeCompressed):
+	case err == nil && env.IsSet(flagEnvelopeCompressed) && r.compressionPool == nil:
 		return errorf(
 			CodeInternal,
 			"protocol error: sent compressed message without compression support",
@@ -240,6 +269,7 @@ func (r *envelopeReader) Unmarshal(message any) *Error {
 		env.Data.Len() == 0:
 		// This is a standard message (because none of the top 7 bits are set) and
 		// there's no data, so the zero value of the message is correct.
+		r.stats.inPayload(0, 5, env.IsSet(flagEnvelopeCompressed))
 		return nil
 	case err != nil && errors.Is(err, io.EOF):
 		// The stream has ended. Propagate the EOF to the caller.
@@ -250,6 +280,19 @@ func (r *envelopeReader) Unmarshal(message any) *Error {
 	}
 
 	data := env.Data
+	wireLength := 5 + data.Len()
+	if data.Len() > 0 && env.IsSet(flagEnvelopeCompressed) {
+		decompressed := r.bufferPool.Get()
+		defer func() {
+			if decompressed != dontRelease {
+				r.bufferPool.Put(decompressed)
+			}
+		}()
+		if err := r.compressionPool.Decompress(decompressed, data, int64(r.readMaxBytes)); err != nil {
+			return err
+		}
+		data = decompressed
+	}
 
 	if env.Flags != 0 && env.Flags != flagEnvelopeCompressed {
 		// Drain the rest of the stream to ensure there is no extra data.
@@ -279,6 +322,7 @@ func (r *envelopeReader) Unmarshal(message any) *Error {
 	if err := r.codec.Unmarshal(data.Bytes(), message); err != nil {
 		return errorf(CodeInvalidArgument, "unmarshal message: %w", err)
 	}
+	r.stats.inPayload(data.Len(), wireLength, env.IsSet(flagEnvelopeCompressed))
 	return nil
 }
 
@@ -343,11 +387,13 @@ func (r *envelopeReader) Read(env *envelope) *Error {
 }
 
 func makeEnvelopePrefix(flags uint8, size int) ([5]byte, error) {
-	if size < 0 || size > math.MaxUint32 {
+	// Cast as 64-bit to ensure comparison works on all architectures.
+	size64 := int64(size)
+	if size64 < 0 || size64 > math.MaxUint32 {
 		return [5]byte{}, fmt.Errorf("connect.makeEnvelopePrefix: size %d out of bounds", size)
 	}
 	prefix := [5]byte{}
 	prefix[0] = flags
-	binary.BigEndian.PutUint32(prefix[1:5], uint32(size))
+	binary.BigEndian.PutUint32(prefix[1:5], uint32(size64))
 	return prefix, nil
 }
@@ -0,0 +1,258 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errdetails builds and inspects the standard error details defined
+// in google/rpc/error_details.proto, such as BadRequest and RetryInfo.
+//
+// The details are sent with their canonical google.rpc type names, so clients
+// in any language can decode them, and details from other servers can be read
+// with this package's extractors. This package doesn't depend on Google's
+// genproto module, and its messages don't conflict with genproto's if both are
+// linked into the same binary.
+package errdetails
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/agentio/scalpel"
+	googlerpc "github.com/agentio/scalpel/internal/gen/connectext/google/rpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	typeURLPrefix = "type.googleapis.com/"
+	// packagePrefix is prepended to the package of this module's copy of the
+	// google.rpc messages.
+	packagePrefix = "connectext."
+)
+
+// The standard error details. See google/rpc/error_details.proto for a
+// description of each message.
+type (
+	ErrorInfo             = googlerpc.ErrorInfo
+	RetryInfo             = googlerpc.RetryInfo
+	DebugInfo             = googlerpc.DebugInfo
+	QuotaFailure          = googlerpc.QuotaFailure
+	QuotaViolation        = googlerpc.QuotaFailure_Violation
+	PreconditionFailure   = googlerpc.PreconditionFailure
+	PreconditionViolation = googlerpc.PreconditionFailure_Violation
+	BadRequest            = googlerpc.BadRequest
+	FieldViolation        = googlerpc.BadRequest_FieldViolation
+	RequestInfo           = googlerpc.RequestInfo
+	ResourceInfo          = googlerpc.ResourceInfo
+	Help                  = googlerpc.Help
+	HelpLink              = googlerpc.Help_Link
+	LocalizedMessage      = googlerpc.LocalizedMessage
+)
+
+// NewDetail wraps a message in a [scalpel.ErrorDetail]. Messages from this
+// package are sent with their canonical google.rpc type names; other messages
+// behave as they do with [scalpel.NewErrorDetail].
+func NewDetail(msg proto.Message) (*scalpel.ErrorDetail, error) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return scalpel.NewErrorDetail(&anypb.Any{
+		TypeUrl: typeURLPrefix + typeName(msg),
+		Value:   data,
+	})
+}
+
+// AddDetail adds a detail to the error and returns the error. Details that
+// can't be marshaled, for example because they contain invalid UTF-8, are
+// omitted.
+func AddDetail(err *scalpel.Error, msg proto.Message) *scalpel.Error {
+	if detail, detailErr := NewDetail(msg); detailErr == nil {
+		err.AddDetail(detail)
+	}
+	return err
+}
+
+// NewFieldViolation constructs a [FieldViolation] for [NewBadRequestError].
+// The field is a path to the offending field, such as "user.email".
+func NewFieldViolation(field, description string) *FieldViolation {
+	return &FieldViolation{Field: field, Description: description}
+}
+
+// NewBadRequestError constructs an error with CodeInvalidArgument and a
+// [BadRequest] detail listing the violations. The error's message summarizes
+// the violations.
+func NewBadRequestError(violations ...*FieldViolation) *scalpel.Error {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.GetField()+": "+violation.GetDescription())
+	}
+	err := scalpel.NewError(scalpel.CodeInvalidArgument, summarize("invalid request", messages))
+	return AddDetail(err, &BadRequest{FieldViolations: violations})
+}
+
+// NewPreconditionViolation constructs a [PreconditionViolation] for
+// [NewPreconditionFailureError]. The type is a service-specific category, such
+// as "TOS", and the subject is relative to it.
+func NewPreconditionViolation(typ, subject, description string) *PreconditionViolation {
+	return &PreconditionViolation{Type: typ, Subject: subject, Description: description}
+}
+
+// NewPreconditionFailureError constructs an error with
+// CodeFailedPrecondition and a [PreconditionFailure] detail listing the
+// violations.
+func NewPreconditionFailureError(violations ...*PreconditionViolation) *scalpel.Error {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.GetType()+" "+violation.GetSubject()+": "+violation.GetDescription())
+	}
+	err := scalpel.NewError(scalpel.CodeFailedPrecondition, summarize("precondition failed", messages))
+	return AddDetail(err, &PreconditionFailure{Violations: violations})
+}
+
+// NewQuotaViolation constructs a [QuotaViolation] for [NewQuotaFailureError].
+// The subject is what the quota applies to, such as "clientip:203.0.113.7".
+func NewQuotaViolation(subject, description string) *QuotaViolation {
+	return &QuotaViolation{Subject: subject, Description: description}
+}
+
+// NewQuotaFailureError constructs an error with CodeResourceExhausted and a
+// [QuotaFailure] detail listing the violations.
+func NewQuotaFailureError(violations ...*QuotaViolation) *scalpel.Error {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.GetSubject()+": "+violation.GetDescription())
+	}
+	err := scalpel.NewError(scalpel.CodeResourceExhausted, summarize("quota exceeded", messages))
+	return AddDetail(err, &QuotaFailure{Violations: violations})
+}
+
+// NewResourceError constructs an error with the given code and a
+// [ResourceInfo] detail. It's typically used with CodeNotFound,
+// CodeAlreadyExists, and CodePermissionDenied.
+func NewResourceError(code scalpel.Code, resource *ResourceInfo) *scalpel.Error {
+	message := resource.GetResourceType() + " " + resource.GetResourceName()
+	if description := resource.GetDescription(); description != "" {
+		message += ": " + description
+	}
+	return AddDetail(scalpel.NewError(code, errors.New(message)), resource)
+}
+
+// AddErrorInfo adds an [ErrorInfo] detail to the error and returns the error.
+// The reason is a constant in UPPER_SNAKE_CASE, and the domain is usually the
+// name of the service that produced the error.
+func AddErrorInfo(err *scalpel.Error, reason, domain string, metadata map[string]string) *scalpel.Error {
+	return AddDetail(err, &ErrorInfo{Reason: reason, Domain: domain, Metadata: metadata})
+}
+
+// AddRetryInfo adds a [RetryInfo] detail to the error and returns the error.
+// Clients that retry the call wait for the delay rather than their usual
+// backoff.
+func AddRetryInfo(err *scalpel.Error, delay time.Duration) *scalpel.Error {
+	return AddDetail(err, &RetryInfo{RetryDelay: durationpb.New(delay)})
+}
+
+// AddHelp adds a [Help] detail to the error and returns the error.
+func AddHelp(err *scalpel.Error, links ...*HelpLink) *scalpel.Error {
+	return AddDetail(err, &Help{Links: links})
+}
+
+// AddLocalizedMessage adds a [LocalizedMessage] detail to the error and
+// returns the error. The locale is a BCP 47 tag, such as "en-US".
+func AddLocalizedMessage(err *scalpel.Error, locale, message string) *scalpel.Error {
+	return AddDetail(err, &LocalizedMessage{Locale: locale, Message: message})
+}
+
+// AddRequestInfo adds a [RequestInfo] detail to the error and returns the
+// error.
+func AddRequestInfo(err *scalpel.Error, requestID, servingData string) *scalpel.Error {
+	return AddDetail(err, &RequestInfo{RequestId: requestID, ServingData: servingData})
+}
+
+// Find returns the first detail of type T attached to err. For example:
+//
+//	if badRequest, ok := errdetails.Find[errdetails.BadRequest](err); ok {
+//		for _, violation := range badRequest.GetFieldViolations() {
+//			...
+//		}
+//	}
+//
+// Find recognizes details sent by any server, whether they use their
+// canonical google.rpc type names or this module's copies.
+func Find[T any, PT interface {
+	*T
+	proto.Message
+}](err error) (PT, bool) {
+	var connectErr *scalpel.Error
+	if !errors.As(err, &connectErr) {
+		return nil, false
+	}
+	msg := PT(new(T))
+	name := string(msg.ProtoReflect().Descriptor().FullName())
+	canonical := strings.TrimPrefix(name, packagePrefix)
+	for _, detail := range connectErr.Details() {
+		if typ := detail.Type(); typ != canonical && typ != name {
+			continue
+		}
+		if proto.Unmarshal(detail.Bytes(), msg) == nil {
+			return msg, true
+		}
+		proto.Reset(msg)
+	}
+	return nil, false
+}
+
+// RetryDelay returns the delay from the error's [RetryInfo] detail, if any.
+func RetryDelay(err error) (time.Duration, bool) {
+	info, ok := Find[RetryInfo](err)
+	if !ok || !info.GetRetryDelay().IsValid() {
+		return 0, false
+	}
+	return info.GetRetryDelay().AsDuration(), true
+}
+
+// ErrorReason returns the reason from the error's [ErrorInfo] detail, if any.
+func ErrorReason(err error) (string, bool) {
+	info, ok := Find[ErrorInfo](err)
+	if !ok {
+		return "", false
+	}
+	return info.GetReason(), true
+}
+
+// FieldViolations returns the violations from the error's [BadRequest]
+// detail, if any.
+func FieldViolations(err error) []*FieldViolation {
+	badRequest, ok := Find[BadRequest](err)
+	if !ok {
+		return nil
+	}
+	return badRequest.GetFieldViolations()
+}
+
+// typeName returns the name that a message is sent with.
+func typeName(msg proto.Message) string {
+	name := string(msg.ProtoReflect().Descriptor().FullName())
+	if strings.HasPrefix(name, packagePrefix+"google.rpc.") {
+		return strings.TrimPrefix(name, packagePrefix)
+	}
+	return name
+}
+
+func summarize(prefix string, messages []string) error {
+	if len(messages) == 0 {
+		return errors.New(prefix)
+	}
+	return errors.New(prefix + ": " + strings.Join(messages, "; "))
+}
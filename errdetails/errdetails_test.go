@@ -0,0 +1,156 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errdetails_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/errdetails"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+)
+
+func TestErrorDetails(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}))
+	server := memhttptest.NewServer(t, mux)
+	protocols := []struct {
+		name string
+		opt  connect.ClientOption
+	}{
+		{connect.ProtocolConnect, connect.WithConnect()},
+		{connect.ProtocolGRPC, connect.WithGRPC()},
+		{connect.ProtocolGRPCWeb, connect.WithGRPCWeb()},
+	}
+	for _, protocol := range protocols {
+		t.Run(protocol.name, func(t *testing.T) {
+			t.Parallel()
+			client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), protocol.opt)
+			_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: -1}))
+			assert.Equal(t, connect.CodeOf(err), connect.CodeInvalidArgument)
+			var connectErr *connect.Error
+			assert.True(t, errors.As(err, &connectErr))
+			assert.Equal(t, connectErr.Message(), "invalid request: number: must be positive")
+			types := make([]string, 0, len(connectErr.Details()))
+			for _, detail := range connectErr.Details() {
+				types = append(types, detail.Type())
+			}
+			assert.Equal(t, types, []string{"google.rpc.BadRequest", "google.rpc.ErrorInfo", "google.rpc.RetryInfo"})
+
+			violations := errdetails.FieldViolations(err)
+			assert.Equal(t, len(violations), 1)
+			assert.Equal(t, violations[0].GetField(), "number")
+			reason, ok := errdetails.ErrorReason(err)
+			assert.True(t, ok)
+			assert.Equal(t, reason, "NEGATIVE_NUMBER")
+			info, ok := errdetails.Find[errdetails.ErrorInfo](err)
+			assert.True(t, ok)
+			assert.Equal(t, info.GetMetadata(), map[string]string{"number": "-1"})
+			delay, ok := errdetails.RetryDelay(err)
+			assert.True(t, ok)
+			assert.Equal(t, delay, 2*time.Second)
+			_, ok = errdetails.Find[errdetails.Help](err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestErrorConstructors(t *testing.T) {
+	t.Parallel()
+	err := errdetails.NewPreconditionFailureError(errdetails.NewPreconditionViolation("TOS", "user:42", "terms not accepted"))
+	assert.Equal(t, err.Code(), connect.CodeFailedPrecondition)
+	assert.Equal(t, err.Message(), "precondition failed: TOS user:42: terms not accepted")
+	failure, ok := errdetails.Find[errdetails.PreconditionFailure](err)
+	assert.True(t, ok)
+	assert.Equal(t, failure.GetViolations()[0].GetSubject(), "user:42")
+
+	err = errdetails.NewQuotaFailureError(errdetails.NewQuotaViolation("clientip:203.0.113.7", "daily limit exceeded"))
+	assert.Equal(t, err.Code(), connect.CodeResourceExhausted)
+	_, ok = errdetails.Find[errdetails.QuotaFailure](err)
+	assert.True(t, ok)
+
+	err = errdetails.NewResourceError(connect.CodeNotFound, &errdetails.ResourceInfo{
+		ResourceType: "user",
+		ResourceName: "users/42",
+	})
+	assert.Equal(t, err.Code(), connect.CodeNotFound)
+	assert.Equal(t, err.Message(), "user users/42")
+	errdetails.AddHelp(err, &errdetails.HelpLink{Description: "docs", Url: "https://example.com"})
+	errdetails.AddLocalizedMessage(err, "fr-FR", "utilisateur introuvable")
+	localized, ok := errdetails.Find[errdetails.LocalizedMessage](err)
+	assert.True(t, ok)
+	assert.Equal(t, localized.GetLocale(), "fr-FR")
+
+	// Details with invalid UTF-8 can't be marshaled, so they're omitted.
+	err = errdetails.NewBadRequestError(errdetails.NewFieldViolation("name", "\xff"))
+	assert.Equal(t, len(err.Details()), 0)
+
+	_, ok = errdetails.RetryDelay(errors.New("oops"))
+	assert.False(t, ok)
+	assert.Nil(t, errdetails.FieldViolations(nil))
+}
+
+func TestRetryInfo(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{calls: &calls}))
+	server := memhttptest.NewServer(t, mux)
+	client := pingv1connect.NewPingServiceClient(
+		server.Client(),
+		server.URL(),
+		connect.WithRetryPolicy(connect.RetryPolicy{
+			MaxAttempts:       2,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        time.Millisecond,
+			BackoffMultiplier: 1,
+			RetryableCodes:    []connect.Code{connect.CodeUnavailable},
+		}),
+	)
+	start := time.Now()
+	_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: 1}))
+	assert.Nil(t, err)
+	assert.Equal(t, calls.Load(), 2)
+	// The client waits for the server's delay rather than its own backoff.
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+type pingServer struct {
+	pingv1connect.UnimplementedPingServiceHandler
+
+	calls *atomic.Int32
+}
+
+func (s pingServer) Ping(_ context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+	if s.calls != nil && s.calls.Add(1) == 1 {
+		err := connect.NewError(connect.CodeUnavailable, errors.New("busy"))
+		return nil, errdetails.AddRetryInfo(err, 50*time.Millisecond)
+	}
+	if request.Msg.GetNumber() < 0 {
+		err := errdetails.NewBadRequestError(errdetails.NewFieldViolation("number", "must be positive"))
+		errdetails.AddErrorInfo(err, "NEGATIVE_NUMBER", "ping.example.com", map[string]string{"number": "-1"})
+		return nil, errdetails.AddRetryInfo(err, 2*time.Second)
+	}
+	return connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber()}), nil
+}
@@ -36,6 +36,8 @@ var (
 	// errNotModified signals Connect-protocol responses to GET requests to use the
 	// 304 Not Modified HTTP error code.
 	errNotModified = errors.New("not modified")
+	// errNotModifiedClient wraps ErrNotModified for use client-side.
+	errNotModifiedClient = fmt.Errorf("HTTP 304: %w", errNotModified)
 )
 
 // An ErrorDetail is a self-describing Protobuf message attached to an [*Error].
@@ -45,10 +47,13 @@ var (
 // parameters to the client.
 //
 // The [google.golang.org/genproto/googleapis/rpc/errdetails] package contains a
-// variety of Protobuf messages commonly used as error details.
+// variety of Protobuf messages commonly used as error details. This module's
+// errdetails package has typed constructors and extractors for the same
+// messages.
 type ErrorDetail struct {
-	pbAny   *anypb.Any
-	pbInner proto.Message // if nil, must be extracted from pbAny
+	pbAny    *anypb.Any
+	pbInner  proto.Message // if nil, must be extracted from pbAny
+	wireJSON string        // preserve human-readable JSON
 }
 
 // NewErrorDetail constructs a new error detail. If msg is an *[anypb.Any] then
@@ -170,7 +175,7 @@ func IsWireError(err error) bool {
 // NewNotModifiedError indicates that the requested resource hasn't changed. It
 // should be used only when handlers wish to respond to conditional HTTP GET
 // requests with a 304 Not Modified. In all other circumstances, including all
-// RPCs using gRPC, it's equivalent to sending an
+// RPCs using the gRPC or gRPC-Web protocols, it's equivalent to sending an
 // error with [CodeUnknown]. The supplied headers should include Etag,
 // Cache-Control, or any other headers required by [RFC 9110 § 15.4.5].
 //
@@ -15,6 +15,8 @@
 package scalpel
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 )
@@ -24,7 +26,10 @@ type protocolType uint8
 
 const (
 	unknownProtocol protocolType = iota
+	connectUnaryProtocol
+	connectStreamProtocol
 	grpcProtocol
+	grpcWebProtocol
 )
 
 // An ErrorWriter writes errors to an [http.ResponseWriter] in the format
@@ -41,7 +46,7 @@ type ErrorWriter struct {
 
 // NewErrorWriter constructs an ErrorWriter. Handler options may be passed to
 // configure the error writer behaviour to match the handlers.
-// [WithRequiredConnectProtocolHeader] will assert that Connect protocol
+// [WithRequireConnectProtocolHeader] will assert that Connect protocol
 // requests include the version header allowing the error writer to correctly
 // classify the request.
 // Options supplied via [WithConditionalHandlerOptions] are ignored.
@@ -58,9 +63,31 @@ func NewErrorWriter(opts ...HandlerOption) *ErrorWriter {
 func (w *ErrorWriter) classifyRequest(request *http.Request) protocolType {
 	ctype := canonicalizeContentType(getHeaderCanonical(request.Header, headerContentType))
 	isPost := request.Method == http.MethodPost
+	isGet := request.Method == http.MethodGet
 	switch {
 	case isPost && (ctype == grpcContentTypeDefault || strings.HasPrefix(ctype, grpcContentTypePrefix)):
 		return grpcProtocol
+	case isPost && (ctype == grpcWebContentTypeDefault || strings.HasPrefix(ctype, grpcWebContentTypePrefix)):
+		return grpcWebProtocol
+	case isPost && grpcWebIsTextContentType(ctype):
+		return grpcWebProtocol
+	case isPost && strings.HasPrefix(ctype, connectStreamingContentTypePrefix):
+		// Streaming ignores the requireConnectProtocolHeader option as the
+		// Content-Type is enough to determine the protocol.
+		if err := connectCheckProtocolVersion(request, false /* required */); err != nil {
+			return unknownProtocol
+		}
+		return connectStreamProtocol
+	case isPost && strings.HasPrefix(ctype, connectUnaryContentTypePrefix):
+		if err := connectCheckProtocolVersion(request, w.requireConnectProtocolHeader); err != nil {
+			return unknownProtocol
+		}
+		return connectUnaryProtocol
+	case isGet:
+		if err := connectCheckProtocolVersion(request, w.requireConnectProtocolHeader); err != nil {
+			return unknownProtocol
+		}
+		return connectUnaryProtocol
 	default:
 		return unknownProtocol
 	}
@@ -81,14 +108,52 @@ func (w *ErrorWriter) IsSupported(request *http.Request) bool {
 func (w *ErrorWriter) Write(response http.ResponseWriter, request *http.Request, err error) error {
 	ctype := canonicalizeContentType(getHeaderCanonical(request.Header, headerContentType))
 	switch protocolType := w.classifyRequest(request); protocolType {
+	case connectStreamProtocol:
+		setHeaderCanonical(response.Header(), headerContentType, ctype)
+		return w.writeConnectStreaming(response, err)
 	case grpcProtocol:
 		setHeaderCanonical(response.Header(), headerContentType, ctype)
 		return w.writeGRPC(response, err)
-	case unknownProtocol:
+	case grpcWebProtocol:
+		setHeaderCanonical(response.Header(), headerContentType, ctype)
+		return w.writeGRPCWeb(response, err)
+	case unknownProtocol, connectUnaryProtocol:
 		fallthrough
 	default:
-		return nil
+		// Unary errors are always JSON. Unknown protocols are treated as unary
+		// because they are likely to be Connect clients and will still be able to
+		// parse the error as it's in a human-readable format.
+		setHeaderCanonical(response.Header(), headerContentType, connectUnaryContentTypeJSON)
+		return w.writeConnectUnary(response, err)
+	}
+}
+
+func (w *ErrorWriter) writeConnectUnary(response http.ResponseWriter, err error) error {
+	if connectErr, ok := asError(err); ok && !connectErr.wireErr {
+		mergeNonProtocolHeaders(response.Header(), connectErr.meta)
+	}
+	response.WriteHeader(connectCodeToHTTP(CodeOf(err)))
+	data, marshalErr := json.Marshal(newConnectWireError(err))
+	if marshalErr != nil {
+		return fmt.Errorf("marshal error: %w", marshalErr)
+	}
+	_, writeErr := response.Write(data)
+	return writeErr
+}
+
+func (w *ErrorWriter) writeConnectStreaming(response http.ResponseWriter, err error) error {
+	response.WriteHeader(http.StatusOK)
+	marshaler := &connectStreamingMarshaler{
+		envelopeWriter: envelopeWriter{
+			sender:     writeSender{writer: response},
+			bufferPool: w.bufferPool,
+		},
 	}
+	// MarshalEndStream returns *Error: check return value to avoid typed nils.
+	if marshalErr := marshaler.MarshalEndStream(err, make(http.Header)); marshalErr != nil {
+		return marshalErr
+	}
+	return nil
 }
 
 func (w *ErrorWriter) writeGRPC(response http.ResponseWriter, err error) error {
@@ -106,3 +171,11 @@ func (w *ErrorWriter) writeGRPC(response http.ResponseWriter, err error) error {
 	mergeHeaders(response.Header(), trailers)
 	return nil
 }
+
+func (w *ErrorWriter) writeGRPCWeb(response http.ResponseWriter, err error) error {
+	// This is a trailers-only response. To match the behavior of Envoy and
+	// protocol_grpc.go, put the trailers in the HTTP headers.
+	grpcErrorToTrailer(response.Header(), w.protobuf, err)
+	response.WriteHeader(http.StatusOK)
+	return nil
+}
@@ -24,9 +24,49 @@ import (
 
 func TestErrorWriter(t *testing.T) {
 	t.Parallel()
+	t.Run("RequireConnectProtocolHeader", func(t *testing.T) {
+		t.Parallel()
+		writer := NewErrorWriter(WithRequireConnectProtocolHeader())
+		t.Run("Unary", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", connectUnaryContentTypePrefix+codecNameJSON)
+			assert.False(t, writer.IsSupported(req))
+			req.Header.Set(connectHeaderProtocolVersion, connectProtocolVersion)
+			assert.True(t, writer.IsSupported(req))
+		})
+		t.Run("UnaryGET", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			assert.False(t, writer.IsSupported(req))
+			query := req.URL.Query()
+			query.Set(connectUnaryConnectQueryParameter, connectUnaryConnectQueryValue)
+			req.URL.RawQuery = query.Encode()
+			assert.True(t, writer.IsSupported(req))
+		})
+		t.Run("Stream", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", connectStreamingContentTypePrefix+codecNameJSON)
+			assert.True(t, writer.IsSupported(req)) // ignores WithRequireConnectProtocolHeader
+			req.Header.Set(connectHeaderProtocolVersion, connectProtocolVersion)
+			assert.True(t, writer.IsSupported(req))
+		})
+	})
 	t.Run("Protocols", func(t *testing.T) {
 		t.Parallel()
 		writer := NewErrorWriter() // All supported by default
+		t.Run("ConnectUnary", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", connectUnaryContentTypePrefix+codecNameJSON)
+			assert.True(t, writer.IsSupported(req))
+		})
+		t.Run("ConnectUnaryGET", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			assert.True(t, writer.IsSupported(req))
+		})
+		t.Run("ConnectStream", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", connectStreamingContentTypePrefix+codecNameJSON)
+			assert.True(t, writer.IsSupported(req))
+		})
 		t.Run("GRPC", func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
 			req.Header.Set("Content-Type", grpcContentTypeDefault)
@@ -34,6 +74,13 @@ func TestErrorWriter(t *testing.T) {
 			req.Header.Set("Content-Type", grpcContentTypePrefix+"json")
 			assert.True(t, writer.IsSupported(req))
 		})
+		t.Run("GRPCWeb", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", grpcWebContentTypeDefault)
+			assert.True(t, writer.IsSupported(req))
+			req.Header.Set("Content-Type", grpcWebContentTypePrefix+"json")
+			assert.True(t, writer.IsSupported(req))
+		})
 	})
 	t.Run("UnknownCodec", func(t *testing.T) {
 		// An Unknown codec should return supported as the protocol is known and
@@ -42,10 +89,25 @@ func TestErrorWriter(t *testing.T) {
 		t.Parallel()
 		writer := NewErrorWriter()
 		unknownCodec := "invalid"
+		t.Run("ConnectUnary", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", connectUnaryContentTypePrefix+unknownCodec)
+			assert.True(t, writer.IsSupported(req))
+		})
+		t.Run("ConnectStream", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", connectStreamingContentTypePrefix+unknownCodec)
+			assert.True(t, writer.IsSupported(req))
+		})
 		t.Run("GRPC", func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
 			req.Header.Set("Content-Type", grpcContentTypePrefix+unknownCodec)
 			assert.True(t, writer.IsSupported(req))
 		})
+		t.Run("GRPCWeb", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+			req.Header.Set("Content-Type", grpcWebContentTypePrefix+unknownCodec)
+			assert.True(t, writer.IsSupported(req))
+		})
 	})
 }
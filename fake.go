@@ -0,0 +1,513 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// FakeCall is a call recorded by a generated fake service.
+type FakeCall struct {
+	// Procedure is the fully-qualified name of the RPC, for example
+	// "/acme.foo.v1.FooService/Bar".
+	Procedure string
+	// Request is the request message of unary and server streaming calls. It's
+	// nil for client and bidirectional streaming calls.
+	Request any
+}
+
+// FakeRecorder records the calls made to a generated fake service. Generated
+// fakes hold one in their Recorder field. It's safe to use concurrently.
+//
+// To generate fakes, run protoc-gen-scalpel-go with the "mocks" option.
+type FakeRecorder struct {
+	mu    sync.Mutex
+	calls []FakeCall
+}
+
+// Record appends a call. Generated fakes call it at the start of each method.
+func (r *FakeRecorder) Record(procedure string, request any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, FakeCall{Procedure: procedure, Request: request})
+}
+
+// Calls returns the recorded calls, in the order they were made.
+func (r *FakeRecorder) Calls() []FakeCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]FakeCall(nil), r.calls...)
+}
+
+// CallsTo returns the recorded calls to a procedure, in the order they were
+// made.
+func (r *FakeRecorder) CallsTo(procedure string) []FakeCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls []FakeCall
+	for _, call := range r.calls {
+		if call.Procedure == procedure {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset discards the recorded calls.
+func (r *FakeRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+// CallFakeUnary calls a unary handler function in memory, without a
+// transport. The function runs as though it were wrapped by
+// [NewUnaryHandler], and the caller sees its results as though they came from
+// [Client.CallUnary]: messages are copied rather than shared, headers and
+// trailers are propagated, and errors are returned as wire errors.
+//
+// It's used by the fake clients that protoc-gen-scalpel-go generates with the
+// "mocks" option.
+func CallFakeUnary[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	request *Request[Req],
+	unary func(context.Context, *Request[Req]) (*Response[Res], error),
+) (*Response[Res], error) {
+	conn := newFakeClientConn(ctx, NewUnaryHandler(procedure, unary))
+	prepareFakeRequest(conn, request)
+	return callFakeUnary[Res](conn, request.Msg)
+}
+
+// CallFakeUnarySimple is like [CallFakeUnary], but uses the simple function
+// signatures. Headers and trailers are propagated through the [CallInfo] in
+// the context, as they are by [NewClientContext].
+func CallFakeUnarySimple[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	request *Req,
+	unary func(context.Context, *Req) (*Res, error),
+) (*Res, error) {
+	conn := newFakeClientConn(ctx, NewUnaryHandlerSimple(procedure, unary))
+	response, err := callFakeUnary[Res](conn, request)
+	if err != nil {
+		return nil, err
+	}
+	return response.Msg, nil
+}
+
+// CallFakeClientStream calls a client streaming handler function in memory,
+// without a transport. See [CallFakeUnary] for details.
+func CallFakeClientStream[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	implementation func(context.Context, *ClientStream[Req]) (*Response[Res], error),
+) *ClientStreamForClient[Req, Res] {
+	return &ClientStreamForClient[Req, Res]{
+		conn: newFakeClientConn(ctx, NewClientStreamHandler(procedure, implementation)),
+	}
+}
+
+// CallFakeClientStreamSimple is like [CallFakeClientStream], but uses the
+// simple function signatures.
+func CallFakeClientStreamSimple[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	implementation func(context.Context, *ClientStream[Req]) (*Res, error),
+) (*ClientStreamForClientSimple[Req, Res], error) {
+	stream := &ClientStreamForClientSimple[Req, Res]{
+		stream: &ClientStreamForClient[Req, Res]{
+			conn: newFakeClientConn(ctx, NewClientStreamHandlerSimple(procedure, implementation)),
+		},
+	}
+	if err := stream.Send(nil); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// CallFakeServerStream calls a server streaming handler function in memory,
+// without a transport. See [CallFakeUnary] for details.
+func CallFakeServerStream[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	request *Request[Req],
+	implementation func(context.Context, *Request[Req], *ServerStream[Res]) error,
+) (*ServerStreamForClient[Res], error) {
+	conn := newFakeClientConn(ctx, NewServerStreamHandler(procedure, implementation))
+	prepareFakeRequest(conn, request)
+	return callFakeServerStream[Res](conn, request.Msg)
+}
+
+// CallFakeServerStreamSimple is like [CallFakeServerStream], but uses the
+// simple function signatures.
+func CallFakeServerStreamSimple[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	request *Req,
+	implementation func(context.Context, *Req, *ServerStream[Res]) error,
+) (*ServerStreamForClient[Res], error) {
+	conn := newFakeClientConn(ctx, NewServerStreamHandlerSimple(procedure, implementation))
+	return callFakeServerStream[Res](conn, request)
+}
+
+// CallFakeBidiStream calls a bidirectional streaming handler function in
+// memory, without a transport. See [CallFakeUnary] for details.
+func CallFakeBidiStream[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	implementation func(context.Context, *BidiStream[Req, Res]) error,
+) *BidiStreamForClient[Req, Res] {
+	return &BidiStreamForClient[Req, Res]{
+		conn: newFakeClientConn(ctx, NewBidiStreamHandler(procedure, implementation)),
+	}
+}
+
+// CallFakeBidiStreamSimple is like [CallFakeBidiStream], but uses the simple
+// function signatures.
+func CallFakeBidiStreamSimple[Req, Res any](
+	ctx context.Context,
+	procedure string,
+	implementation func(context.Context, *BidiStream[Req, Res]) error,
+) (*BidiStreamForClientSimple[Req, Res], error) {
+	stream := &BidiStreamForClientSimple[Req, Res]{
+		stream: &BidiStreamForClient[Req, Res]{
+			conn: newFakeClientConn(ctx, NewBidiStreamHandler(procedure, implementation)),
+		},
+	}
+	if err := stream.Send(nil); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func prepareFakeRequest[Req any](conn StreamingClientConn, request *Request[Req]) {
+	request.spec = conn.Spec()
+	request.peer = conn.Peer()
+	request.method = http.MethodPost
+	mergeHeaders(conn.RequestHeader(), request.header)
+}
+
+func callFakeUnary[Res any](conn StreamingClientConn, msg any) (*Response[Res], error) {
+	// As with real clients, Send returns io.EOF if the handler has already
+	// failed, and Receive returns the handler's error.
+	if err := conn.Send(msg); err != nil && !errors.Is(err, io.EOF) {
+		_ = conn.CloseRequest()
+		_ = conn.CloseResponse()
+		return nil, err
+	}
+	if err := conn.CloseRequest(); err != nil {
+		_ = conn.CloseResponse()
+		return nil, err
+	}
+	response, err := receiveUnaryResponse[Res](conn, maybeInitializer{})
+	if err != nil {
+		_ = conn.CloseResponse()
+		return nil, err
+	}
+	return response, conn.CloseResponse()
+}
+
+func callFakeServerStream[Res any](conn StreamingClientConn, msg any) (*ServerStreamForClient[Res], error) {
+	if err := conn.Send(msg); err != nil && !errors.Is(err, io.EOF) {
+		_ = conn.CloseRequest()
+		_ = conn.CloseResponse()
+		return nil, err
+	}
+	if err := conn.CloseRequest(); err != nil {
+		return nil, err
+	}
+	return &ServerStreamForClient[Res]{conn: conn}, nil
+}
+
+// fakeClientConn is a StreamingClientConn that runs a handler's
+// implementation in a goroutine, exchanging messages over channels. The
+// handler starts when the client first sends or receives, so request headers
+// set before then are visible to it.
+type fakeClientConn struct {
+	ctx            context.Context //nolint:containedctx
+	handlerCtx     context.Context //nolint:containedctx
+	cancel         context.CancelFunc
+	spec           Spec
+	implementation StreamingHandlerFunc
+	requestHeader  http.Header
+	handler        fakeHandlerConn
+
+	startOnce        sync.Once
+	requests         chan any
+	requestsClosed   chan struct{}
+	closeRequestOnce sync.Once
+	responses        chan any
+
+	// Written by the handler's goroutine before closing headerSent and done.
+	headerSent      chan struct{}
+	responseHeader  http.Header
+	done            chan struct{}
+	responseTrailer http.Header
+	err             error
+}
+
+func newFakeClientConn(ctx context.Context, handler *Handler) *fakeClientConn {
+	spec := handler.spec
+	spec.IsClient = true
+	handlerCtx, cancel := context.WithCancel(ctx)
+	conn := &fakeClientConn{
+		ctx:            ctx,
+		handlerCtx:     handlerCtx,
+		cancel:         cancel,
+		spec:           spec,
+		implementation: handler.implementation,
+		requestHeader:  make(http.Header),
+		requests:       make(chan any),
+		requestsClosed: make(chan struct{}),
+		responses:      make(chan any),
+		headerSent:     make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	conn.handler = fakeHandlerConn{
+		client:          conn,
+		spec:            handler.spec,
+		responseHeader:  make(http.Header),
+		responseTrailer: make(http.Header),
+	}
+	if callInfo, ok := clientCallInfoForContext(ctx); ok {
+		callInfo.spec = conn.spec
+		callInfo.peer = conn.Peer()
+		callInfo.method = http.MethodPost
+		callInfo.attempts.Store(1)
+		callInfo.deadline, callInfo.hasDeadline = ctx.Deadline()
+		callInfo.responseSource = conn
+		mergeHeaders(conn.requestHeader, callInfo.RequestHeader())
+	}
+	return conn
+}
+
+func (c *fakeClientConn) Spec() Spec {
+	return c.spec
+}
+
+func (c *fakeClientConn) Peer() Peer {
+	return Peer{}
+}
+
+func (c *fakeClientConn) RequestHeader() http.Header {
+	return c.requestHeader
+}
+
+func (c *fakeClientConn) Send(msg any) error {
+	c.start()
+	if msg == nil {
+		return nil
+	}
+	select {
+	case <-c.requestsClosed:
+		return errorf(CodeInternal, "send after CloseRequest")
+	default:
+	}
+	msg = cloneFakeMessage(msg)
+	select {
+	case c.requests <- msg:
+		return nil
+	case <-c.done:
+		return io.EOF
+	case <-c.ctx.Done():
+		return wrapIfContextError(c.ctx.Err())
+	}
+}
+
+func (c *fakeClientConn) CloseRequest() error {
+	c.start()
+	c.closeRequestOnce.Do(func() { close(c.requestsClosed) })
+	return nil
+}
+
+func (c *fakeClientConn) Receive(msg any) error {
+	c.start()
+	// The handler's Send blocks until we've received the message, so once
+	// the handler is done, no messages are left in flight.
+	select {
+	case response := <-c.responses:
+		return copyFakeMessage(msg, response)
+	case <-c.done:
+		if c.err != nil {
+			return c.err
+		}
+		return io.EOF
+	case <-c.ctx.Done():
+		return wrapIfContextError(c.ctx.Err())
+	}
+}
+
+func (c *fakeClientConn) ResponseHeader() http.Header {
+	c.start()
+	select {
+	case <-c.headerSent:
+		return c.responseHeader
+	case <-c.ctx.Done():
+		return make(http.Header)
+	}
+}
+
+func (c *fakeClientConn) ResponseTrailer() http.Header {
+	select {
+	case <-c.done:
+		return c.responseTrailer
+	default:
+		return make(http.Header)
+	}
+}
+
+func (c *fakeClientConn) CloseResponse() error {
+	// As with a real transport, the handler's context is canceled if the
+	// client goes away before the handler finishes.
+	c.cancel()
+	return nil
+}
+
+func (c *fakeClientConn) start() {
+	c.startOnce.Do(func() {
+		c.handler.requestHeader = c.requestHeader.Clone()
+		go c.run()
+	})
+}
+
+func (c *fakeClientConn) run() {
+	defer c.cancel()
+	err := c.implementation(c.handlerCtx, &c.handler)
+	c.handler.sendHeader()
+	trailer := c.handler.responseTrailer.Clone()
+	if err != nil {
+		err = newFakeWireError(err, c.responseHeader, trailer)
+	}
+	c.responseTrailer = trailer
+	c.err = err
+	close(c.done)
+}
+
+// fakeHandlerConn is the handler's view of a fakeClientConn.
+type fakeHandlerConn struct {
+	client          *fakeClientConn
+	spec            Spec
+	requestHeader   http.Header
+	responseHeader  http.Header
+	responseTrailer http.Header
+	headerOnce      sync.Once
+}
+
+func (h *fakeHandlerConn) Spec() Spec {
+	return h.spec
+}
+
+func (h *fakeHandlerConn) Peer() Peer {
+	return Peer{}
+}
+
+func (h *fakeHandlerConn) RequestHeader() http.Header {
+	return h.requestHeader
+}
+
+func (h *fakeHandlerConn) Receive(msg any) error {
+	// The client's Send blocks until we've received the message, so once the
+	// client has closed the request, no messages are left in flight.
+	select {
+	case request := <-h.client.requests:
+		return copyFakeMessage(msg, request)
+	case <-h.client.requestsClosed:
+		return io.EOF
+	case <-h.client.handlerCtx.Done():
+		return wrapIfContextError(h.client.handlerCtx.Err())
+	}
+}
+
+func (h *fakeHandlerConn) ResponseHeader() http.Header {
+	return h.responseHeader
+}
+
+func (h *fakeHandlerConn) ResponseTrailer() http.Header {
+	return h.responseTrailer
+}
+
+func (h *fakeHandlerConn) Send(msg any) error {
+	h.sendHeader()
+	if msg == nil {
+		return nil
+	}
+	msg = cloneFakeMessage(msg)
+	select {
+	case h.client.responses <- msg:
+		return nil
+	case <-h.client.handlerCtx.Done():
+		return wrapIfContextError(h.client.handlerCtx.Err())
+	}
+}
+
+func (h *fakeHandlerConn) sendHeader() {
+	h.headerOnce.Do(func() {
+		h.client.responseHeader = h.responseHeader.Clone()
+		close(h.client.headerSent)
+	})
+}
+
+// newFakeWireError converts a handler's error into the error a client would
+// receive over the network.
+func newFakeWireError(err error, header, trailer http.Header) *Error {
+	connectErr, _ := asError(wrapIfUncoded(err))
+	if !connectErr.wireErr {
+		mergeNonProtocolHeaders(trailer, connectErr.meta)
+	}
+	var underlying error
+	if message := connectErr.Message(); message != "" {
+		underlying = errors.New(message)
+	}
+	wireErr := NewWireError(connectErr.Code(), underlying)
+	wireErr.details = connectErr.details
+	wireErr.meta = header.Clone()
+	mergeHeaders(wireErr.meta, trailer)
+	return wireErr
+}
+
+// cloneFakeMessage copies a message as it's sent, so that senders may reuse
+// or modify their messages.
+func cloneFakeMessage(msg any) any {
+	if protoMsg, ok := msg.(proto.Message); ok {
+		return proto.Clone(protoMsg)
+	}
+	return msg
+}
+
+// copyFakeMessage copies a sent message into the receiver's message.
+func copyFakeMessage(dst, src any) error {
+	if reflect.TypeOf(dst) != reflect.TypeOf(src) {
+		return errorf(CodeInternal, "can't receive %T into %T", src, dst)
+	}
+	if dstMsg, ok := dst.(proto.Message); ok {
+		proto.Reset(dstMsg)
+		proto.Merge(dstMsg, src.(proto.Message)) //nolint:forcetypeassert // types match
+		return nil
+	}
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Pointer || dstValue.IsNil() {
+		return errorf(CodeInternal, "can't receive into %T", dst)
+	}
+	dstValue.Elem().Set(reflect.ValueOf(src).Elem())
+	return nil
+}
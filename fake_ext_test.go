@@ -0,0 +1,266 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	pingv1connectsimple "github.com/agentio/scalpel/internal/gen/simple/connect/ping/v1/pingv1connect"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestFakeService(t *testing.T) {
+	t.Parallel()
+	t.Run("unary", func(t *testing.T) {
+		t.Parallel()
+		var received *pingv1.PingRequest
+		fake := &pingv1connect.FakePingService{
+			PingFunc: func(_ context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+				received = request.Msg
+				assert.Equal(t, request.Spec().Procedure, pingv1connect.PingServicePingProcedure)
+				assert.False(t, request.Spec().IsClient)
+				response := connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber()})
+				response.Header().Set("Echo", request.Header().Get("Echo"))
+				response.Trailer().Set("Result", "done")
+				return response, nil
+			},
+		}
+		request := connect.NewRequest(&pingv1.PingRequest{Number: 42})
+		request.Header().Set("Echo", "hello")
+		response, err := pingv1connect.NewFakePingServiceClient(fake).Ping(t.Context(), request)
+		assert.Nil(t, err)
+		assert.Equal(t, response.Msg.GetNumber(), 42)
+		assert.Equal(t, response.Header().Get("Echo"), "hello")
+		assert.Equal(t, response.Trailer().Get("Result"), "done")
+		assert.True(t, request.Spec().IsClient)
+		// Messages are copied, as they would be over the network.
+		assert.True(t, received != request.Msg)
+
+		calls := fake.Recorder.Calls()
+		assert.Equal(t, len(calls), 1)
+		assert.Equal(t, calls[0].Procedure, pingv1connect.PingServicePingProcedure)
+		assert.Equal(t, calls[0].Request.(*pingv1.PingRequest).GetNumber(), 42) //nolint:forcetypeassert
+		fake.Recorder.Reset()
+		assert.Zero(t, len(fake.Recorder.Calls()))
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		fake := &pingv1connect.FakePingService{
+			FailFunc: func(context.Context, *connect.Request[pingv1.FailRequest]) (*connect.Response[pingv1.FailResponse], error) {
+				err := connect.NewError(connect.CodeResourceExhausted, errors.New("oh no"))
+				err.Meta().Set("Reason", "quota")
+				detail, detailErr := connect.NewErrorDetail(wrapperspb.String("details"))
+				assert.Nil(t, detailErr)
+				err.AddDetail(detail)
+				return nil, err
+			},
+		}
+		client := pingv1connect.NewFakePingServiceClient(fake)
+		_, err := client.Fail(t.Context(), connect.NewRequest(&pingv1.FailRequest{}))
+		var connectErr *connect.Error
+		assert.True(t, errors.As(err, &connectErr))
+		assert.Equal(t, connectErr.Code(), connect.CodeResourceExhausted)
+		assert.Equal(t, connectErr.Message(), "oh no")
+		assert.Equal(t, connectErr.Meta().Get("Reason"), "quota")
+		assert.Equal(t, len(connectErr.Details()), 1)
+		assert.True(t, connect.IsWireError(err))
+
+		// Unset functions return CodeUnimplemented.
+		_, err = client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assert.Equal(t, connect.CodeOf(err), connect.CodeUnimplemented)
+		stream, err := client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{Number: 1}))
+		assert.Nil(t, err)
+		assert.False(t, stream.Receive())
+		assert.Equal(t, connect.CodeOf(stream.Err()), connect.CodeUnimplemented)
+		assert.Equal(t, len(fake.Recorder.Calls()), 3)
+	})
+	t.Run("client_stream", func(t *testing.T) {
+		t.Parallel()
+		fake := &pingv1connect.FakePingService{
+			SumFunc: func(_ context.Context, stream *connect.ClientStream[pingv1.SumRequest]) (*connect.Response[pingv1.SumResponse], error) {
+				var sum int64
+				for stream.Receive() {
+					sum += stream.Msg().GetNumber()
+				}
+				if stream.Err() != nil {
+					return nil, stream.Err()
+				}
+				response := connect.NewResponse(&pingv1.SumResponse{Sum: sum})
+				response.Header().Set("Echo", stream.RequestHeader().Get("Echo"))
+				return response, nil
+			},
+		}
+		stream := pingv1connect.NewFakePingServiceClient(fake).Sum(t.Context())
+		stream.RequestHeader().Set("Echo", "hello")
+		for i := range int64(3) {
+			assert.Nil(t, stream.Send(&pingv1.SumRequest{Number: i + 1}))
+		}
+		response, err := stream.CloseAndReceive()
+		assert.Nil(t, err)
+		assert.Equal(t, response.Msg.GetSum(), 6)
+		assert.Equal(t, response.Header().Get("Echo"), "hello")
+		calls := fake.Recorder.CallsTo(pingv1connect.PingServiceSumProcedure)
+		assert.Equal(t, len(calls), 1)
+		assert.Nil(t, calls[0].Request)
+	})
+	t.Run("server_stream", func(t *testing.T) {
+		t.Parallel()
+		canceled := make(chan struct{})
+		fake := &pingv1connect.FakePingService{
+			CountUpFunc: func(ctx context.Context, request *connect.Request[pingv1.CountUpRequest], stream *connect.ServerStream[pingv1.CountUpResponse]) error {
+				if request.Msg.GetNumber() == 0 {
+					// Wait for the client to give up.
+					<-ctx.Done()
+					close(canceled)
+					return ctx.Err()
+				}
+				stream.ResponseHeader().Set("Echo", request.Header().Get("Echo"))
+				for i := range request.Msg.GetNumber() {
+					if err := stream.Send(&pingv1.CountUpResponse{Number: i + 1}); err != nil {
+						return err
+					}
+				}
+				stream.ResponseTrailer().Set("Count", "done")
+				return nil
+			},
+		}
+		client := pingv1connect.NewFakePingServiceClient(fake)
+		request := connect.NewRequest(&pingv1.CountUpRequest{Number: 3})
+		request.Header().Set("Echo", "hello")
+		stream, err := client.CountUp(t.Context(), request)
+		assert.Nil(t, err)
+		var numbers []int64
+		for stream.Receive() {
+			numbers = append(numbers, stream.Msg().GetNumber())
+		}
+		assert.Nil(t, stream.Err())
+		assert.Equal(t, numbers, []int64{1, 2, 3})
+		assert.Equal(t, stream.ResponseHeader().Get("Echo"), "hello")
+		assert.Equal(t, stream.ResponseTrailer().Get("Count"), "done")
+		assert.Nil(t, stream.Close())
+
+		// Closing the stream early cancels the handler.
+		stream, err = client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{}))
+		assert.Nil(t, err)
+		assert.Nil(t, stream.Close())
+		<-canceled
+	})
+	t.Run("bidi_stream", func(t *testing.T) {
+		t.Parallel()
+		fake := &pingv1connect.FakePingService{CumSumFunc: cumSum}
+		stream := pingv1connect.NewFakePingServiceClient(fake).CumSum(t.Context())
+		var sums []int64
+		for i := range int64(3) {
+			assert.Nil(t, stream.Send(&pingv1.CumSumRequest{Number: i + 1}))
+			response, err := stream.Receive()
+			assert.Nil(t, err)
+			sums = append(sums, response.GetSum())
+		}
+		assert.Nil(t, stream.CloseRequest())
+		_, err := stream.Receive()
+		assert.True(t, errors.Is(err, io.EOF))
+		assert.Nil(t, stream.CloseResponse())
+		assert.Equal(t, sums, []int64{1, 3, 6})
+	})
+	t.Run("simple", func(t *testing.T) {
+		t.Parallel()
+		fake := &pingv1connectsimple.FakePingService{
+			PingFunc: func(ctx context.Context, request *pingv1.PingRequest) (*pingv1.PingResponse, error) {
+				callInfo, ok := connect.CallInfoForHandlerContext(ctx)
+				assert.True(t, ok)
+				callInfo.ResponseHeader().Set("Echo", callInfo.RequestHeader().Get("Echo"))
+				return &pingv1.PingResponse{Number: request.GetNumber()}, nil
+			},
+			SumFunc: func(_ context.Context, stream *connect.ClientStream[pingv1.SumRequest]) (*pingv1.SumResponse, error) {
+				var sum int64
+				for stream.Receive() {
+					sum += stream.Msg().GetNumber()
+				}
+				return &pingv1.SumResponse{Sum: sum}, stream.Err()
+			},
+			CountUpFunc: func(_ context.Context, request *pingv1.CountUpRequest, stream *connect.ServerStream[pingv1.CountUpResponse]) error {
+				for i := range request.GetNumber() {
+					if err := stream.Send(&pingv1.CountUpResponse{Number: i + 1}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			CumSumFunc: cumSum,
+		}
+		client := pingv1connectsimple.NewFakePingServiceClient(fake)
+
+		ctx, callInfo := connect.NewClientContext(t.Context())
+		callInfo.RequestHeader().Set("Echo", "hello")
+		response, err := client.Ping(ctx, &pingv1.PingRequest{Number: 42})
+		assert.Nil(t, err)
+		assert.Equal(t, response.GetNumber(), 42)
+		assert.Equal(t, callInfo.ResponseHeader().Get("Echo"), "hello")
+		assert.Equal(t, callInfo.Spec().Procedure, pingv1connectsimple.PingServicePingProcedure)
+
+		sumStream, err := client.Sum(t.Context())
+		assert.Nil(t, err)
+		assert.Nil(t, sumStream.Send(&pingv1.SumRequest{Number: 2}))
+		assert.Nil(t, sumStream.Send(&pingv1.SumRequest{Number: 3}))
+		sum, err := sumStream.CloseAndReceive()
+		assert.Nil(t, err)
+		assert.Equal(t, sum.GetSum(), 5)
+
+		countStream, err := client.CountUp(t.Context(), &pingv1.CountUpRequest{Number: 2})
+		assert.Nil(t, err)
+		var count int
+		for countStream.Receive() {
+			count++
+		}
+		assert.Nil(t, countStream.Err())
+		assert.Equal(t, count, 2)
+
+		cumSumStream, err := client.CumSum(t.Context())
+		assert.Nil(t, err)
+		assert.Nil(t, cumSumStream.Send(&pingv1.CumSumRequest{Number: 5}))
+		cumSumResponse, err := cumSumStream.Receive()
+		assert.Nil(t, err)
+		assert.Equal(t, cumSumResponse.GetSum(), 5)
+		assert.Nil(t, cumSumStream.CloseRequest())
+		assert.Nil(t, cumSumStream.CloseResponse())
+
+		calls := fake.Recorder.Calls()
+		assert.Equal(t, len(calls), 4)
+		assert.Equal(t, calls[0].Request.(*pingv1.PingRequest).GetNumber(), 42) //nolint:forcetypeassert
+	})
+}
+
+func cumSum(_ context.Context, stream *connect.BidiStream[pingv1.CumSumRequest, pingv1.CumSumResponse]) error {
+	var sum int64
+	for {
+		request, err := stream.Receive()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		sum += request.GetNumber()
+		if err := stream.Send(&pingv1.CumSumResponse{Sum: sum}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+)
+
+// A Frame is a single enveloped message from the body of a gRPC, gRPC-Web, or
+// Connect streaming RPC. Data holds the message exactly as it was sent, so
+// it's compressed if the compressed bit of Flags is set. The other bits are
+// protocol-specific: gRPC-Web and Connect use them to mark the frame that
+// ends the stream.
+//
+// Frames let proxies and other intermediaries handle RPCs without a codec,
+// and so without generated code for the services involved.
+type Frame struct {
+	Flags uint8
+	Data  []byte
+}
+
+// IsCompressed reports whether the frame's data is compressed.
+func (f Frame) IsCompressed() bool {
+	return f.Flags&flagEnvelopeCompressed != 0
+}
+
+// IsEndStream reports whether the frame ends the stream: that is, whether it
+// has any protocol-specific flags set.
+func (f Frame) IsEndStream() bool {
+	return f.Flags&^flagEnvelopeCompressed != 0
+}
+
+// A FrameReader reads frames from an RPC body. It doesn't decompress or
+// unmarshal them.
+type FrameReader struct {
+	reader envelopeReader
+}
+
+// NewFrameReader constructs a FrameReader. If readMaxBytes is positive,
+// frames with more data than that are discarded, and Read returns an error
+// with CodeResourceExhausted.
+func NewFrameReader(reader io.Reader, readMaxBytes int) *FrameReader {
+	return &FrameReader{reader: envelopeReader{
+		ctx:          context.Background(),
+		reader:       reader,
+		readMaxBytes: readMaxBytes,
+	}}
+}
+
+// Read reads the next frame. It returns io.EOF if the body ends cleanly
+// before the frame's first byte.
+func (r *FrameReader) Read() (Frame, error) {
+	env := &envelope{Data: &bytes.Buffer{}}
+	if err := r.reader.Read(env); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Frame{}, io.EOF
+		}
+		return Frame{}, err
+	}
+	return Frame{Flags: env.Flags, Data: env.Data.Bytes()}, nil
+}
+
+// A FrameWriter writes frames to an RPC body, byte for byte.
+type FrameWriter struct {
+	writer envelopeWriter
+}
+
+// NewFrameWriter constructs a FrameWriter.
+func NewFrameWriter(writer io.Writer) *FrameWriter {
+	return &FrameWriter{writer: envelopeWriter{
+		ctx:    context.Background(),
+		sender: writeSender{writer: writer},
+	}}
+}
+
+// Write writes a frame. It doesn't retain any references to the frame's
+// data.
+func (w *FrameWriter) Write(frame Frame) error {
+	if err := w.writer.Write(&envelope{
+		Data:  bytes.NewBuffer(frame.Data),
+		Flags: frame.Flags,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
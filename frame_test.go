@@ -0,0 +1,66 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/agentio/scalpel/internal/assert"
+)
+
+func TestFrame(t *testing.T) {
+	t.Parallel()
+	frames := []Frame{
+		{Data: []byte(`{"number": 42}`)},
+		{Flags: flagEnvelopeCompressed, Data: []byte("compressed")},
+		{Flags: connectFlagEnvelopeEndStream, Data: []byte("{}")},
+	}
+	body := &bytes.Buffer{}
+	writer := NewFrameWriter(body)
+	for _, frame := range frames {
+		assert.Nil(t, writer.Write(frame))
+	}
+	t.Run("round_trip", func(t *testing.T) {
+		t.Parallel()
+		reader := NewFrameReader(bytes.NewReader(body.Bytes()), 0)
+		for _, want := range frames {
+			got, err := reader.Read()
+			assert.Nil(t, err)
+			assert.Equal(t, got.Flags, want.Flags)
+			assert.Equal(t, got.Data, want.Data)
+		}
+		_, err := reader.Read()
+		assert.ErrorIs(t, err, io.EOF)
+		assert.False(t, frames[0].IsCompressed())
+		assert.True(t, frames[1].IsCompressed())
+		assert.False(t, frames[1].IsEndStream())
+		assert.True(t, frames[2].IsEndStream())
+	})
+	t.Run("read_max_bytes", func(t *testing.T) {
+		t.Parallel()
+		reader := NewFrameReader(bytes.NewReader(body.Bytes()), 4)
+		_, err := reader.Read()
+		assert.Equal(t, CodeOf(err), CodeResourceExhausted)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+		reader := NewFrameReader(bytes.NewReader(body.Bytes()[:8]), 0)
+		_, err := reader.Read()
+		assert.NotNil(t, err)
+		assert.False(t, err == io.EOF) //nolint:errorlint
+	})
+}
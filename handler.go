@@ -17,19 +17,27 @@ package scalpel
 import (
 	"context"
 	"net/http"
+	"runtime/debug"
+	"time"
 )
 
 // A Handler is the server-side implementation of a single RPC defined by a
 // service schema.
 //
-// By default, Handlers support the gRPC protocol with
-// the binary Protobuf codec.
+// By default, Handlers support the Connect, gRPC, and gRPC-Web protocols with
+// the binary Protobuf and JSON codecs. They support gzip compression using the
+// standard library's [compress/gzip].
 type Handler struct {
 	spec             Spec
 	implementation   StreamingHandlerFunc
 	protocolHandlers map[string][]protocolHandler // Method to protocol handlers
 	allowMethod      string                       // Allow header
 	acceptPost       string                       // Accept-Post header
+	maxTimeout       time.Duration
+	defaultTimeout   time.Duration
+	limiters         []*ConcurrencyLimiter
+	recoverPanic     func(context.Context, Spec, http.Header, any, []byte)
+	statsHandlers    []StatsHandler
 }
 
 // NewUnaryHandler constructs a [Handler] for a request-response procedure.
@@ -56,6 +64,9 @@ func NewUnaryHandler[Req, Res any](
 		return res, err
 	})
 	config := newHandlerConfig(procedure, StreamTypeUnary, options)
+	if interceptor := config.Interceptor; interceptor != nil {
+		untyped = interceptor.WrapUnary(untyped)
+	}
 	// Given a stream, how should we call the unary function?
 	implementation := func(ctx context.Context, conn StreamingHandlerConn) error {
 		request, err := receiveUnaryRequest[Req](conn, config.Initializer)
@@ -65,10 +76,11 @@ func NewUnaryHandler[Req, Res any](
 		// Add the request header to the context, and store the response header
 		// and trailer to propagate back to the caller.
 		info := &handlerCallInfo{
-			peer:          request.Peer(),
-			spec:          request.Spec(),
-			method:        request.HTTPMethod(),
-			requestHeader: request.Header(),
+			handlerDeadline: newHandlerDeadline(ctx),
+			peer:            request.Peer(),
+			spec:            request.Spec(),
+			method:          request.HTTPMethod(),
+			requestHeader:   request.Header(),
 		}
 		ctx = newHandlerContext(ctx, info)
 		response, err := untyped(ctx, request)
@@ -96,13 +108,22 @@ func NewUnaryHandler[Req, Res any](
 	}
 
 	protocolHandlers := config.newProtocolHandlers()
-	return &Handler{
+	handler := &Handler{
 		spec:             config.newSpec(),
 		implementation:   implementation,
 		protocolHandlers: mappedMethodHandlers(protocolHandlers),
 		allowMethod:      sortedAllowMethodValue(protocolHandlers),
 		acceptPost:       sortedAcceptPostValue(protocolHandlers),
+		maxTimeout:       config.MaxTimeout,
+		defaultTimeout:   config.DefaultTimeout,
+		limiters:         config.ConcurrencyLimiters,
+		recoverPanic:     config.RecoverPanic,
+		statsHandlers:    config.StatsHandlers,
+	}
+	if config.Transcoder != nil {
+		config.Transcoder.handle(handler, config.ReadMaxBytes)
 	}
+	return handler
 }
 
 // NewUnaryHandlerSimple constructs a [Handler] for a request-response procedure using the
@@ -143,7 +164,8 @@ func NewClientStreamHandler[Req, Res any](
 				initializer: config.Initializer,
 			}
 			ctx = newHandlerContext(ctx, &streamingHandlerCallInfo{
-				conn: conn,
+				handlerDeadline: newHandlerDeadline(ctx),
+				conn:            conn,
 			})
 			res, err := implementation(ctx, stream)
 			if err != nil {
@@ -199,7 +221,8 @@ func NewServerStreamHandler[Req, Res any](
 				return err
 			}
 			ctx = newHandlerContext(ctx, &streamingHandlerCallInfo{
-				conn: conn,
+				handlerDeadline: newHandlerDeadline(ctx),
+				conn:            conn,
 			})
 			return implementation(ctx, req, &ServerStream[Res]{conn: conn})
 		},
@@ -236,7 +259,8 @@ func NewBidiStreamHandler[Req, Res any](
 		config,
 		func(ctx context.Context, conn StreamingHandlerConn) error {
 			ctx = newHandlerContext(ctx, &streamingHandlerCallInfo{
-				conn: conn,
+				handlerDeadline: newHandlerDeadline(ctx),
+				conn:            conn,
 			})
 			return implementation(
 				ctx,
@@ -315,6 +339,14 @@ func (h *Handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Re
 	if cancel != nil {
 		defer cancel()
 	}
+	if timeoutErr == nil {
+		var limitCancel context.CancelFunc
+		ctx, limitCancel = h.limitTimeout(ctx, cancel != nil)
+		if limitCancel != nil {
+			defer limitCancel()
+		}
+	}
+	ctx, stats := newRPCStats(ctx, h.statsHandlers, h.spec)
 	connCloser, ok := protocolHandler.NewConn(
 		responseWriter,
 		request.WithContext(ctx),
@@ -324,34 +356,119 @@ func (h *Handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Re
 		// compression algorithm. Nothing further to do.
 		return
 	}
+	if stats != nil {
+		stats.begin()
+		stats.inHeader(request.Header)
+		connCloser = &statsHandlerConn{handlerConnCloser: connCloser, stats: stats}
+	}
 	if timeoutErr != nil {
 		_ = connCloser.Close(timeoutErr)
 		return
 	}
-	_ = connCloser.Close(h.implementation(ctx, connCloser))
+	_ = connCloser.Close(h.serve(ctx, connCloser))
+}
+
+// serve runs the implementation, subject to the handler's concurrency limits.
+// If the handler recovers from panics, a panic becomes a CodeInternal error so
+// that the protocol still ends the stream properly.
+func (h *Handler) serve(ctx context.Context, conn StreamingHandlerConn) (retErr error) {
+	if h.recoverPanic != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				// net/http checks for ErrAbortHandler with ==, so we should too.
+				if r == http.ErrAbortHandler { //nolint:errorlint,goerr113
+					panic(r) //nolint:forbidigo
+				}
+				h.recoverPanic(ctx, conn.Spec(), conn.RequestHeader(), r, debug.Stack())
+				retErr = errorf(CodeInternal, "handler panicked")
+			}
+		}()
+	}
+	if len(h.limiters) == 0 {
+		return h.implementation(ctx, conn)
+	}
+	release, err := acquireConcurrency(ctx, h.limiters)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	defer func() {
+		// Only unary latency says anything about load: streams last as long
+		// as the application needs.
+		var latency time.Duration
+		if h.spec.StreamType == StreamTypeUnary {
+			latency = time.Since(start)
+		}
+		release(latency)
+	}()
+	return h.implementation(ctx, conn)
+}
+
+// limitTimeout applies the handler's default and maximum timeouts to the
+// context returned by the protocol's SetTimeout. If the deadline is shortened
+// by the maximum timeout, the context records that it was clamped.
+func (h *Handler) limitTimeout(ctx context.Context, clientTimeout bool) (context.Context, context.CancelFunc) {
+	if h.maxTimeout <= 0 && (clientTimeout || h.defaultTimeout <= 0) {
+		return ctx, nil
+	}
+	now := time.Now()
+	deadline, ok := ctx.Deadline()
+	limited := false
+	if !clientTimeout && h.defaultTimeout > 0 {
+		if defaultDeadline := now.Add(h.defaultTimeout); !ok || defaultDeadline.Before(deadline) {
+			deadline, ok, limited = defaultDeadline, true, true
+		}
+	}
+	clamped := false
+	if h.maxTimeout > 0 {
+		if maxDeadline := now.Add(h.maxTimeout); !ok || maxDeadline.Before(deadline) {
+			deadline, limited, clamped = maxDeadline, true, true
+		}
+	}
+	if !limited {
+		return ctx, nil
+	}
+	if clamped {
+		ctx = context.WithValue(ctx, deadlineClampedContextKey{}, true)
+	}
+	return context.WithDeadline(ctx, deadline)
 }
 
 type handlerConfig struct {
+	CompressionPools             map[string]*compressionPool
+	CompressionNames             []string
 	Codecs                       map[string]Codec
+	CompressMinBytes             int
 	Procedure                    string
 	Schema                       any
+	IdempotencyLevel             IdempotencyLevel
 	Initializer                  maybeInitializer
+	Interceptor                  Interceptor
 	RequireConnectProtocolHeader bool
 	BufferPool                   *bufferPool
 	ReadMaxBytes                 int
 	SendMaxBytes                 int
 	StreamType                   StreamType
+	MaxTimeout                   time.Duration
+	DefaultTimeout               time.Duration
+	ConcurrencyLimiters          []*ConcurrencyLimiter
+	RecoverPanic                 func(context.Context, Spec, http.Header, any, []byte)
+	StatsHandlers                []StatsHandler
+	Transcoder                   *Transcoder
 }
 
 func newHandlerConfig(procedure string, streamType StreamType, options []HandlerOption) *handlerConfig {
 	protoPath := extractProtoPath(procedure)
 	config := handlerConfig{
-		Procedure:  protoPath,
-		Codecs:     make(map[string]Codec),
-		BufferPool: newBufferPool(),
-		StreamType: streamType,
+		Procedure:        protoPath,
+		CompressionPools: make(map[string]*compressionPool),
+		Codecs:           make(map[string]Codec),
+		BufferPool:       newBufferPool(),
+		StreamType:       streamType,
 	}
 	withProtoBinaryCodec().applyToHandler(&config)
+	withProtoJSONCodecs().applyToHandler(&config)
+	withGzip().applyToHandler(&config)
 	for _, opt := range options {
 		opt.applyToHandler(&config)
 	}
@@ -360,22 +477,31 @@ func newHandlerConfig(procedure string, streamType StreamType, options []Handler
 
 func (c *handlerConfig) newSpec() Spec {
 	return Spec{
-		Procedure:  c.Procedure,
-		Schema:     c.Schema,
-		StreamType: c.StreamType,
+		Procedure:        c.Procedure,
+		Schema:           c.Schema,
+		StreamType:       c.StreamType,
+		IdempotencyLevel: idempotencyLevel(c.IdempotencyLevel, c.Schema),
 	}
 }
 
 func (c *handlerConfig) newProtocolHandlers() []protocolHandler {
 	protocols := []protocol{
-		&protocolGRPC{},
+		&protocolConnect{},
+		&protocolGRPC{web: false},
+		&protocolGRPC{web: true},
 	}
 	handlers := make([]protocolHandler, 0, len(protocols))
 	codecs := newReadOnlyCodecs(c.Codecs)
+	compressors := newReadOnlyCompressionPools(
+		c.CompressionPools,
+		c.CompressionNames,
+	)
 	for _, protocol := range protocols {
 		handlers = append(handlers, protocol.NewHandler(&protocolHandlerParams{
 			Spec:                         c.newSpec(),
 			Codecs:                       codecs,
+			CompressionPools:             compressors,
+			CompressMinBytes:             c.CompressMinBytes,
 			BufferPool:                   c.BufferPool,
 			ReadMaxBytes:                 c.ReadMaxBytes,
 			SendMaxBytes:                 c.SendMaxBytes,
@@ -389,6 +515,9 @@ func newStreamHandler(
 	config *handlerConfig,
 	implementation StreamingHandlerFunc,
 ) *Handler {
+	if ic := config.Interceptor; ic != nil {
+		implementation = ic.WrapStreamingHandler(implementation)
+	}
 	protocolHandlers := config.newProtocolHandlers()
 	return &Handler{
 		spec:             config.newSpec(),
@@ -396,5 +525,10 @@ func newStreamHandler(
 		protocolHandlers: mappedMethodHandlers(protocolHandlers),
 		allowMethod:      sortedAllowMethodValue(protocolHandlers),
 		acceptPost:       sortedAcceptPostValue(protocolHandlers),
+		maxTimeout:       config.MaxTimeout,
+		defaultTimeout:   config.DefaultTimeout,
+		limiters:         config.ConcurrencyLimiters,
+		recoverPanic:     config.RecoverPanic,
+		statsHandlers:    config.StatsHandlers,
 	}
 }
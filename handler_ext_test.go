@@ -17,6 +17,7 @@ package scalpel_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -25,12 +26,14 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	connect "github.com/agentio/scalpel"
 	"github.com/agentio/scalpel/internal/assert"
 	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
 	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
 	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/dynamicpb"
@@ -64,7 +67,20 @@ func TestHandler_ServeHTTP(t *testing.T) {
 		assert.Equal(t, resp.StatusCode, http.StatusUnsupportedMediaType)
 		assert.Equal(t, resp.Header.Get("Accept-Post"), strings.Join([]string{
 			"application/grpc",
+			"application/grpc+json",
+			"application/grpc+json; charset=utf-8",
 			"application/grpc+proto",
+			"application/grpc-web",
+			"application/grpc-web+json",
+			"application/grpc-web+json; charset=utf-8",
+			"application/grpc-web+proto",
+			"application/grpc-web-text",
+			"application/grpc-web-text+json",
+			"application/grpc-web-text+json; charset=utf-8",
+			"application/grpc-web-text+proto",
+			"application/json",
+			"application/json; charset=utf-8",
+			"application/proto",
 		}, ", "))
 	})
 
@@ -125,6 +141,234 @@ func TestHandlerMaliciousPrefix(t *testing.T) {
 	wg.Wait()
 }
 
+func TestHandlerGRPCWebText(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pingServer{}))
+	server := memhttptest.NewServer(t, mux)
+
+	payload, err := proto.Marshal(&pingv1.PingRequest{Number: 42})
+	assert.Nil(t, err)
+	envelope := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(envelope[1:5], uint32(len(payload)))
+	envelope = append(envelope, payload...)
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		server.URL()+pingv1connect.PingServicePingProcedure,
+		strings.NewReader(base64.StdEncoding.EncodeToString(envelope)),
+	)
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "application/grpc-web-text")
+	// gRPC-Web text must work without HTTP/2.
+	client := &http.Client{Transport: server.TransportHTTP1()}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.Equal(t, resp.Header.Get("Content-Type"), "application/grpc-web-text")
+
+	encoded, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	// The response is a series of independently padded base64 segments.
+	var decoded []byte
+	for i := 0; i+4 <= len(encoded); i += 4 {
+		quantum, err := base64.StdEncoding.DecodeString(string(encoded[i : i+4]))
+		assert.Nil(t, err)
+		decoded = append(decoded, quantum...)
+	}
+	assert.True(t, len(decoded) >= 5)
+	assert.Equal(t, decoded[0], byte(0))
+	size := binary.BigEndian.Uint32(decoded[1:5])
+	var msg pingv1.PingResponse
+	assert.Nil(t, proto.Unmarshal(decoded[5:5+size], &msg))
+	assert.Equal(t, msg.GetNumber(), 42)
+	trailer := decoded[5+size:]
+	assert.True(t, len(trailer) >= 5)
+	assert.Equal(t, trailer[0], byte(0x80))
+	assert.True(t, strings.Contains(strings.ToLower(string(trailer[5:])), "grpc-status: 0"))
+}
+
+func TestHandlerTimeoutLimits(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		name          string
+		options       []connect.HandlerOption
+		clientTimeout time.Duration
+		wantDeadline  bool
+		wantMax       time.Duration
+		wantClamped   bool
+	}
+	testCases := []testCase{
+		{
+			name: "no_limits",
+		},
+		{
+			name:          "client_timeout",
+			clientTimeout: time.Minute,
+			wantDeadline:  true,
+			wantMax:       time.Minute,
+		},
+		{
+			name:         "default",
+			options:      []connect.HandlerOption{connect.WithDefaultTimeout(time.Minute)},
+			wantDeadline: true,
+			wantMax:      time.Minute,
+		},
+		{
+			name:          "default_ignored",
+			options:       []connect.HandlerOption{connect.WithDefaultTimeout(time.Second)},
+			clientTimeout: time.Minute,
+			wantDeadline:  true,
+			wantMax:       time.Minute,
+		},
+		{
+			name:          "clamped",
+			options:       []connect.HandlerOption{connect.WithMaxTimeout(time.Second)},
+			clientTimeout: time.Hour,
+			wantDeadline:  true,
+			wantMax:       time.Second,
+			wantClamped:   true,
+		},
+		{
+			name:          "under_max",
+			options:       []connect.HandlerOption{connect.WithMaxTimeout(time.Hour)},
+			clientTimeout: time.Second,
+			wantDeadline:  true,
+			wantMax:       time.Second,
+		},
+		{
+			name:         "max_without_client_timeout",
+			options:      []connect.HandlerOption{connect.WithMaxTimeout(time.Second)},
+			wantDeadline: true,
+			wantMax:      time.Second,
+			wantClamped:  true,
+		},
+		{
+			name: "default_clamped",
+			options: []connect.HandlerOption{
+				connect.WithDefaultTimeout(time.Hour),
+				connect.WithMaxTimeout(time.Second),
+			},
+			wantDeadline: true,
+			wantMax:      time.Second,
+			wantClamped:  true,
+		},
+		{
+			name: "conditional",
+			options: []connect.HandlerOption{
+				connect.WithConditionalHandlerOptions(func(spec connect.Spec) []connect.HandlerOption {
+					if spec.Procedure == pingv1connect.PingServicePingProcedure {
+						return []connect.HandlerOption{connect.WithMaxTimeout(time.Second)}
+					}
+					return nil
+				}),
+			},
+			clientTimeout: time.Hour,
+			wantDeadline:  true,
+			wantMax:       time.Second,
+			wantClamped:   true,
+		},
+	}
+	for _, testCase := range testCases {
+		for _, protocol := range []struct {
+			name   string
+			option connect.ClientOption
+		}{
+			{"connect", connect.WithClientOptions()},
+			{"grpc", connect.WithGRPC()},
+		} {
+			t.Run(testCase.name+"/"+protocol.name, func(t *testing.T) {
+				t.Parallel()
+				mux := http.NewServeMux()
+				mux.Handle(pingv1connect.NewPingServiceHandler(deadlinePingServer{}, testCase.options...))
+				server := memhttptest.NewServer(t, mux)
+				client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), protocol.option)
+				ctx := t.Context()
+				if testCase.clientTimeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, testCase.clientTimeout)
+					defer cancel()
+				}
+				response, err := client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Nil(t, err)
+				remaining := time.Duration(response.Msg.GetNumber())
+				if !testCase.wantDeadline {
+					assert.Equal(t, remaining, 0)
+					return
+				}
+				assert.True(t, remaining > 0)
+				assert.True(t, remaining <= testCase.wantMax)
+				assert.Equal(t, response.Msg.GetText() == "clamped", testCase.wantClamped)
+			})
+		}
+	}
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		mux := http.NewServeMux()
+		mux.Handle(pingv1connect.NewPingServiceHandler(
+			deadlinePingServer{},
+			connect.WithMaxTimeout(10*time.Millisecond),
+		))
+		server := memhttptest.NewServer(t, mux)
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL())
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: -1}))
+		assert.Equal(t, connect.CodeOf(err), connect.CodeDeadlineExceeded)
+	})
+}
+
+func TestHandlerConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+	limiter := connect.NewConcurrencyLimiter(connect.ConcurrencyPolicy{
+		Limit:         1,
+		Code:          connect.CodeUnavailable,
+		RetryPushback: time.Second,
+	})
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(
+		&blockingPingServer{started: started, unblock: unblock},
+		connect.WithConcurrencyLimit(limiter),
+	))
+	server := memhttptest.NewServer(t, mux)
+	for _, protocol := range []struct {
+		name   string
+		option connect.ClientOption
+	}{
+		{"connect", connect.WithClientOptions()},
+		{"grpc", connect.WithGRPC()},
+		{"grpcweb", connect.WithGRPCWeb()},
+	} {
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), protocol.option)
+		blocked := make(chan error, 1)
+		go func() {
+			_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+			blocked <- err
+		}()
+		<-started
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable, assert.Sprintf("%s", protocol.name))
+		var connectErr *connect.Error
+		if assert.True(t, errors.As(err, &connectErr)) {
+			assert.Equal(t, connectErr.Meta().Get("Grpc-Retry-Pushback-Ms"), "1000")
+		}
+		// Streams share the service's limit.
+		stream, err := client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{Number: 1}))
+		if assert.Nil(t, err) {
+			assert.False(t, stream.Receive())
+			assert.Equal(t, connect.CodeOf(stream.Err()), connect.CodeUnavailable)
+			assert.Nil(t, stream.Close())
+		}
+		unblock <- struct{}{}
+		assert.Nil(t, <-blocked)
+		// The slot may be released just after the client has its response.
+		for limiter.InFlight() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
 func TestDynamicHandler(t *testing.T) {
 	t.Parallel()
 	initializer := func(spec connect.Spec, msg any) error {
@@ -413,3 +657,49 @@ type successPingServer struct {
 func (successPingServer) Ping(context.Context, *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
 	return &connect.Response[pingv1.PingResponse]{}, nil
 }
+
+// deadlinePingServer reports the deadline in its handler's CallInfo: the
+// response's number is the time remaining, and its text is "clamped" if
+// WithMaxTimeout shortened the deadline. Requests with a negative number wait
+// for the deadline to pass.
+type deadlinePingServer struct {
+	pingv1connect.UnimplementedPingServiceHandler
+}
+
+func (deadlinePingServer) Ping(ctx context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+	if request.Msg.GetNumber() < 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	callInfo, ok := connect.CallInfoForHandlerContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("no call info"))
+	}
+	response := &pingv1.PingResponse{}
+	if deadline, ok := callInfo.Deadline(); ok {
+		response.Number = int64(time.Until(deadline))
+	}
+	if callInfo.DeadlineClamped() {
+		response.Text = "clamped"
+	}
+	return connect.NewResponse(response), nil
+}
+
+// blockingPingServer signals when a Ping starts, then waits to be unblocked
+// before responding.
+type blockingPingServer struct {
+	pingv1connect.UnimplementedPingServiceHandler
+
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (s *blockingPingServer) Ping(ctx context.Context, _ *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+	s.started <- struct{}{}
+	select {
+	case <-s.unblock:
+		return connect.NewResponse(&pingv1.PingResponse{}), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -29,6 +29,13 @@ var protocolHeaders = map[string]struct{}{
 	headerUserAgent:       {},
 	headerTrailer:         {},
 	headerDate:            {},
+	// Connect headers.
+	connectUnaryHeaderAcceptCompression:     {},
+	connectUnaryTrailerPrefix:               {},
+	connectStreamingHeaderCompression:       {},
+	connectStreamingHeaderAcceptCompression: {},
+	connectHeaderTimeout:                    {},
+	connectHeaderProtocolVersion:            {},
 	// gRPC headers.
 	grpcHeaderCompression:       {},
 	grpcHeaderAcceptCompression: {},
@@ -40,7 +47,8 @@ var protocolHeaders = map[string]struct{}{
 
 // EncodeBinaryHeader base64-encodes the data. It always emits unpadded values.
 //
-// In the gRPC protocol, binary headers must have keys ending in "-Bin".
+// In the Connect, gRPC, and gRPC-Web protocols, binary headers must have keys
+// ending in "-Bin".
 func EncodeBinaryHeader(data []byte) string {
 	// gRPC specification says that implementations should emit unpadded values.
 	return base64.RawStdEncoding.EncodeToString(data)
@@ -51,7 +59,8 @@ func EncodeBinaryHeader(data []byte) string {
 // be joined with a comma. When receiving such comma-separated values, split
 // them with [strings.Split] before calling DecodeBinaryHeader.
 //
-// Binary headers sent have keys ending in "-Bin".
+// Binary headers sent using the Connect, gRPC, and gRPC-Web protocols have
+// keys ending in "-Bin".
 func DecodeBinaryHeader(data string) ([]byte, error) {
 	if len(data)%4 != 0 {
 		// Data definitely isn't padded.
@@ -0,0 +1,82 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"strings"
+
+	"github.com/agentio/scalpel"
+	healthv1 "github.com/agentio/scalpel/internal/gen/connectext/grpc/health/v1"
+)
+
+// Client is a client for the health service. It works with any server that
+// implements gRPC's health-checking API, not just servers built with
+// [NewHandler].
+type Client struct {
+	check *scalpel.Client[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse]
+	watch *scalpel.Client[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse]
+}
+
+// NewClient constructs a Client. The HTTP client, base URL, and options are
+// used to connect to the health service; the base URL shouldn't include the
+// service path.
+func NewClient(httpClient scalpel.HTTPClient, baseURL string, options ...scalpel.ClientOption) *Client {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &Client{
+		check: scalpel.NewClient[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse](
+			httpClient,
+			baseURL+checkProcedure,
+			scalpel.WithClientOptions(options...),
+			scalpel.WithSchema(healthMethods.ByName("Check")),
+		),
+		watch: scalpel.NewClient[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse](
+			httpClient,
+			baseURL+watchProcedure,
+			scalpel.WithClientOptions(options...),
+			scalpel.WithSchema(healthMethods.ByName("Watch")),
+		),
+	}
+}
+
+// Check asks the server for the health of a service. Use the empty string to
+// check the health of the server as a whole. If the service is unknown, the
+// server returns an error with scalpel.CodeNotFound.
+func (c *Client) Check(ctx context.Context, service string) (Status, error) {
+	response, err := c.check.CallUnary(ctx, scalpel.NewRequest(&healthv1.HealthCheckRequest{Service: service}))
+	if err != nil {
+		return StatusUnknown, err
+	}
+	return Status(response.Msg.GetStatus()), nil
+}
+
+// Watch asks the server to stream the health of a service, calling fn with
+// the current status and then with each change. It returns when the context
+// is done, when the server ends the stream, or when fn returns an error.
+// Servers that don't support watching return an error with
+// scalpel.CodeUnimplemented.
+func (c *Client) Watch(ctx context.Context, service string, fn func(Status) error) error {
+	stream, err := c.watch.CallServerStream(ctx, scalpel.NewRequest(&healthv1.HealthCheckRequest{Service: service}))
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	for stream.Receive() {
+		if err := fn(Status(stream.Msg().GetStatus())); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
@@ -0,0 +1,280 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health offers support for gRPC's health-checking APIs, which are
+// used by orchestration systems like Kubernetes and by load balancers to
+// decide whether a server is ready for traffic. The handlers and clients in
+// this package work with the gRPC, gRPC-Web, and Connect protocols.
+//
+// See https://github.com/grpc/grpc/blob/master/doc/health-checking.md for
+// details of the protocol.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/agentio/scalpel"
+	healthv1 "github.com/agentio/scalpel/internal/gen/connectext/grpc/health/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// HealthV1ServiceName is the fully-qualified name of the v1 version of the
+// health service.
+const HealthV1ServiceName = "grpc.health.v1.Health"
+
+const (
+	checkProcedure = "/" + HealthV1ServiceName + "/Check"
+	watchProcedure = "/" + HealthV1ServiceName + "/Watch"
+)
+
+// healthMethods describes the methods of the health service. The generated
+// code uses a "connectext." package prefix to avoid conflicts with gRPC's own
+// copy, so the schema is rebuilt with the standard package: otherwise,
+// Spec.Schema would name a different service than Spec.Procedure.
+var healthMethods = newHealthMethods() //nolint:gochecknoglobals
+
+func newHealthMethods() protoreflect.MethodDescriptors {
+	const (
+		generatedPackage = ".connectext.grpc.health.v1."
+		standardPackage  = ".grpc.health.v1."
+	)
+	fileProto := protodesc.ToFileDescriptorProto(healthv1.File_connectext_grpc_health_v1_health_proto)
+	fileProto.Name = proto.String("grpc/health/v1/health.proto")
+	fileProto.Package = proto.String("grpc.health.v1")
+	for _, message := range fileProto.GetMessageType() {
+		for _, field := range message.GetField() {
+			if field.TypeName != nil {
+				field.TypeName = proto.String(strings.Replace(field.GetTypeName(), generatedPackage, standardPackage, 1))
+			}
+		}
+	}
+	for _, method := range fileProto.GetService()[0].GetMethod() {
+		method.InputType = proto.String(strings.Replace(method.GetInputType(), generatedPackage, standardPackage, 1))
+		method.OutputType = proto.String(strings.Replace(method.GetOutputType(), generatedPackage, standardPackage, 1))
+	}
+	file, err := protodesc.NewFile(fileProto, nil)
+	if err != nil {
+		panic(fmt.Sprintf("scalpel.health: invalid schema: %v", err)) //nolint:forbidigo
+	}
+	return file.Services().ByName("Health").Methods()
+}
+
+// Status describes the health of a service.
+type Status uint8
+
+const (
+	// StatusUnknown indicates that the service's health state is indeterminate.
+	StatusUnknown Status = 0
+	// StatusServing indicates that the service is ready to accept requests.
+	StatusServing Status = 1
+	// StatusNotServing indicates that the process is healthy but the service
+	// is not accepting requests. For example, StatusNotServing is often
+	// appropriate while a server is draining.
+	StatusNotServing Status = 2
+	// StatusServiceUnknown indicates that the service isn't known. It's only
+	// reported by the Watch RPC: Check returns a NotFound error instead.
+	StatusServiceUnknown Status = 3
+)
+
+// String representation of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusUnknown:
+		return "unknown"
+	case StatusServing:
+		return "serving"
+	case StatusNotServing:
+		return "not_serving"
+	case StatusServiceUnknown:
+		return "service_unknown"
+	}
+	return fmt.Sprintf("status_%d", s)
+}
+
+// CheckRequest is a request for the health of a service. When using
+// protobuf, Service will be a fully-qualified service name (for example,
+// "acme.ping.v1.PingService"). If the Service is an empty string, the caller
+// is asking for the health status of the whole process.
+type CheckRequest struct {
+	Service string
+}
+
+// CheckResponse reports the health of a service (or of the whole process).
+type CheckResponse struct {
+	Status Status
+}
+
+// A Checker reports the health of a service. It must be safe to call
+// concurrently.
+type Checker interface {
+	// Check the health of a service. If the service is unknown, Check should
+	// return an error with scalpel.CodeNotFound.
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+}
+
+// A Watcher is a [Checker] that can report changes in health. Handlers only
+// support the Watch RPC if their Checker is also a Watcher.
+type Watcher interface {
+	Checker
+
+	// Changed returns a channel that's closed the next time the health of any
+	// service changes.
+	Changed() <-chan struct{}
+}
+
+// NewHandler wraps the supplied Checker to build an HTTP handler for gRPC's
+// health-checking API. It returns the path on which to mount the handler and
+// the HTTP handler itself.
+//
+// Note that the returned handler only supports the unary Check method. If
+// the checker is also a [Watcher], the handler supports the streaming Watch
+// method too; otherwise, Watch returns scalpel.CodeUnimplemented, which
+// tells clients to fall back to polling Check.
+func NewHandler(checker Checker, options ...scalpel.HandlerOption) (string, http.Handler) {
+	mux := http.NewServeMux()
+	check := scalpel.NewUnaryHandler(
+		checkProcedure,
+		func(ctx context.Context, request *scalpel.Request[healthv1.HealthCheckRequest]) (*scalpel.Response[healthv1.HealthCheckResponse], error) {
+			response, err := checker.Check(ctx, &CheckRequest{Service: request.Msg.GetService()})
+			if err != nil {
+				return nil, err
+			}
+			return scalpel.NewResponse(&healthv1.HealthCheckResponse{
+				Status: healthv1.HealthCheckResponse_ServingStatus(response.Status),
+			}), nil
+		},
+		scalpel.WithHandlerOptions(options...),
+		scalpel.WithSchema(healthMethods.ByName("Check")),
+	)
+	mux.Handle(checkProcedure, check)
+	watch := scalpel.NewServerStreamHandler(
+		watchProcedure,
+		func(ctx context.Context, request *scalpel.Request[healthv1.HealthCheckRequest], stream *scalpel.ServerStream[healthv1.HealthCheckResponse]) error {
+			watcher, ok := checker.(Watcher)
+			if !ok {
+				return scalpel.NewError(
+					scalpel.CodeUnimplemented,
+					errors.New("scalpel.health: watch not implemented"),
+				)
+			}
+			return watch(ctx, watcher, request.Msg.GetService(), stream)
+		},
+		scalpel.WithHandlerOptions(options...),
+		scalpel.WithSchema(healthMethods.ByName("Watch")),
+	)
+	mux.Handle(watchProcedure, watch)
+	return "/" + HealthV1ServiceName + "/", mux
+}
+
+// watch sends the service's status, and then sends it again every time it
+// changes, until the context is done.
+func watch(ctx context.Context, watcher Watcher, service string, stream *scalpel.ServerStream[healthv1.HealthCheckResponse]) error {
+	var last Status
+	first := true
+	for {
+		// Get the channel before checking, so we don't miss a change.
+		changed := watcher.Changed()
+		status := StatusServiceUnknown
+		response, err := watcher.Check(ctx, &CheckRequest{Service: service})
+		switch {
+		case err == nil:
+			status = response.Status
+		case scalpel.CodeOf(err) != scalpel.CodeNotFound:
+			return err
+		}
+		if first || status != last {
+			if err := stream.Send(&healthv1.HealthCheckResponse{
+				Status: healthv1.HealthCheckResponse_ServingStatus(status),
+			}); err != nil {
+				return err
+			}
+			first, last = false, status
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// StaticChecker is a simple [Checker] and [Watcher]. It reports the health
+// of a fixed set of services, and the health of each service can be changed
+// at runtime with SetStatus. The health of the whole process, reported for
+// the empty service name, is tracked the same way.
+type StaticChecker struct {
+	mu       sync.RWMutex
+	statuses map[string]Status
+	changed  chan struct{}
+}
+
+// NewStaticChecker constructs a StaticChecker. By default, each of the
+// supplied services and the process as a whole have StatusServing. Any other
+// service is unknown.
+//
+// The supplied strings should be fully-qualified protobuf service names (for
+// example, "acme.user.v1.UserService"). Generated code contains constants
+// for service names; see the ServiceName constants in the generated code.
+func NewStaticChecker(services ...string) *StaticChecker {
+	statuses := make(map[string]Status, len(services)+1)
+	statuses[""] = StatusServing // process health
+	for _, service := range services {
+		statuses[service] = StatusServing
+	}
+	return &StaticChecker{
+		statuses: statuses,
+		changed:  make(chan struct{}),
+	}
+}
+
+// SetStatus sets the health of a service, registering the service if it
+// wasn't already known. Use the empty string to set the health of the
+// process as a whole.
+func (c *StaticChecker) SetStatus(service string, status Status) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.statuses[service]; ok && current == status {
+		return
+	}
+	c.statuses[service] = status
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// Check implements [Checker].
+func (c *StaticChecker) Check(_ context.Context, request *CheckRequest) (*CheckResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if status, ok := c.statuses[request.Service]; ok {
+		return &CheckResponse{Status: status}, nil
+	}
+	return nil, scalpel.NewError(
+		scalpel.CodeNotFound,
+		fmt.Errorf("unknown service %s", request.Service),
+	)
+}
+
+// Changed implements [Watcher].
+func (c *StaticChecker) Changed() <-chan struct{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.changed
+}
@@ -0,0 +1,135 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/health"
+	"github.com/agentio/scalpel/internal/assert"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+	const pingService = "connect.ping.v1.PingService"
+	checker := health.NewStaticChecker(pingService)
+	mux := http.NewServeMux()
+	mux.Handle(health.NewHandler(checker))
+	server := memhttptest.NewServer(t, mux)
+	protocols := []struct {
+		name string
+		opt  connect.ClientOption
+	}{
+		{connect.ProtocolConnect, connect.WithConnect()},
+		{connect.ProtocolGRPC, connect.WithGRPC()},
+		{connect.ProtocolGRPCWeb, connect.WithGRPCWeb()},
+	}
+	for _, protocol := range protocols {
+		t.Run(protocol.name, func(t *testing.T) {
+			t.Parallel()
+			client := health.NewClient(server.Client(), server.URL(), protocol.opt)
+			status, err := client.Check(t.Context(), "")
+			assert.Nil(t, err)
+			assert.Equal(t, status, health.StatusServing)
+			status, err = client.Check(t.Context(), pingService)
+			assert.Nil(t, err)
+			assert.Equal(t, status, health.StatusServing)
+			_, err = client.Check(t.Context(), "unknown")
+			assert.Equal(t, connect.CodeOf(err), connect.CodeNotFound)
+		})
+	}
+}
+
+func TestHealthWatch(t *testing.T) {
+	t.Parallel()
+	const service = "connect.ping.v1.PingService"
+	checker := health.NewStaticChecker()
+	mux := http.NewServeMux()
+	mux.Handle(health.NewHandler(checker))
+	server := memhttptest.NewServer(t, mux)
+	client := health.NewClient(server.Client(), server.URL())
+
+	errDone := errors.New("done")
+	var statuses []health.Status
+	err := client.Watch(t.Context(), service, func(status health.Status) error {
+		statuses = append(statuses, status)
+		switch status {
+		case health.StatusServiceUnknown:
+			checker.SetStatus(service, health.StatusServing)
+		case health.StatusServing:
+			checker.SetStatus(service, health.StatusNotServing)
+		default:
+			return errDone
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errDone)
+	assert.Equal(t, statuses, []health.Status{
+		health.StatusServiceUnknown,
+		health.StatusServing,
+		health.StatusNotServing,
+	})
+}
+
+func TestHealthWatchUnimplemented(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle(health.NewHandler(checkerFunc(func(context.Context, *health.CheckRequest) (*health.CheckResponse, error) {
+		return &health.CheckResponse{Status: health.StatusServing}, nil
+	})))
+	server := memhttptest.NewServer(t, mux)
+	client := health.NewClient(server.Client(), server.URL())
+	status, err := client.Check(t.Context(), "")
+	assert.Nil(t, err)
+	assert.Equal(t, status, health.StatusServing)
+	err = client.Watch(t.Context(), "", func(health.Status) error { return nil })
+	assert.Equal(t, connect.CodeOf(err), connect.CodeUnimplemented)
+}
+
+type checkerFunc func(context.Context, *health.CheckRequest) (*health.CheckResponse, error)
+
+func (fn checkerFunc) Check(ctx context.Context, request *health.CheckRequest) (*health.CheckResponse, error) {
+	return fn(ctx, request)
+}
+
+func TestHealthSchema(t *testing.T) {
+	t.Parallel()
+	var specs []connect.Spec
+	interceptor := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			specs = append(specs, request.Spec())
+			return next(ctx, request)
+		}
+	})
+	mux := http.NewServeMux()
+	mux.Handle(health.NewHandler(health.NewStaticChecker(), connect.WithInterceptors(interceptor)))
+	server := memhttptest.NewServer(t, mux)
+	client := health.NewClient(server.Client(), server.URL(), connect.WithInterceptors(interceptor))
+	_, err := client.Check(t.Context(), "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(specs), 2) // client and handler
+	for _, spec := range specs {
+		method, ok := spec.Schema.(protoreflect.MethodDescriptor)
+		assert.True(t, ok)
+		assert.Equal(t, "/"+string(method.Parent().FullName())+"/"+string(method.Name()), spec.Procedure)
+		assert.Equal(t, method.Input().FullName(), "grpc.health.v1.HealthCheckRequest")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// A HedgingPolicy configures request hedging for idempotent unary methods.
+// Like [RetryPolicy], it's modeled on gRPC's service config: see
+// https://github.com/grpc/proposal/blob/master/A6-client-retries.md.
+//
+// The client sends the request, then sends another copy after each
+// HedgingDelay until it has sent MaxAttempts copies. The first response that
+// succeeds or fails with a fatal error wins, and the other attempts are
+// canceled. If an attempt fails with one of the NonFatalCodes, the client
+// sends the next copy immediately. If every attempt fails, the client returns
+// the last error.
+//
+// Methods are idempotent if their [Spec.IdempotencyLevel] is
+// IdempotencyIdempotent or IdempotencyNoSideEffects. Hedging never applies to
+// streaming methods.
+type HedgingPolicy struct {
+	// MaxAttempts is the maximum number of copies of the request, including
+	// the original. It must be greater than one; values greater than five are
+	// treated as five.
+	MaxAttempts int
+	// HedgingDelay is the time to wait before sending each additional copy of
+	// the request. If zero, all copies are sent at once.
+	HedgingDelay time.Duration
+	// NonFatalCodes are the codes that don't end the call while other attempts
+	// are in flight or still to be sent.
+	NonFatalCodes []Code
+}
+
+func (p *HedgingPolicy) validate() error {
+	if p.MaxAttempts < 2 {
+		return fmt.Errorf("hedging policy: MaxAttempts must be greater than one, got %d", p.MaxAttempts)
+	}
+	if p.HedgingDelay < 0 {
+		return fmt.Errorf("hedging policy: HedgingDelay must not be negative, got %v", p.HedgingDelay)
+	}
+	return nil
+}
+
+func (p *HedgingPolicy) maxAttempts() int {
+	return min(p.MaxAttempts, retryMaxAttemptsLimit)
+}
+
+// isFatal reports whether an attempt's error ends the call.
+func (p *HedgingPolicy) isFatal(err error) bool {
+	return !slices.Contains(p.NonFatalCodes, CodeOf(err))
+}
+
+// payloadClones creates request bodies for hedged attempts. The bodies share
+// the marshaled message, but each has its own read offset so that attempts
+// can be sent concurrently.
+type payloadClones struct {
+	mu       sync.Mutex
+	payload  messagePayload
+	snapshot []byte // copy of payloads that can't be shared
+	clones   []*payloadCloser
+	released bool
+}
+
+func newPayloadClones(payload messagePayload) *payloadClones {
+	return &payloadClones{payload: payload}
+}
+
+// NewBody returns a new request body. It's safe to use as a GetBody function.
+func (p *payloadClones) NewBody() (io.ReadCloser, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.released {
+		return nil, errors.New("payload cannot be retried")
+	}
+	var clone messagePayload
+	if env, ok := p.payload.(*envelope); ok {
+		clone = &envelope{Data: env.Data, Flags: env.Flags}
+	} else {
+		if p.snapshot == nil {
+			buffer := bytes.NewBuffer(make([]byte, 0, p.payload.Len()))
+			if _, err := p.payload.WriteTo(buffer); err != nil {
+				return nil, err
+			}
+			p.snapshot = buffer.Bytes()
+		}
+		clone = bytes.NewReader(p.snapshot)
+	}
+	body := newPayloadCloser(clone)
+	p.clones = append(p.clones, body)
+	return body, nil
+}
+
+// Release discards the payload from all bodies, so that it's safe to reuse
+// even if canceled attempts are still being written.
+func (p *payloadClones) Release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.released = true
+	for _, body := range p.clones {
+		body.Release()
+	}
+}
+
+// hedgeResult is the outcome of a single hedged attempt.
+type hedgeResult struct {
+	response *http.Response
+	err      error
+	attempt  int // counting from zero
+}
+
+// close discards the response of an attempt that lost the race.
+func (r *hedgeResult) close() {
+	if r.response != nil {
+		_ = r.response.Body.Close()
+	}
+}
+
+// cancelOnClose is a response body that cancels its attempt's context when
+// it's closed.
+type cancelOnClose struct {
+	io.ReadCloser
+
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
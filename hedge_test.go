@@ -0,0 +1,57 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/agentio/scalpel/internal/assert"
+)
+
+func TestPayloadClones(t *testing.T) {
+	t.Parallel()
+	payloads := map[string]func() messagePayload{
+		"envelope": func() messagePayload {
+			return &envelope{Data: bytes.NewBufferString("hello")}
+		},
+		"bytes": func() messagePayload {
+			return bytes.NewReader([]byte("\x00\x00\x00\x00\x05hello"))
+		},
+	}
+	for name, newPayload := range payloads {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			clones := newPayloadClones(newPayload())
+			first, err := clones.NewBody()
+			assert.Nil(t, err)
+			second, err := clones.NewBody()
+			assert.Nil(t, err)
+			prefix := make([]byte, 3)
+			_, err = io.ReadFull(first, prefix)
+			assert.Nil(t, err)
+			secondData, err := io.ReadAll(second)
+			assert.Nil(t, err)
+			assert.Equal(t, secondData, []byte("\x00\x00\x00\x00\x05hello"))
+			clones.Release()
+			rest, err := io.ReadAll(first)
+			assert.Nil(t, err)
+			assert.Equal(t, len(rest), 0)
+			_, err = clones.NewBody()
+			assert.NotNil(t, err)
+		})
+	}
+}
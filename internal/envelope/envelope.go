@@ -0,0 +1,33 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envelope describes which HTTP bodies are made of enveloped frames.
+// It's shared by the packages that copy or record bodies without decoding
+// them.
+package envelope
+
+import "strings"
+
+// IsEnveloped reports whether bodies with the content type are made of
+// frames: that is, whether they're gRPC, gRPC-Web, or Connect streaming
+// bodies. Connect unary bodies aren't enveloped, and neither are gRPC-Web text
+// bodies, whose frames are base64-encoded.
+func IsEnveloped(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.HasPrefix(contentType, "application/grpc-web-text") {
+		return false
+	}
+	return strings.HasPrefix(contentType, "application/grpc") ||
+		strings.HasPrefix(contentType, "application/connect+")
+}
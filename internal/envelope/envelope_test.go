@@ -0,0 +1,40 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope_test
+
+import (
+	"testing"
+
+	"github.com/agentio/scalpel/internal/assert"
+	"github.com/agentio/scalpel/internal/envelope"
+)
+
+func TestIsEnveloped(t *testing.T) {
+	t.Parallel()
+	for contentType, want := range map[string]bool{
+		"application/grpc":                true,
+		"application/grpc+proto":          true,
+		"application/grpc-web":            true,
+		"application/GRPC-Web+json":       true,
+		"application/connect+proto":       true,
+		"application/proto":               false,
+		"application/json":                false,
+		"application/grpc-web-text":       false,
+		"application/grpc-web-text+proto": false,
+		"":                                false,
+	} {
+		assert.Equal(t, envelope.IsEnveloped(contentType), want, assert.Sprintf("content type %q", contentType))
+	}
+}
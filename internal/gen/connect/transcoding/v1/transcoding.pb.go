@@ -0,0 +1,876 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: connect/transcoding/v1/transcoding.proto
+
+// The connect.transcoding.v1 package contains a small REST-style service
+// designed to test HTTP/JSON transcoding.
+
+package transcodingv1
+
+import (
+	_ "github.com/agentio/scalpel/internal/gen/connectext/google/api"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Genre int32
+
+const (
+	Genre_GENRE_UNSPECIFIED Genre = 0
+	Genre_GENRE_FICTION     Genre = 1
+	Genre_GENRE_HISTORY     Genre = 2
+)
+
+// Enum value maps for Genre.
+var (
+	Genre_name = map[int32]string{
+		0: "GENRE_UNSPECIFIED",
+		1: "GENRE_FICTION",
+		2: "GENRE_HISTORY",
+	}
+	Genre_value = map[string]int32{
+		"GENRE_UNSPECIFIED": 0,
+		"GENRE_FICTION":     1,
+		"GENRE_HISTORY":     2,
+	}
+)
+
+func (x Genre) Enum() *Genre {
+	p := new(Genre)
+	*p = x
+	return p
+}
+
+func (x Genre) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Genre) Descriptor() protoreflect.EnumDescriptor {
+	return file_connect_transcoding_v1_transcoding_proto_enumTypes[0].Descriptor()
+}
+
+func (Genre) Type() protoreflect.EnumType {
+	return &file_connect_transcoding_v1_transcoding_proto_enumTypes[0]
+}
+
+func (x Genre) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Genre.Descriptor instead.
+func (Genre) EnumDescriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{0}
+}
+
+type Book struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The resource name, like "shelves/1/books/2".
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Title         string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Author        string `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Pages         int32  `protobuf:"varint,4,opt,name=pages,proto3" json:"pages,omitempty"`
+	Genre         Genre  `protobuf:"varint,5,opt,name=genre,proto3,enum=connect.transcoding.v1.Genre" json:"genre,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Book) Reset() {
+	*x = Book{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Book) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Book) ProtoMessage() {}
+
+func (x *Book) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Book.ProtoReflect.Descriptor instead.
+func (*Book) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Book) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Book) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Book) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Book) GetPages() int32 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+func (x *Book) GetGenre() Genre {
+	if x != nil {
+		return x.Genre
+	}
+	return Genre_GENRE_UNSPECIFIED
+}
+
+type GetBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookRequest) Reset() {
+	*x = GetBookRequest{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookRequest) ProtoMessage() {}
+
+func (x *GetBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookRequest.ProtoReflect.Descriptor instead.
+func (*GetBookRequest) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBookRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookResponse) Reset() {
+	*x = GetBookResponse{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookResponse) ProtoMessage() {}
+
+func (x *GetBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookResponse.ProtoReflect.Descriptor instead.
+func (*GetBookResponse) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBookResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type ListBooksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The shelf, like "shelves/1".
+	Parent        string   `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	PageSize      int32    `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Authors       []string `protobuf:"bytes,3,rep,name=authors,proto3" json:"authors,omitempty"`
+	Genre         Genre    `protobuf:"varint,4,opt,name=genre,proto3,enum=connect.transcoding.v1.Genre" json:"genre,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBooksRequest) Reset() {
+	*x = ListBooksRequest{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBooksRequest) ProtoMessage() {}
+
+func (x *ListBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBooksRequest.ProtoReflect.Descriptor instead.
+func (*ListBooksRequest) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListBooksRequest) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *ListBooksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListBooksRequest) GetAuthors() []string {
+	if x != nil {
+		return x.Authors
+	}
+	return nil
+}
+
+func (x *ListBooksRequest) GetGenre() Genre {
+	if x != nil {
+		return x.Genre
+	}
+	return Genre_GENRE_UNSPECIFIED
+}
+
+type ListBooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Books         []*Book                `protobuf:"bytes,1,rep,name=books,proto3" json:"books,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBooksResponse) Reset() {
+	*x = ListBooksResponse{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBooksResponse) ProtoMessage() {}
+
+func (x *ListBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBooksResponse.ProtoReflect.Descriptor instead.
+func (*ListBooksResponse) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListBooksResponse) GetBooks() []*Book {
+	if x != nil {
+		return x.Books
+	}
+	return nil
+}
+
+func (x *ListBooksResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type CreateBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parent        string                 `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Book          *Book                  `protobuf:"bytes,2,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBookRequest) Reset() {
+	*x = CreateBookRequest{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBookRequest) ProtoMessage() {}
+
+func (x *CreateBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBookRequest.ProtoReflect.Descriptor instead.
+func (*CreateBookRequest) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateBookRequest) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *CreateBookRequest) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type CreateBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBookResponse) Reset() {
+	*x = CreateBookResponse{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBookResponse) ProtoMessage() {}
+
+func (x *CreateBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBookResponse.ProtoReflect.Descriptor instead.
+func (*CreateBookResponse) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateBookResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type UpdateBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBookRequest) Reset() {
+	*x = UpdateBookRequest{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBookRequest) ProtoMessage() {}
+
+func (x *UpdateBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBookRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBookRequest) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateBookRequest) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type UpdateBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBookResponse) Reset() {
+	*x = UpdateBookResponse{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBookResponse) ProtoMessage() {}
+
+func (x *UpdateBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBookResponse.ProtoReflect.Descriptor instead.
+func (*UpdateBookResponse) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateBookResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type DeleteBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Force         bool                   `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBookRequest) Reset() {
+	*x = DeleteBookRequest{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBookRequest) ProtoMessage() {}
+
+func (x *DeleteBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBookRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBookRequest) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteBookRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeleteBookRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type DeleteBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBookResponse) Reset() {
+	*x = DeleteBookResponse{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBookResponse) ProtoMessage() {}
+
+func (x *DeleteBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBookResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBookResponse) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{10}
+}
+
+type WatchBooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parent        string                 `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchBooksRequest) Reset() {
+	*x = WatchBooksRequest{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBooksRequest) ProtoMessage() {}
+
+func (x *WatchBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBooksRequest.ProtoReflect.Descriptor instead.
+func (*WatchBooksRequest) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchBooksRequest) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+type WatchBooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *Book                  `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchBooksResponse) Reset() {
+	*x = WatchBooksResponse{}
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBooksResponse) ProtoMessage() {}
+
+func (x *WatchBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_connect_transcoding_v1_transcoding_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBooksResponse.ProtoReflect.Descriptor instead.
+func (*WatchBooksResponse) Descriptor() ([]byte, []int) {
+	return file_connect_transcoding_v1_transcoding_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchBooksResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+var File_connect_transcoding_v1_transcoding_proto protoreflect.FileDescriptor
+
+const file_connect_transcoding_v1_transcoding_proto_rawDesc = "" +
+	"\n" +
+	"(connect/transcoding/v1/transcoding.proto\x12\x16connect.transcoding.v1\x1a'connectext/google/api/annotations.proto\"\x93\x01\n" +
+	"\x04Book\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x14\n" +
+	"\x05pages\x18\x04 \x01(\x05R\x05pages\x123\n" +
+	"\x05genre\x18\x05 \x01(\x0e2\x1d.connect.transcoding.v1.GenreR\x05genre\"$\n" +
+	"\x0eGetBookRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"C\n" +
+	"\x0fGetBookResponse\x120\n" +
+	"\x04book\x18\x01 \x01(\v2\x1c.connect.transcoding.v1.BookR\x04book\"\x96\x01\n" +
+	"\x10ListBooksRequest\x12\x16\n" +
+	"\x06parent\x18\x01 \x01(\tR\x06parent\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x18\n" +
+	"\aauthors\x18\x03 \x03(\tR\aauthors\x123\n" +
+	"\x05genre\x18\x04 \x01(\x0e2\x1d.connect.transcoding.v1.GenreR\x05genre\"]\n" +
+	"\x11ListBooksResponse\x122\n" +
+	"\x05books\x18\x01 \x03(\v2\x1c.connect.transcoding.v1.BookR\x05books\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"]\n" +
+	"\x11CreateBookRequest\x12\x16\n" +
+	"\x06parent\x18\x01 \x01(\tR\x06parent\x120\n" +
+	"\x04book\x18\x02 \x01(\v2\x1c.connect.transcoding.v1.BookR\x04book\"F\n" +
+	"\x12CreateBookResponse\x120\n" +
+	"\x04book\x18\x01 \x01(\v2\x1c.connect.transcoding.v1.BookR\x04book\"E\n" +
+	"\x11UpdateBookRequest\x120\n" +
+	"\x04book\x18\x01 \x01(\v2\x1c.connect.transcoding.v1.BookR\x04book\"F\n" +
+	"\x12UpdateBookResponse\x120\n" +
+	"\x04book\x18\x01 \x01(\v2\x1c.connect.transcoding.v1.BookR\x04book\"=\n" +
+	"\x11DeleteBookRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"\x14\n" +
+	"\x12DeleteBookResponse\"+\n" +
+	"\x11WatchBooksRequest\x12\x16\n" +
+	"\x06parent\x18\x01 \x01(\tR\x06parent\"F\n" +
+	"\x12WatchBooksResponse\x120\n" +
+	"\x04book\x18\x01 \x01(\v2\x1c.connect.transcoding.v1.BookR\x04book*D\n" +
+	"\x05Genre\x12\x15\n" +
+	"\x11GENRE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rGENRE_FICTION\x10\x01\x12\x11\n" +
+	"\rGENRE_HISTORY\x10\x022\xae\a\n" +
+	"\x0eLibraryService\x12\x86\x01\n" +
+	"\aGetBook\x12&.connect.transcoding.v1.GetBookRequest\x1a'.connect.transcoding.v1.GetBookResponse\"*\x82\xd3\xe4\x93\x02$b\x04book\x12\x1c/v1/{name=shelves/*/books/*}\x12\x93\x01\n" +
+	"\tListBooks\x12(.connect.transcoding.v1.ListBooksRequest\x1a).connect.transcoding.v1.ListBooksResponse\"1\x82\xd3\xe4\x93\x02+Z\v\x12\t/v1/books\x12\x1c/v1/{parent=shelves/*}/books\x12\x95\x01\n" +
+	"\n" +
+	"CreateBook\x12).connect.transcoding.v1.CreateBookRequest\x1a*.connect.transcoding.v1.CreateBookResponse\"0\x82\xd3\xe4\x93\x02*:\x04bookb\x04book\"\x1c/v1/{parent=shelves/*}/books\x12\x9a\x01\n" +
+	"\n" +
+	"UpdateBook\x12).connect.transcoding.v1.UpdateBookRequest\x1a*.connect.transcoding.v1.UpdateBookResponse\"5\x82\xd3\xe4\x93\x02/:\x04bookb\x04book2!/v1/{book.name=shelves/*/books/*}\x12\xb3\x01\n" +
+	"\n" +
+	"DeleteBook\x12).connect.transcoding.v1.DeleteBookRequest\x1a*.connect.transcoding.v1.DeleteBookResponse\"N\x82\xd3\xe4\x93\x02HZ(:\x01*\"#/v1/{name=shelves/*/books/*}:delete*\x1c/v1/{name=shelves/*/books/*}\x12\x91\x01\n" +
+	"\n" +
+	"WatchBooks\x12).connect.transcoding.v1.WatchBooksRequest\x1a*.connect.transcoding.v1.WatchBooksResponse\"*\x82\xd3\xe4\x93\x02$\x12\"/v1/{parent=shelves/*}/books:watch0\x01B\xf6\x01\n" +
+	"\x1acom.connect.transcoding.v1B\x10TranscodingProtoP\x01ZLgithub.com/agentio/scalpel/internal/gen/connect/transcoding/v1;transcodingv1\xa2\x02\x03CTX\xaa\x02\x16Connect.Transcoding.V1\xca\x02\x16Connect\\Transcoding\\V1\xe2\x02\"Connect\\Transcoding\\V1\\GPBMetadata\xea\x02\x18Connect::Transcoding::V1b\x06proto3"
+
+var (
+	file_connect_transcoding_v1_transcoding_proto_rawDescOnce sync.Once
+	file_connect_transcoding_v1_transcoding_proto_rawDescData []byte
+)
+
+func file_connect_transcoding_v1_transcoding_proto_rawDescGZIP() []byte {
+	file_connect_transcoding_v1_transcoding_proto_rawDescOnce.Do(func() {
+		file_connect_transcoding_v1_transcoding_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_connect_transcoding_v1_transcoding_proto_rawDesc), len(file_connect_transcoding_v1_transcoding_proto_rawDesc)))
+	})
+	return file_connect_transcoding_v1_transcoding_proto_rawDescData
+}
+
+var file_connect_transcoding_v1_transcoding_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_connect_transcoding_v1_transcoding_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_connect_transcoding_v1_transcoding_proto_goTypes = []any{
+	(Genre)(0),                 // 0: connect.transcoding.v1.Genre
+	(*Book)(nil),               // 1: connect.transcoding.v1.Book
+	(*GetBookRequest)(nil),     // 2: connect.transcoding.v1.GetBookRequest
+	(*GetBookResponse)(nil),    // 3: connect.transcoding.v1.GetBookResponse
+	(*ListBooksRequest)(nil),   // 4: connect.transcoding.v1.ListBooksRequest
+	(*ListBooksResponse)(nil),  // 5: connect.transcoding.v1.ListBooksResponse
+	(*CreateBookRequest)(nil),  // 6: connect.transcoding.v1.CreateBookRequest
+	(*CreateBookResponse)(nil), // 7: connect.transcoding.v1.CreateBookResponse
+	(*UpdateBookRequest)(nil),  // 8: connect.transcoding.v1.UpdateBookRequest
+	(*UpdateBookResponse)(nil), // 9: connect.transcoding.v1.UpdateBookResponse
+	(*DeleteBookRequest)(nil),  // 10: connect.transcoding.v1.DeleteBookRequest
+	(*DeleteBookResponse)(nil), // 11: connect.transcoding.v1.DeleteBookResponse
+	(*WatchBooksRequest)(nil),  // 12: connect.transcoding.v1.WatchBooksRequest
+	(*WatchBooksResponse)(nil), // 13: connect.transcoding.v1.WatchBooksResponse
+}
+var file_connect_transcoding_v1_transcoding_proto_depIdxs = []int32{
+	0,  // 0: connect.transcoding.v1.Book.genre:type_name -> connect.transcoding.v1.Genre
+	1,  // 1: connect.transcoding.v1.GetBookResponse.book:type_name -> connect.transcoding.v1.Book
+	0,  // 2: connect.transcoding.v1.ListBooksRequest.genre:type_name -> connect.transcoding.v1.Genre
+	1,  // 3: connect.transcoding.v1.ListBooksResponse.books:type_name -> connect.transcoding.v1.Book
+	1,  // 4: connect.transcoding.v1.CreateBookRequest.book:type_name -> connect.transcoding.v1.Book
+	1,  // 5: connect.transcoding.v1.CreateBookResponse.book:type_name -> connect.transcoding.v1.Book
+	1,  // 6: connect.transcoding.v1.UpdateBookRequest.book:type_name -> connect.transcoding.v1.Book
+	1,  // 7: connect.transcoding.v1.UpdateBookResponse.book:type_name -> connect.transcoding.v1.Book
+	1,  // 8: connect.transcoding.v1.WatchBooksResponse.book:type_name -> connect.transcoding.v1.Book
+	2,  // 9: connect.transcoding.v1.LibraryService.GetBook:input_type -> connect.transcoding.v1.GetBookRequest
+	4,  // 10: connect.transcoding.v1.LibraryService.ListBooks:input_type -> connect.transcoding.v1.ListBooksRequest
+	6,  // 11: connect.transcoding.v1.LibraryService.CreateBook:input_type -> connect.transcoding.v1.CreateBookRequest
+	8,  // 12: connect.transcoding.v1.LibraryService.UpdateBook:input_type -> connect.transcoding.v1.UpdateBookRequest
+	10, // 13: connect.transcoding.v1.LibraryService.DeleteBook:input_type -> connect.transcoding.v1.DeleteBookRequest
+	12, // 14: connect.transcoding.v1.LibraryService.WatchBooks:input_type -> connect.transcoding.v1.WatchBooksRequest
+	3,  // 15: connect.transcoding.v1.LibraryService.GetBook:output_type -> connect.transcoding.v1.GetBookResponse
+	5,  // 16: connect.transcoding.v1.LibraryService.ListBooks:output_type -> connect.transcoding.v1.ListBooksResponse
+	7,  // 17: connect.transcoding.v1.LibraryService.CreateBook:output_type -> connect.transcoding.v1.CreateBookResponse
+	9,  // 18: connect.transcoding.v1.LibraryService.UpdateBook:output_type -> connect.transcoding.v1.UpdateBookResponse
+	11, // 19: connect.transcoding.v1.LibraryService.DeleteBook:output_type -> connect.transcoding.v1.DeleteBookResponse
+	13, // 20: connect.transcoding.v1.LibraryService.WatchBooks:output_type -> connect.transcoding.v1.WatchBooksResponse
+	15, // [15:21] is the sub-list for method output_type
+	9,  // [9:15] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_connect_transcoding_v1_transcoding_proto_init() }
+func file_connect_transcoding_v1_transcoding_proto_init() {
+	if File_connect_transcoding_v1_transcoding_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_connect_transcoding_v1_transcoding_proto_rawDesc), len(file_connect_transcoding_v1_transcoding_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_connect_transcoding_v1_transcoding_proto_goTypes,
+		DependencyIndexes: file_connect_transcoding_v1_transcoding_proto_depIdxs,
+		EnumInfos:         file_connect_transcoding_v1_transcoding_proto_enumTypes,
+		MessageInfos:      file_connect_transcoding_v1_transcoding_proto_msgTypes,
+	}.Build()
+	File_connect_transcoding_v1_transcoding_proto = out.File
+	file_connect_transcoding_v1_transcoding_proto_goTypes = nil
+	file_connect_transcoding_v1_transcoding_proto_depIdxs = nil
+}
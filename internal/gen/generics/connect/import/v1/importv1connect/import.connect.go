@@ -73,3 +73,25 @@ func NewImportServiceHandler(svc ImportServiceHandler, opts ...scalpel.HandlerOp
 
 // UnimplementedImportServiceHandler returns CodeUnimplemented from all methods.
 type UnimplementedImportServiceHandler struct{}
+
+// FakeImportService is a configurable fake of the connect.import.v1.ImportService service for unit
+// tests. Each method calls the corresponding function field, or returns CodeUnimplemented if the
+// field is nil. Set the fields before making calls.
+//
+// FakeImportService implements ImportServiceHandler and records every call in its Recorder. Use
+// NewFakeImportServiceClient to call the fake in memory, without a transport.
+type FakeImportService struct {
+	Recorder scalpel.FakeRecorder
+}
+
+// NewFakeImportServiceClient returns a ImportServiceClient that calls the fake in memory, without a
+// transport. Messages are copied rather than shared, and headers, trailers, and errors are
+// propagated as though the calls were made over the network.
+func NewFakeImportServiceClient(fake *FakeImportService) ImportServiceClient {
+	return &fakeImportServiceClient{fake: fake}
+}
+
+// fakeImportServiceClient implements ImportServiceClient by calling a FakeImportService.
+type fakeImportServiceClient struct {
+	fake *FakeImportService
+}
@@ -92,6 +92,7 @@ func NewPingServiceClient(httpClient scalpel.HTTPClient, baseURL string, opts ..
 			httpClient,
 			baseURL+PingServicePingProcedure,
 			scalpel.WithSchema(pingServiceMethods.ByName("Ping")),
+			scalpel.WithIdempotency(scalpel.IdempotencyNoSideEffects),
 			scalpel.WithClientOptions(opts...),
 		),
 		fail: scalpel.NewClient[v1.FailRequest, v1.FailResponse](
@@ -179,6 +180,7 @@ func NewPingServiceHandler(svc PingServiceHandler, opts ...scalpel.HandlerOption
 		PingServicePingProcedure,
 		svc.Ping,
 		scalpel.WithSchema(pingServiceMethods.ByName("Ping")),
+		scalpel.WithIdempotency(scalpel.IdempotencyNoSideEffects),
 		scalpel.WithHandlerOptions(opts...),
 	)
 	pingServiceFailHandler := scalpel.NewUnaryHandler(
@@ -245,3 +247,101 @@ func (UnimplementedPingServiceHandler) CountUp(context.Context, *scalpel.Request
 func (UnimplementedPingServiceHandler) CumSum(context.Context, *scalpel.BidiStream[v1.CumSumRequest, v1.CumSumResponse]) error {
 	return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.ping.v1.PingService.CumSum is not implemented"))
 }
+
+// FakePingService is a configurable fake of the connect.ping.v1.PingService service for unit tests.
+// Each method calls the corresponding function field, or returns CodeUnimplemented if the field is
+// nil. Set the fields before making calls.
+//
+// FakePingService implements PingServiceHandler and records every call in its Recorder. Use
+// NewFakePingServiceClient to call the fake in memory, without a transport.
+type FakePingService struct {
+	Recorder scalpel.FakeRecorder
+
+	PingFunc    func(context.Context, *scalpel.Request[v1.PingRequest]) (*scalpel.Response[v1.PingResponse], error)
+	FailFunc    func(context.Context, *scalpel.Request[v1.FailRequest]) (*scalpel.Response[v1.FailResponse], error)
+	SumFunc     func(context.Context, *scalpel.ClientStream[v1.SumRequest]) (*scalpel.Response[v1.SumResponse], error)
+	CountUpFunc func(context.Context, *scalpel.Request[v1.CountUpRequest], *scalpel.ServerStream[v1.CountUpResponse]) error
+	CumSumFunc  func(context.Context, *scalpel.BidiStream[v1.CumSumRequest, v1.CumSumResponse]) error
+}
+
+// Ping records the call and calls PingFunc.
+func (f *FakePingService) Ping(ctx context.Context, req *scalpel.Request[v1.PingRequest]) (*scalpel.Response[v1.PingResponse], error) {
+	f.Recorder.Record(PingServicePingProcedure, req.Msg)
+	if f.PingFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.ping.v1.PingService.Ping is not implemented"))
+	}
+	return f.PingFunc(ctx, req)
+}
+
+// Fail records the call and calls FailFunc.
+func (f *FakePingService) Fail(ctx context.Context, req *scalpel.Request[v1.FailRequest]) (*scalpel.Response[v1.FailResponse], error) {
+	f.Recorder.Record(PingServiceFailProcedure, req.Msg)
+	if f.FailFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.ping.v1.PingService.Fail is not implemented"))
+	}
+	return f.FailFunc(ctx, req)
+}
+
+// Sum records the call and calls SumFunc.
+func (f *FakePingService) Sum(ctx context.Context, stream *scalpel.ClientStream[v1.SumRequest]) (*scalpel.Response[v1.SumResponse], error) {
+	f.Recorder.Record(PingServiceSumProcedure, nil)
+	if f.SumFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.ping.v1.PingService.Sum is not implemented"))
+	}
+	return f.SumFunc(ctx, stream)
+}
+
+// CountUp records the call and calls CountUpFunc.
+func (f *FakePingService) CountUp(ctx context.Context, req *scalpel.Request[v1.CountUpRequest], stream *scalpel.ServerStream[v1.CountUpResponse]) error {
+	f.Recorder.Record(PingServiceCountUpProcedure, req.Msg)
+	if f.CountUpFunc == nil {
+		return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.ping.v1.PingService.CountUp is not implemented"))
+	}
+	return f.CountUpFunc(ctx, req, stream)
+}
+
+// CumSum records the call and calls CumSumFunc.
+func (f *FakePingService) CumSum(ctx context.Context, stream *scalpel.BidiStream[v1.CumSumRequest, v1.CumSumResponse]) error {
+	f.Recorder.Record(PingServiceCumSumProcedure, nil)
+	if f.CumSumFunc == nil {
+		return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.ping.v1.PingService.CumSum is not implemented"))
+	}
+	return f.CumSumFunc(ctx, stream)
+}
+
+// NewFakePingServiceClient returns a PingServiceClient that calls the fake in memory, without a
+// transport. Messages are copied rather than shared, and headers, trailers, and errors are
+// propagated as though the calls were made over the network.
+func NewFakePingServiceClient(fake *FakePingService) PingServiceClient {
+	return &fakePingServiceClient{fake: fake}
+}
+
+// fakePingServiceClient implements PingServiceClient by calling a FakePingService.
+type fakePingServiceClient struct {
+	fake *FakePingService
+}
+
+// Ping calls FakePingService.Ping.
+func (c *fakePingServiceClient) Ping(ctx context.Context, req *scalpel.Request[v1.PingRequest]) (*scalpel.Response[v1.PingResponse], error) {
+	return scalpel.CallFakeUnary(ctx, PingServicePingProcedure, req, c.fake.Ping)
+}
+
+// Fail calls FakePingService.Fail.
+func (c *fakePingServiceClient) Fail(ctx context.Context, req *scalpel.Request[v1.FailRequest]) (*scalpel.Response[v1.FailResponse], error) {
+	return scalpel.CallFakeUnary(ctx, PingServiceFailProcedure, req, c.fake.Fail)
+}
+
+// Sum calls FakePingService.Sum.
+func (c *fakePingServiceClient) Sum(ctx context.Context) *scalpel.ClientStreamForClient[v1.SumRequest, v1.SumResponse] {
+	return scalpel.CallFakeClientStream(ctx, PingServiceSumProcedure, c.fake.Sum)
+}
+
+// CountUp calls FakePingService.CountUp.
+func (c *fakePingServiceClient) CountUp(ctx context.Context, req *scalpel.Request[v1.CountUpRequest]) (*scalpel.ServerStreamForClient[v1.CountUpResponse], error) {
+	return scalpel.CallFakeServerStream(ctx, PingServiceCountUpProcedure, req, c.fake.CountUp)
+}
+
+// CumSum calls FakePingService.CumSum.
+func (c *fakePingServiceClient) CumSum(ctx context.Context) *scalpel.BidiStreamForClient[v1.CumSumRequest, v1.CumSumResponse] {
+	return scalpel.CallFakeBidiStream(ctx, PingServiceCumSumProcedure, c.fake.CumSum)
+}
@@ -122,3 +122,41 @@ type UnimplementedCollideServiceHandler struct{}
 func (UnimplementedCollideServiceHandler) Import(context.Context, *v1.ImportRequest) (*v1.ImportResponse, error) {
 	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.collide.v1.CollideService.Import is not implemented"))
 }
+
+// FakeCollideService is a configurable fake of the connect.collide.v1.CollideService service for
+// unit tests. Each method calls the corresponding function field, or returns CodeUnimplemented if
+// the field is nil. Set the fields before making calls.
+//
+// FakeCollideService implements CollideServiceHandler and records every call in its Recorder. Use
+// NewFakeCollideServiceClient to call the fake in memory, without a transport.
+type FakeCollideService struct {
+	Recorder scalpel.FakeRecorder
+
+	ImportFunc func(context.Context, *v1.ImportRequest) (*v1.ImportResponse, error)
+}
+
+// Import records the call and calls ImportFunc.
+func (f *FakeCollideService) Import(ctx context.Context, req *v1.ImportRequest) (*v1.ImportResponse, error) {
+	f.Recorder.Record(CollideServiceImportProcedure, req)
+	if f.ImportFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.collide.v1.CollideService.Import is not implemented"))
+	}
+	return f.ImportFunc(ctx, req)
+}
+
+// NewFakeCollideServiceClient returns a CollideServiceClient that calls the fake in memory, without
+// a transport. Messages are copied rather than shared, and headers, trailers, and errors are
+// propagated as though the calls were made over the network.
+func NewFakeCollideServiceClient(fake *FakeCollideService) CollideServiceClient {
+	return &fakeCollideServiceClient{fake: fake}
+}
+
+// fakeCollideServiceClient implements CollideServiceClient by calling a FakeCollideService.
+type fakeCollideServiceClient struct {
+	fake *FakeCollideService
+}
+
+// Import calls FakeCollideService.Import.
+func (c *fakeCollideServiceClient) Import(ctx context.Context, req *v1.ImportRequest) (*v1.ImportResponse, error) {
+	return scalpel.CallFakeUnarySimple(ctx, CollideServiceImportProcedure, req, c.fake.Import)
+}
@@ -0,0 +1,402 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-scalpel-go. DO NOT EDIT.
+//
+// Source: connect/transcoding/v1/transcoding.proto
+
+// The connect.transcoding.v1 package contains a small REST-style service
+// designed to test HTTP/JSON transcoding.
+package transcodingv1connect
+
+import (
+	context "context"
+	errors "errors"
+	scalpel "github.com/agentio/scalpel"
+	v1 "github.com/agentio/scalpel/internal/gen/connect/transcoding/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = scalpel.IsAtLeastVersion1_13_0
+
+const (
+	// LibraryServiceName is the fully-qualified name of the LibraryService service.
+	LibraryServiceName = "connect.transcoding.v1.LibraryService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// LibraryServiceGetBookProcedure is the fully-qualified name of the LibraryService's GetBook RPC.
+	LibraryServiceGetBookProcedure = "/connect.transcoding.v1.LibraryService/GetBook"
+	// LibraryServiceListBooksProcedure is the fully-qualified name of the LibraryService's ListBooks
+	// RPC.
+	LibraryServiceListBooksProcedure = "/connect.transcoding.v1.LibraryService/ListBooks"
+	// LibraryServiceCreateBookProcedure is the fully-qualified name of the LibraryService's CreateBook
+	// RPC.
+	LibraryServiceCreateBookProcedure = "/connect.transcoding.v1.LibraryService/CreateBook"
+	// LibraryServiceUpdateBookProcedure is the fully-qualified name of the LibraryService's UpdateBook
+	// RPC.
+	LibraryServiceUpdateBookProcedure = "/connect.transcoding.v1.LibraryService/UpdateBook"
+	// LibraryServiceDeleteBookProcedure is the fully-qualified name of the LibraryService's DeleteBook
+	// RPC.
+	LibraryServiceDeleteBookProcedure = "/connect.transcoding.v1.LibraryService/DeleteBook"
+	// LibraryServiceWatchBooksProcedure is the fully-qualified name of the LibraryService's WatchBooks
+	// RPC.
+	LibraryServiceWatchBooksProcedure = "/connect.transcoding.v1.LibraryService/WatchBooks"
+)
+
+// LibraryServiceClient is a client for the connect.transcoding.v1.LibraryService service.
+type LibraryServiceClient interface {
+	GetBook(context.Context, *v1.GetBookRequest) (*v1.GetBookResponse, error)
+	ListBooks(context.Context, *v1.ListBooksRequest) (*v1.ListBooksResponse, error)
+	CreateBook(context.Context, *v1.CreateBookRequest) (*v1.CreateBookResponse, error)
+	UpdateBook(context.Context, *v1.UpdateBookRequest) (*v1.UpdateBookResponse, error)
+	DeleteBook(context.Context, *v1.DeleteBookRequest) (*v1.DeleteBookResponse, error)
+	// Transcoding only applies to unary methods, so this rule is ignored.
+	WatchBooks(context.Context, *v1.WatchBooksRequest) (*scalpel.ServerStreamForClient[v1.WatchBooksResponse], error)
+}
+
+// NewLibraryServiceClient constructs a client for the connect.transcoding.v1.LibraryService
+// service. By default, it uses the gRPCConnect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewLibraryServiceClient(httpClient scalpel.HTTPClient, baseURL string, opts ...scalpel.ClientOption) LibraryServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	libraryServiceMethods := v1.File_connect_transcoding_v1_transcoding_proto.Services().ByName("LibraryService").Methods()
+	return &libraryServiceClient{
+		getBook: scalpel.NewClient[v1.GetBookRequest, v1.GetBookResponse](
+			httpClient,
+			baseURL+LibraryServiceGetBookProcedure,
+			scalpel.WithSchema(libraryServiceMethods.ByName("GetBook")),
+			scalpel.WithClientOptions(opts...),
+		),
+		listBooks: scalpel.NewClient[v1.ListBooksRequest, v1.ListBooksResponse](
+			httpClient,
+			baseURL+LibraryServiceListBooksProcedure,
+			scalpel.WithSchema(libraryServiceMethods.ByName("ListBooks")),
+			scalpel.WithClientOptions(opts...),
+		),
+		createBook: scalpel.NewClient[v1.CreateBookRequest, v1.CreateBookResponse](
+			httpClient,
+			baseURL+LibraryServiceCreateBookProcedure,
+			scalpel.WithSchema(libraryServiceMethods.ByName("CreateBook")),
+			scalpel.WithClientOptions(opts...),
+		),
+		updateBook: scalpel.NewClient[v1.UpdateBookRequest, v1.UpdateBookResponse](
+			httpClient,
+			baseURL+LibraryServiceUpdateBookProcedure,
+			scalpel.WithSchema(libraryServiceMethods.ByName("UpdateBook")),
+			scalpel.WithClientOptions(opts...),
+		),
+		deleteBook: scalpel.NewClient[v1.DeleteBookRequest, v1.DeleteBookResponse](
+			httpClient,
+			baseURL+LibraryServiceDeleteBookProcedure,
+			scalpel.WithSchema(libraryServiceMethods.ByName("DeleteBook")),
+			scalpel.WithClientOptions(opts...),
+		),
+		watchBooks: scalpel.NewClient[v1.WatchBooksRequest, v1.WatchBooksResponse](
+			httpClient,
+			baseURL+LibraryServiceWatchBooksProcedure,
+			scalpel.WithSchema(libraryServiceMethods.ByName("WatchBooks")),
+			scalpel.WithClientOptions(opts...),
+		),
+	}
+}
+
+// libraryServiceClient implements LibraryServiceClient.
+type libraryServiceClient struct {
+	getBook    *scalpel.Client[v1.GetBookRequest, v1.GetBookResponse]
+	listBooks  *scalpel.Client[v1.ListBooksRequest, v1.ListBooksResponse]
+	createBook *scalpel.Client[v1.CreateBookRequest, v1.CreateBookResponse]
+	updateBook *scalpel.Client[v1.UpdateBookRequest, v1.UpdateBookResponse]
+	deleteBook *scalpel.Client[v1.DeleteBookRequest, v1.DeleteBookResponse]
+	watchBooks *scalpel.Client[v1.WatchBooksRequest, v1.WatchBooksResponse]
+}
+
+// GetBook calls connect.transcoding.v1.LibraryService.GetBook.
+func (c *libraryServiceClient) GetBook(ctx context.Context, req *v1.GetBookRequest) (*v1.GetBookResponse, error) {
+	response, err := c.getBook.CallUnary(ctx, scalpel.NewRequest(req))
+	if response != nil {
+		return response.Msg, err
+	}
+	return nil, err
+}
+
+// ListBooks calls connect.transcoding.v1.LibraryService.ListBooks.
+func (c *libraryServiceClient) ListBooks(ctx context.Context, req *v1.ListBooksRequest) (*v1.ListBooksResponse, error) {
+	response, err := c.listBooks.CallUnary(ctx, scalpel.NewRequest(req))
+	if response != nil {
+		return response.Msg, err
+	}
+	return nil, err
+}
+
+// CreateBook calls connect.transcoding.v1.LibraryService.CreateBook.
+func (c *libraryServiceClient) CreateBook(ctx context.Context, req *v1.CreateBookRequest) (*v1.CreateBookResponse, error) {
+	response, err := c.createBook.CallUnary(ctx, scalpel.NewRequest(req))
+	if response != nil {
+		return response.Msg, err
+	}
+	return nil, err
+}
+
+// UpdateBook calls connect.transcoding.v1.LibraryService.UpdateBook.
+func (c *libraryServiceClient) UpdateBook(ctx context.Context, req *v1.UpdateBookRequest) (*v1.UpdateBookResponse, error) {
+	response, err := c.updateBook.CallUnary(ctx, scalpel.NewRequest(req))
+	if response != nil {
+		return response.Msg, err
+	}
+	return nil, err
+}
+
+// DeleteBook calls connect.transcoding.v1.LibraryService.DeleteBook.
+func (c *libraryServiceClient) DeleteBook(ctx context.Context, req *v1.DeleteBookRequest) (*v1.DeleteBookResponse, error) {
+	response, err := c.deleteBook.CallUnary(ctx, scalpel.NewRequest(req))
+	if response != nil {
+		return response.Msg, err
+	}
+	return nil, err
+}
+
+// WatchBooks calls connect.transcoding.v1.LibraryService.WatchBooks.
+func (c *libraryServiceClient) WatchBooks(ctx context.Context, req *v1.WatchBooksRequest) (*scalpel.ServerStreamForClient[v1.WatchBooksResponse], error) {
+	return c.watchBooks.CallServerStream(ctx, scalpel.NewRequest(req))
+}
+
+// LibraryServiceHandler is an implementation of the connect.transcoding.v1.LibraryService service.
+type LibraryServiceHandler interface {
+	GetBook(context.Context, *v1.GetBookRequest) (*v1.GetBookResponse, error)
+	ListBooks(context.Context, *v1.ListBooksRequest) (*v1.ListBooksResponse, error)
+	CreateBook(context.Context, *v1.CreateBookRequest) (*v1.CreateBookResponse, error)
+	UpdateBook(context.Context, *v1.UpdateBookRequest) (*v1.UpdateBookResponse, error)
+	DeleteBook(context.Context, *v1.DeleteBookRequest) (*v1.DeleteBookResponse, error)
+	// Transcoding only applies to unary methods, so this rule is ignored.
+	WatchBooks(context.Context, *v1.WatchBooksRequest, *scalpel.ServerStream[v1.WatchBooksResponse]) error
+}
+
+// NewLibraryServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the gRPC protocol with the binary Protobuf codecs.
+func NewLibraryServiceHandler(svc LibraryServiceHandler, opts ...scalpel.HandlerOption) (string, http.Handler) {
+	libraryServiceMethods := v1.File_connect_transcoding_v1_transcoding_proto.Services().ByName("LibraryService").Methods()
+	libraryServiceGetBookHandler := scalpel.NewUnaryHandlerSimple(
+		LibraryServiceGetBookProcedure,
+		svc.GetBook,
+		scalpel.WithSchema(libraryServiceMethods.ByName("GetBook")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	libraryServiceListBooksHandler := scalpel.NewUnaryHandlerSimple(
+		LibraryServiceListBooksProcedure,
+		svc.ListBooks,
+		scalpel.WithSchema(libraryServiceMethods.ByName("ListBooks")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	libraryServiceCreateBookHandler := scalpel.NewUnaryHandlerSimple(
+		LibraryServiceCreateBookProcedure,
+		svc.CreateBook,
+		scalpel.WithSchema(libraryServiceMethods.ByName("CreateBook")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	libraryServiceUpdateBookHandler := scalpel.NewUnaryHandlerSimple(
+		LibraryServiceUpdateBookProcedure,
+		svc.UpdateBook,
+		scalpel.WithSchema(libraryServiceMethods.ByName("UpdateBook")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	libraryServiceDeleteBookHandler := scalpel.NewUnaryHandlerSimple(
+		LibraryServiceDeleteBookProcedure,
+		svc.DeleteBook,
+		scalpel.WithSchema(libraryServiceMethods.ByName("DeleteBook")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	libraryServiceWatchBooksHandler := scalpel.NewServerStreamHandlerSimple(
+		LibraryServiceWatchBooksProcedure,
+		svc.WatchBooks,
+		scalpel.WithSchema(libraryServiceMethods.ByName("WatchBooks")),
+		scalpel.WithHandlerOptions(opts...),
+	)
+	return "/connect.transcoding.v1.LibraryService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case LibraryServiceGetBookProcedure:
+			libraryServiceGetBookHandler.ServeHTTP(w, r)
+		case LibraryServiceListBooksProcedure:
+			libraryServiceListBooksHandler.ServeHTTP(w, r)
+		case LibraryServiceCreateBookProcedure:
+			libraryServiceCreateBookHandler.ServeHTTP(w, r)
+		case LibraryServiceUpdateBookProcedure:
+			libraryServiceUpdateBookHandler.ServeHTTP(w, r)
+		case LibraryServiceDeleteBookProcedure:
+			libraryServiceDeleteBookHandler.ServeHTTP(w, r)
+		case LibraryServiceWatchBooksProcedure:
+			libraryServiceWatchBooksHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedLibraryServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedLibraryServiceHandler struct{}
+
+func (UnimplementedLibraryServiceHandler) GetBook(context.Context, *v1.GetBookRequest) (*v1.GetBookResponse, error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.GetBook is not implemented"))
+}
+
+func (UnimplementedLibraryServiceHandler) ListBooks(context.Context, *v1.ListBooksRequest) (*v1.ListBooksResponse, error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.ListBooks is not implemented"))
+}
+
+func (UnimplementedLibraryServiceHandler) CreateBook(context.Context, *v1.CreateBookRequest) (*v1.CreateBookResponse, error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.CreateBook is not implemented"))
+}
+
+func (UnimplementedLibraryServiceHandler) UpdateBook(context.Context, *v1.UpdateBookRequest) (*v1.UpdateBookResponse, error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.UpdateBook is not implemented"))
+}
+
+func (UnimplementedLibraryServiceHandler) DeleteBook(context.Context, *v1.DeleteBookRequest) (*v1.DeleteBookResponse, error) {
+	return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.DeleteBook is not implemented"))
+}
+
+func (UnimplementedLibraryServiceHandler) WatchBooks(context.Context, *v1.WatchBooksRequest, *scalpel.ServerStream[v1.WatchBooksResponse]) error {
+	return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.WatchBooks is not implemented"))
+}
+
+// FakeLibraryService is a configurable fake of the connect.transcoding.v1.LibraryService service
+// for unit tests. Each method calls the corresponding function field, or returns CodeUnimplemented
+// if the field is nil. Set the fields before making calls.
+//
+// FakeLibraryService implements LibraryServiceHandler and records every call in its Recorder. Use
+// NewFakeLibraryServiceClient to call the fake in memory, without a transport.
+type FakeLibraryService struct {
+	Recorder scalpel.FakeRecorder
+
+	GetBookFunc    func(context.Context, *v1.GetBookRequest) (*v1.GetBookResponse, error)
+	ListBooksFunc  func(context.Context, *v1.ListBooksRequest) (*v1.ListBooksResponse, error)
+	CreateBookFunc func(context.Context, *v1.CreateBookRequest) (*v1.CreateBookResponse, error)
+	UpdateBookFunc func(context.Context, *v1.UpdateBookRequest) (*v1.UpdateBookResponse, error)
+	DeleteBookFunc func(context.Context, *v1.DeleteBookRequest) (*v1.DeleteBookResponse, error)
+	WatchBooksFunc func(context.Context, *v1.WatchBooksRequest, *scalpel.ServerStream[v1.WatchBooksResponse]) error
+}
+
+// GetBook records the call and calls GetBookFunc.
+func (f *FakeLibraryService) GetBook(ctx context.Context, req *v1.GetBookRequest) (*v1.GetBookResponse, error) {
+	f.Recorder.Record(LibraryServiceGetBookProcedure, req)
+	if f.GetBookFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.GetBook is not implemented"))
+	}
+	return f.GetBookFunc(ctx, req)
+}
+
+// ListBooks records the call and calls ListBooksFunc.
+func (f *FakeLibraryService) ListBooks(ctx context.Context, req *v1.ListBooksRequest) (*v1.ListBooksResponse, error) {
+	f.Recorder.Record(LibraryServiceListBooksProcedure, req)
+	if f.ListBooksFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.ListBooks is not implemented"))
+	}
+	return f.ListBooksFunc(ctx, req)
+}
+
+// CreateBook records the call and calls CreateBookFunc.
+func (f *FakeLibraryService) CreateBook(ctx context.Context, req *v1.CreateBookRequest) (*v1.CreateBookResponse, error) {
+	f.Recorder.Record(LibraryServiceCreateBookProcedure, req)
+	if f.CreateBookFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.CreateBook is not implemented"))
+	}
+	return f.CreateBookFunc(ctx, req)
+}
+
+// UpdateBook records the call and calls UpdateBookFunc.
+func (f *FakeLibraryService) UpdateBook(ctx context.Context, req *v1.UpdateBookRequest) (*v1.UpdateBookResponse, error) {
+	f.Recorder.Record(LibraryServiceUpdateBookProcedure, req)
+	if f.UpdateBookFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.UpdateBook is not implemented"))
+	}
+	return f.UpdateBookFunc(ctx, req)
+}
+
+// DeleteBook records the call and calls DeleteBookFunc.
+func (f *FakeLibraryService) DeleteBook(ctx context.Context, req *v1.DeleteBookRequest) (*v1.DeleteBookResponse, error) {
+	f.Recorder.Record(LibraryServiceDeleteBookProcedure, req)
+	if f.DeleteBookFunc == nil {
+		return nil, scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.DeleteBook is not implemented"))
+	}
+	return f.DeleteBookFunc(ctx, req)
+}
+
+// WatchBooks records the call and calls WatchBooksFunc.
+func (f *FakeLibraryService) WatchBooks(ctx context.Context, req *v1.WatchBooksRequest, stream *scalpel.ServerStream[v1.WatchBooksResponse]) error {
+	f.Recorder.Record(LibraryServiceWatchBooksProcedure, req)
+	if f.WatchBooksFunc == nil {
+		return scalpel.NewError(scalpel.CodeUnimplemented, errors.New("connect.transcoding.v1.LibraryService.WatchBooks is not implemented"))
+	}
+	return f.WatchBooksFunc(ctx, req, stream)
+}
+
+// NewFakeLibraryServiceClient returns a LibraryServiceClient that calls the fake in memory, without
+// a transport. Messages are copied rather than shared, and headers, trailers, and errors are
+// propagated as though the calls were made over the network.
+func NewFakeLibraryServiceClient(fake *FakeLibraryService) LibraryServiceClient {
+	return &fakeLibraryServiceClient{fake: fake}
+}
+
+// fakeLibraryServiceClient implements LibraryServiceClient by calling a FakeLibraryService.
+type fakeLibraryServiceClient struct {
+	fake *FakeLibraryService
+}
+
+// GetBook calls FakeLibraryService.GetBook.
+func (c *fakeLibraryServiceClient) GetBook(ctx context.Context, req *v1.GetBookRequest) (*v1.GetBookResponse, error) {
+	return scalpel.CallFakeUnarySimple(ctx, LibraryServiceGetBookProcedure, req, c.fake.GetBook)
+}
+
+// ListBooks calls FakeLibraryService.ListBooks.
+func (c *fakeLibraryServiceClient) ListBooks(ctx context.Context, req *v1.ListBooksRequest) (*v1.ListBooksResponse, error) {
+	return scalpel.CallFakeUnarySimple(ctx, LibraryServiceListBooksProcedure, req, c.fake.ListBooks)
+}
+
+// CreateBook calls FakeLibraryService.CreateBook.
+func (c *fakeLibraryServiceClient) CreateBook(ctx context.Context, req *v1.CreateBookRequest) (*v1.CreateBookResponse, error) {
+	return scalpel.CallFakeUnarySimple(ctx, LibraryServiceCreateBookProcedure, req, c.fake.CreateBook)
+}
+
+// UpdateBook calls FakeLibraryService.UpdateBook.
+func (c *fakeLibraryServiceClient) UpdateBook(ctx context.Context, req *v1.UpdateBookRequest) (*v1.UpdateBookResponse, error) {
+	return scalpel.CallFakeUnarySimple(ctx, LibraryServiceUpdateBookProcedure, req, c.fake.UpdateBook)
+}
+
+// DeleteBook calls FakeLibraryService.DeleteBook.
+func (c *fakeLibraryServiceClient) DeleteBook(ctx context.Context, req *v1.DeleteBookRequest) (*v1.DeleteBookResponse, error) {
+	return scalpel.CallFakeUnarySimple(ctx, LibraryServiceDeleteBookProcedure, req, c.fake.DeleteBook)
+}
+
+// WatchBooks calls FakeLibraryService.WatchBooks.
+func (c *fakeLibraryServiceClient) WatchBooks(ctx context.Context, req *v1.WatchBooksRequest) (*scalpel.ServerStreamForClient[v1.WatchBooksResponse], error) {
+	return scalpel.CallFakeServerStreamSimple(ctx, LibraryServiceWatchBooksProcedure, req, c.fake.WatchBooks)
+}
@@ -0,0 +1,301 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging logs RPCs with [log/slog]. It works with clients and
+// handlers, and with the gRPC, gRPC-Web, and Connect protocols.
+//
+// Each RPC is logged once, when it ends. Streaming RPCs are summarized in a
+// single entry rather than logged message by message.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/agentio/scalpel"
+)
+
+// A Logger logs RPCs to a [slog.Logger]. It's both an interceptor, which
+// records the peer, and a stats handler, which counts messages and bytes and
+// logs each RPC as it ends. Install it with [Logger.Option], which registers
+// both.
+//
+// A single Logger may be shared by any number of clients and handlers.
+type Logger struct {
+	logger         *slog.Logger
+	sample         func(scalpel.Spec, scalpel.Code) bool
+	level          func(scalpel.Spec, scalpel.Code) slog.Level
+	requestHeaders []string
+}
+
+// New constructs a Logger.
+func New(logger *slog.Logger, options ...Option) *Logger {
+	l := &Logger{
+		logger: logger,
+		sample: func(scalpel.Spec, scalpel.Code) bool { return true },
+		level:  DefaultLevel,
+	}
+	for _, option := range options {
+		option.apply(l)
+	}
+	return l
+}
+
+// Option returns a [scalpel.Option] that installs the Logger on a client or
+// handler.
+func (l *Logger) Option() scalpel.Option {
+	return scalpel.WithOptions(
+		scalpel.WithInterceptors(l),
+		scalpel.WithStatsHandler(l),
+	)
+}
+
+// WrapUnary implements [scalpel.Interceptor].
+func (l *Logger) WrapUnary(next scalpel.UnaryFunc) scalpel.UnaryFunc {
+	return func(ctx context.Context, request scalpel.AnyRequest) (scalpel.AnyResponse, error) {
+		ctx, rpc := l.rpcForContext(ctx, request.Spec())
+		rpc.setPeer(request.Peer())
+		return next(ctx, request)
+	}
+}
+
+// WrapStreamingClient implements [scalpel.Interceptor].
+func (l *Logger) WrapStreamingClient(next scalpel.StreamingClientFunc) scalpel.StreamingClientFunc {
+	return func(ctx context.Context, spec scalpel.Spec) scalpel.StreamingClientConn {
+		ctx, rpc := l.rpcForContext(ctx, spec)
+		conn := next(ctx, spec)
+		rpc.setPeer(conn.Peer())
+		return conn
+	}
+}
+
+// WrapStreamingHandler implements [scalpel.Interceptor].
+func (l *Logger) WrapStreamingHandler(next scalpel.StreamingHandlerFunc) scalpel.StreamingHandlerFunc {
+	return func(ctx context.Context, conn scalpel.StreamingHandlerConn) error {
+		ctx, rpc := l.rpcForContext(ctx, conn.Spec())
+		rpc.setPeer(conn.Peer())
+		return next(ctx, conn)
+	}
+}
+
+// TagRPC implements [scalpel.StatsHandler].
+func (l *Logger) TagRPC(ctx context.Context, spec scalpel.Spec) context.Context {
+	ctx, _ = l.rpcForContext(ctx, spec)
+	return ctx
+}
+
+// HandleRPC implements [scalpel.StatsHandler].
+func (l *Logger) HandleRPC(ctx context.Context, event scalpel.StatsEvent) {
+	rpc, ok := ctx.Value(rpcKey{logger: l}).(*rpcInfo)
+	if !ok {
+		return
+	}
+	switch event := event.(type) {
+	case *scalpel.StatsOutHeader:
+		if event.Client && event.Attempt == 1 {
+			rpc.setHeader(event.Header, l.requestHeaders)
+		}
+	case *scalpel.StatsInHeader:
+		if !event.Client {
+			rpc.setHeader(event.Header, l.requestHeaders)
+		}
+	case *scalpel.StatsOutPayload:
+		rpc.messagesSent.Add(1)
+		rpc.bytesSent.Add(int64(event.WireLength))
+	case *scalpel.StatsInPayload:
+		rpc.messagesReceived.Add(1)
+		rpc.bytesReceived.Add(int64(event.WireLength))
+	case *scalpel.StatsEnd:
+		l.log(ctx, rpc, event)
+	}
+}
+
+func (l *Logger) log(ctx context.Context, rpc *rpcInfo, end *scalpel.StatsEnd) {
+	code := end.Code()
+	if !l.sample(rpc.spec, code) {
+		return
+	}
+	level := l.level(rpc.spec, code)
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	rpc.mu.Lock()
+	peer, header := rpc.peer, rpc.header
+	rpc.mu.Unlock()
+	message := "finished server call"
+	if rpc.spec.IsClient {
+		message = "finished client call"
+	}
+	codeName := "ok"
+	if code != 0 {
+		codeName = code.String()
+	}
+	attrs := make([]slog.Attr, 0, 12)
+	attrs = append(attrs,
+		slog.String("procedure", rpc.spec.Procedure),
+		slog.String("stream_type", rpc.spec.StreamType.String()),
+	)
+	if peer.Addr != "" {
+		attrs = append(attrs, slog.String("peer", peer.Addr))
+	}
+	if peer.Protocol != "" {
+		attrs = append(attrs, slog.String("protocol", peer.Protocol))
+	}
+	attrs = append(attrs,
+		slog.String("code", codeName),
+		slog.Duration("duration", end.Duration()),
+		slog.Int64("messages_sent", rpc.messagesSent.Load()),
+		slog.Int64("messages_received", rpc.messagesReceived.Load()),
+		slog.Int64("bytes_sent", rpc.bytesSent.Load()),
+		slog.Int64("bytes_received", rpc.bytesReceived.Load()),
+	)
+	if end.Error != nil {
+		attrs = append(attrs, slog.String("error", end.Error.Error()))
+	}
+	if len(header) > 0 {
+		attrs = append(attrs, slog.Any("request_header", slog.GroupValue(header...)))
+	}
+	l.logger.LogAttrs(ctx, level, message, attrs...)
+}
+
+// rpcForContext returns the RPC's info from the context, adding it if
+// necessary. Handlers often make client calls with their own context, so the
+// side of the RPC must match.
+func (l *Logger) rpcForContext(ctx context.Context, spec scalpel.Spec) (context.Context, *rpcInfo) {
+	key := rpcKey{logger: l}
+	if rpc, ok := ctx.Value(key).(*rpcInfo); ok && rpc.spec.IsClient == spec.IsClient {
+		return ctx, rpc
+	}
+	rpc := &rpcInfo{spec: spec}
+	return context.WithValue(ctx, key, rpc), rpc
+}
+
+// DefaultLevel is the default mapping from an RPC's outcome to a log level.
+// Successful RPCs and errors that are usually the caller's fault are logged at
+// [slog.LevelInfo], errors that suggest an overloaded or misconfigured system
+// at [slog.LevelWarn], and errors that suggest a bug at [slog.LevelError].
+func DefaultLevel(_ scalpel.Spec, code scalpel.Code) slog.Level {
+	switch code {
+	case 0, scalpel.CodeCanceled, scalpel.CodeInvalidArgument, scalpel.CodeNotFound,
+		scalpel.CodeAlreadyExists, scalpel.CodeUnauthenticated:
+		return slog.LevelInfo
+	case scalpel.CodeDeadlineExceeded, scalpel.CodePermissionDenied, scalpel.CodeResourceExhausted,
+		scalpel.CodeFailedPrecondition, scalpel.CodeAborted, scalpel.CodeOutOfRange,
+		scalpel.CodeUnavailable:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// An Option configures a Logger.
+type Option interface {
+	apply(*Logger)
+}
+
+// WithLevel sets the function that chooses the level of each RPC's entry from
+// its outcome. The code is zero for RPCs that succeed. By default, Loggers
+// use [DefaultLevel].
+func WithLevel(level func(spec scalpel.Spec, code scalpel.Code) slog.Level) Option {
+	return &levelOption{level: level}
+}
+
+// WithSampler sets the function that decides whether to log each RPC. The
+// code is zero for RPCs that succeed. By default, Loggers log every RPC.
+func WithSampler(sample func(spec scalpel.Spec, code scalpel.Code) bool) Option {
+	return &samplerOption{sample: sample}
+}
+
+// WithSampleRate logs a random fraction of successful RPCs, between 0 and 1.
+// RPCs that fail are always logged. It's a shorthand for [WithSampler].
+func WithSampleRate(rate float64) Option {
+	return WithSampler(func(_ scalpel.Spec, code scalpel.Code) bool {
+		return code != 0 || rand.Float64() < rate //nolint:gosec
+	})
+}
+
+// WithRequestHeaders includes the named request headers in each entry, in a
+// group named "request_header". Absent headers are omitted, and headers with
+// multiple values are joined with commas. Take care not to log credentials.
+func WithRequestHeaders(names ...string) Option {
+	return &requestHeadersOption{names: names}
+}
+
+type rpcKey struct {
+	logger *Logger
+}
+
+// rpcInfo accumulates the details of a single RPC.
+type rpcInfo struct {
+	spec scalpel.Spec
+
+	messagesSent     atomic.Int64
+	messagesReceived atomic.Int64
+	bytesSent        atomic.Int64
+	bytesReceived    atomic.Int64
+
+	mu     sync.Mutex
+	peer   scalpel.Peer
+	header []slog.Attr
+}
+
+func (r *rpcInfo) setPeer(peer scalpel.Peer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.peer = peer
+}
+
+func (r *rpcInfo) setHeader(header http.Header, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			attrs = append(attrs, slog.String(strings.ToLower(name), strings.Join(values, ",")))
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.header = attrs
+}
+
+type levelOption struct {
+	level func(scalpel.Spec, scalpel.Code) slog.Level
+}
+
+func (o *levelOption) apply(l *Logger) {
+	l.level = o.level
+}
+
+type samplerOption struct {
+	sample func(scalpel.Spec, scalpel.Code) bool
+}
+
+func (o *samplerOption) apply(l *Logger) {
+	l.sample = o.sample
+}
+
+type requestHeadersOption struct {
+	names []string
+}
+
+func (o *requestHeadersOption) apply(l *Logger) {
+	l.requestHeaders = append(l.requestHeaders, o.names...)
+}
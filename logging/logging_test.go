@@ -0,0 +1,226 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"github.com/agentio/scalpel/logging"
+)
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+	protocols := []struct {
+		name string
+		opt  connect.ClientOption
+	}{
+		{connect.ProtocolConnect, connect.WithConnect()},
+		{connect.ProtocolGRPC, connect.WithGRPC()},
+		{connect.ProtocolGRPCWeb, connect.WithGRPCWeb()},
+	}
+	for _, protocol := range protocols {
+		t.Run(protocol.name, func(t *testing.T) {
+			t.Parallel()
+			serverLogs, clientLogs := &recordingHandler{}, &recordingHandler{}
+			mux := http.NewServeMux()
+			mux.Handle(pingv1connect.NewPingServiceHandler(
+				pingServer{},
+				logging.New(slog.New(serverLogs), logging.WithRequestHeaders("X-Tenant")).Option(),
+			))
+			server := memhttptest.NewServer(t, mux)
+			client := pingv1connect.NewPingServiceClient(
+				server.Client(),
+				server.URL(),
+				protocol.opt,
+				logging.New(slog.New(clientLogs), logging.WithRequestHeaders("X-Tenant")).Option(),
+			)
+
+			request := connect.NewRequest(&pingv1.PingRequest{Number: 42})
+			request.Header().Set("X-Tenant", "acme")
+			_, err := client.Ping(t.Context(), request)
+			assert.Nil(t, err)
+			for _, logs := range []*recordingHandler{clientLogs, serverLogs} {
+				record := logs.wait(t, 1)[0]
+				assert.Equal(t, record.Level, slog.LevelInfo)
+				attrs := recordAttrs(record)
+				assert.Equal(t, attrs["procedure"], pingv1connect.PingServicePingProcedure)
+				assert.Equal(t, attrs["stream_type"], "unary")
+				assert.Equal(t, attrs["protocol"], protocol.name)
+				assert.Equal(t, attrs["code"], "ok")
+				assert.Equal(t, attrs["messages_sent"], "1")
+				assert.Equal(t, attrs["messages_received"], "1")
+				assert.Equal(t, attrs["request_header.x-tenant"], "acme")
+				assert.NotZero(t, attrs["peer"])
+				_, ok := attrs["error"]
+				assert.False(t, ok)
+			}
+			assert.Equal(t, clientLogs.wait(t, 1)[0].Message, "finished client call")
+			assert.Equal(t, serverLogs.wait(t, 1)[0].Message, "finished server call")
+			// Both sides agree on the bytes exchanged.
+			clientAttrs, serverAttrs := recordAttrs(clientLogs.wait(t, 1)[0]), recordAttrs(serverLogs.wait(t, 1)[0])
+			assert.Equal(t, clientAttrs["bytes_sent"], serverAttrs["bytes_received"])
+			assert.Equal(t, clientAttrs["bytes_received"], serverAttrs["bytes_sent"])
+
+			// Streams are logged once, when they end.
+			stream, err := client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{Number: 3}))
+			assert.Nil(t, err)
+			for stream.Receive() {
+			}
+			assert.Nil(t, stream.Err())
+			assert.Nil(t, stream.Close())
+			for _, logs := range []*recordingHandler{clientLogs, serverLogs} {
+				records := logs.wait(t, 2)
+				assert.Equal(t, len(records), 2)
+				attrs := recordAttrs(records[1])
+				assert.Equal(t, attrs["procedure"], pingv1connect.PingServiceCountUpProcedure)
+				assert.Equal(t, attrs["stream_type"], "server")
+			}
+			assert.Equal(t, recordAttrs(clientLogs.wait(t, 2)[1])["messages_received"], "3")
+			assert.Equal(t, recordAttrs(serverLogs.wait(t, 2)[1])["messages_sent"], "3")
+
+			_, err = client.Fail(t.Context(), connect.NewRequest(&pingv1.FailRequest{Code: int32(connect.CodeInternal)}))
+			assert.Equal(t, connect.CodeOf(err), connect.CodeInternal)
+			for _, logs := range []*recordingHandler{clientLogs, serverLogs} {
+				record := logs.wait(t, 3)[2]
+				assert.Equal(t, record.Level, slog.LevelError)
+				attrs := recordAttrs(record)
+				assert.Equal(t, attrs["code"], "internal")
+				assert.NotZero(t, attrs["error"])
+			}
+		})
+	}
+}
+
+func TestLoggerOptions(t *testing.T) {
+	t.Parallel()
+	logs := &recordingHandler{}
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(
+		pingServer{},
+		logging.New(
+			slog.New(logs),
+			logging.WithSampleRate(0),
+			logging.WithLevel(func(_ connect.Spec, code connect.Code) slog.Level {
+				if code == connect.CodeNotFound {
+					return slog.LevelWarn
+				}
+				return slog.LevelInfo
+			}),
+		).Option(),
+	))
+	server := memhttptest.NewServer(t, mux)
+	client := pingv1connect.NewPingServiceClient(server.Client(), server.URL())
+	for range 10 {
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assert.Nil(t, err)
+	}
+	// Failures are logged regardless of the sample rate.
+	_, err := client.Fail(t.Context(), connect.NewRequest(&pingv1.FailRequest{Code: int32(connect.CodeNotFound)}))
+	assert.Equal(t, connect.CodeOf(err), connect.CodeNotFound)
+	records := logs.wait(t, 1)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Level, slog.LevelWarn)
+	assert.Equal(t, recordAttrs(records[0])["code"], "not_found")
+}
+
+type pingServer struct {
+	pingv1connect.UnimplementedPingServiceHandler
+}
+
+func (pingServer) Ping(_ context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+	return connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber()}), nil
+}
+
+func (pingServer) Fail(_ context.Context, request *connect.Request[pingv1.FailRequest]) (*connect.Response[pingv1.FailResponse], error) {
+	return nil, connect.NewError(connect.Code(request.Msg.GetCode()), errors.New("oops"))
+}
+
+func (pingServer) CountUp(_ context.Context, request *connect.Request[pingv1.CountUpRequest], stream *connect.ServerStream[pingv1.CountUpResponse]) error {
+	for i := range request.Msg.GetNumber() {
+		if err := stream.Send(&pingv1.CountUpResponse{Number: i + 1}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordingHandler is a slog.Handler that records log entries.
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record.Clone())
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+// wait waits for at least n entries, since handlers may log after clients
+// receive their responses.
+func (h *recordingHandler) wait(t *testing.T, n int) []slog.Record {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.mu.Lock()
+		records := h.records
+		h.mu.Unlock()
+		if len(records) >= n {
+			return records
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d log entries", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// recordAttrs flattens a record's attributes, joining group keys with dots.
+func recordAttrs(record slog.Record) map[string]string {
+	attrs := make(map[string]string)
+	var add func(prefix string, attr slog.Attr)
+	add = func(prefix string, attr slog.Attr) {
+		if attr.Value.Kind() == slog.KindGroup {
+			for _, member := range attr.Value.Group() {
+				add(prefix+attr.Key+".", member)
+			}
+			return
+		}
+		attrs[prefix+attr.Key] = attr.Value.String()
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		add("", attr)
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,275 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Mux routes RPCs to handlers by procedure. Unlike [http.ServeMux], it
+// answers gRPC and gRPC-Web requests for unknown services and methods with a
+// trailers-only response carrying CodeUnimplemented, as gRPC servers do.
+// Other requests for unknown paths, including Connect requests, are passed to
+// a fallback handler.
+//
+// Handlers for individual procedures are registered with [Mux.Handle], and
+// the handlers returned by generated NewXServiceHandler functions with
+// [Mux.HandleService]. Muxes are safe to use concurrently, and handlers may be
+// registered while the Mux is serving.
+type Mux struct {
+	fallback http.Handler
+	protobuf Codec
+
+	mu         sync.RWMutex
+	procedures map[string]http.Handler
+	services   map[string]http.Handler // for services registered with HandleService
+}
+
+// NewMux constructs a Mux. Requests that aren't for a registered procedure,
+// and that don't use the gRPC or gRPC-Web protocols, are passed to the
+// fallback handler. If fallback is nil, they get a 404 Not Found response.
+func NewMux(fallback http.Handler) *Mux {
+	if fallback == nil {
+		fallback = http.NotFoundHandler()
+	}
+	return &Mux{
+		fallback:   fallback,
+		protobuf:   &protoBinaryCodec{},
+		procedures: make(map[string]http.Handler),
+		services:   make(map[string]http.Handler),
+	}
+}
+
+// Handle registers the handler for a procedure, such as
+// "/acme.foo.v1.FooService/Bar". It's usually a [*Handler], but it may be
+// wrapped in net/http middleware. Handle panics if the procedure is malformed
+// or already registered.
+func (m *Mux) Handle(procedure string, handler http.Handler) {
+	service, method := splitProcedure(procedure)
+	if service == "" || method == "" {
+		panic(fmt.Sprintf("scalpel: invalid procedure %q", procedure)) //nolint:forbidigo
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.procedures[procedure]; ok {
+		panic(fmt.Sprintf("scalpel: multiple registrations for %s", procedure)) //nolint:forbidigo
+	}
+	if _, ok := m.services[service]; ok {
+		panic(fmt.Sprintf("scalpel: %s is already registered with HandleService", service)) //nolint:forbidigo
+	}
+	m.procedures[procedure] = handler
+}
+
+// HandleService registers a handler for all the procedures of a service. Its
+// arguments match the return values of generated NewXServiceHandler
+// functions, so they can be passed directly:
+//
+//	mux.HandleService(pingv1connect.NewPingServiceHandler(&pingServer{}))
+//
+// The path must be of the form "/acme.foo.v1.FooService/". Since the Mux
+// can't see which methods the handler supports, they're not included in
+// [Mux.Procedures]. If the handler responds to a gRPC request with 404 Not
+// Found, the Mux replaces the response with CodeUnimplemented. HandleService
+// panics if the path is malformed or the service is already registered.
+func (m *Mux) HandleService(path string, handler http.Handler) {
+	service, method := splitProcedure(path)
+	if service == "" || method != "" || !strings.HasSuffix(path, "/") {
+		panic(fmt.Sprintf("scalpel: invalid service path %q", path)) //nolint:forbidigo
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.services[service]; ok {
+		panic(fmt.Sprintf("scalpel: multiple registrations for %s", path)) //nolint:forbidigo
+	}
+	for procedure := range m.procedures {
+		if strings.HasPrefix(procedure, path) {
+			panic(fmt.Sprintf("scalpel: %s is already registered with Handle", procedure)) //nolint:forbidigo
+		}
+	}
+	m.services[service] = handler
+}
+
+// Services returns the sorted, fully-qualified names of the registered
+// services, such as "acme.foo.v1.FooService". It's suitable for use with
+// server reflection and health checks.
+func (m *Mux) Services() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	seen := make(map[string]struct{}, len(m.services)+len(m.procedures))
+	for service := range m.services {
+		seen[service] = struct{}{}
+	}
+	for procedure := range m.procedures {
+		service, _ := splitProcedure(procedure)
+		seen[service] = struct{}{}
+	}
+	services := make([]string, 0, len(seen))
+	for service := range seen {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// Procedures returns the sorted procedures registered with [Mux.Handle].
+func (m *Mux) Procedures() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	procedures := make([]string, 0, len(m.procedures))
+	for procedure := range m.procedures {
+		procedures = append(procedures, procedure)
+	}
+	sort.Strings(procedures)
+	return procedures
+}
+
+// ServeHTTP implements [http.Handler].
+func (m *Mux) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	procedure := request.URL.Path
+	service, method := splitProcedure(procedure)
+	m.mu.RLock()
+	handler, isProcedure := m.procedures[procedure]
+	serviceHandler, isService := m.services[service]
+	var hasMethods bool // whether other methods of the service are registered
+	if !isProcedure && !isService && service != "" {
+		for registered := range m.procedures {
+			if strings.HasPrefix(registered, "/"+service+"/") {
+				hasMethods = true
+				break
+			}
+		}
+	}
+	m.mu.RUnlock()
+	isGRPC := isGRPCRequest(request)
+	switch {
+	case isProcedure:
+		handler.ServeHTTP(responseWriter, request)
+	case isService && isGRPC:
+		serviceHandler.ServeHTTP(&unimplementedResponseWriter{
+			ResponseWriter: responseWriter,
+			mux:            m,
+			request:        request,
+			service:        service,
+			method:         method,
+		}, request)
+	case isService:
+		serviceHandler.ServeHTTP(responseWriter, request)
+	case isGRPC && hasMethods:
+		m.writeUnimplemented(responseWriter, request, errorf(CodeUnimplemented, "unknown method %s for service %s", method, service))
+	case isGRPC:
+		m.writeUnimplemented(responseWriter, request, errorf(CodeUnimplemented, "unknown service %s", service))
+	default:
+		m.fallback.ServeHTTP(responseWriter, request)
+	}
+}
+
+// writeUnimplemented writes a trailers-only gRPC or gRPC-Web response, with
+// the status in the HTTP headers and no body.
+func (m *Mux) writeUnimplemented(responseWriter http.ResponseWriter, request *http.Request, err *Error) {
+	header := responseWriter.Header()
+	setHeaderCanonical(header, headerContentType, getHeaderCanonical(request.Header, headerContentType))
+	grpcErrorToTrailer(header, m.protobuf, err)
+	responseWriter.WriteHeader(http.StatusOK)
+}
+
+// isGRPCRequest reports whether the request uses the gRPC or gRPC-Web
+// protocol.
+func isGRPCRequest(request *http.Request) bool {
+	if request.Method != http.MethodPost {
+		return false
+	}
+	ctype := canonicalizeContentType(getHeaderCanonical(request.Header, headerContentType))
+	return ctype == grpcContentTypeDefault ||
+		strings.HasPrefix(ctype, grpcContentTypePrefix) ||
+		ctype == grpcWebContentTypeDefault ||
+		strings.HasPrefix(ctype, grpcWebContentTypePrefix) ||
+		grpcWebIsTextContentType(ctype)
+}
+
+// splitProcedure splits a procedure like "/acme.foo.v1.FooService/Bar" into
+// its service and method. Both are empty if the procedure is malformed.
+func splitProcedure(procedure string) (string, string) {
+	rest, ok := strings.CutPrefix(procedure, "/")
+	if !ok {
+		return "", ""
+	}
+	service, method, ok := strings.Cut(rest, "/")
+	if !ok || service == "" || strings.Contains(method, "/") {
+		return "", ""
+	}
+	return service, method
+}
+
+// unimplementedResponseWriter replaces 404 Not Found responses to gRPC
+// requests with CodeUnimplemented.
+type unimplementedResponseWriter struct {
+	http.ResponseWriter
+
+	mux         *Mux
+	request     *http.Request
+	service     string
+	method      string
+	wroteHeader bool
+	replaced    bool
+}
+
+func (w *unimplementedResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code != http.StatusNotFound {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.replaced = true
+	header := w.Header()
+	for key := range header {
+		delete(header, key)
+	}
+	w.mux.writeUnimplemented(
+		w.ResponseWriter,
+		w.request,
+		errorf(CodeUnimplemented, "unknown method %s for service %s", w.method, w.service),
+	)
+}
+
+func (w *unimplementedResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		// Discard the body of the 404.
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *unimplementedResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *unimplementedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
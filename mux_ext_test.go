@@ -0,0 +1,138 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel_test
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+)
+
+func TestMux(t *testing.T) {
+	t.Parallel()
+	fallback := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	serviceMux := connect.NewMux(fallback)
+	serviceMux.HandleService(pingv1connect.NewPingServiceHandler(pingServer{}))
+	procedureMux := connect.NewMux(fallback)
+	procedureMux.Handle(
+		pingv1connect.PingServicePingProcedure,
+		connect.NewUnaryHandler(pingv1connect.PingServicePingProcedure, pingServer{}.Ping),
+	)
+	assert.Equal(t, serviceMux.Services(), []string{pingv1connect.PingServiceName})
+	assert.Equal(t, serviceMux.Procedures(), []string{})
+	assert.Equal(t, procedureMux.Services(), []string{pingv1connect.PingServiceName})
+	assert.Equal(t, procedureMux.Procedures(), []string{pingv1connect.PingServicePingProcedure})
+
+	muxes := []struct {
+		name string
+		mux  *connect.Mux
+	}{
+		{"service", serviceMux},
+		{"procedure", procedureMux},
+	}
+	for _, mux := range muxes {
+		t.Run(mux.name, func(t *testing.T) {
+			t.Parallel()
+			server := memhttptest.NewServer(t, mux.mux)
+			t.Run("registered", func(t *testing.T) {
+				t.Parallel()
+				for _, opt := range []connect.ClientOption{connect.WithConnect(), connect.WithGRPC(), connect.WithGRPCWeb()} {
+					client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), opt)
+					response, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: 42}))
+					assert.Nil(t, err)
+					assert.Equal(t, response.Msg.GetNumber(), 42)
+				}
+			})
+			t.Run("unknown_method", func(t *testing.T) {
+				t.Parallel()
+				for _, contentType := range []string{"application/grpc", "application/grpc+proto", "application/grpc-web+proto"} {
+					response := postToMux(t, server.Client(), server.URL()+"/"+pingv1connect.PingServiceName+"/Unknown", contentType)
+					assert.Equal(t, response.StatusCode, http.StatusOK)
+					assert.Equal(t, response.Header.Get("Content-Type"), contentType)
+					assert.Equal(t, response.Header.Get("Grpc-Status"), strconv.Itoa(int(connect.CodeUnimplemented)))
+					assert.Equal(t, response.Header.Get("Grpc-Message"), "unknown method Unknown for service connect.ping.v1.PingService")
+					body, err := io.ReadAll(response.Body)
+					assert.Nil(t, err)
+					assert.Zero(t, len(body))
+				}
+			})
+			t.Run("unknown_service", func(t *testing.T) {
+				t.Parallel()
+				response := postToMux(t, server.Client(), server.URL()+"/acme.foo.v1.FooService/Bar", "application/grpc")
+				assert.Equal(t, response.StatusCode, http.StatusOK)
+				assert.Equal(t, response.Header.Get("Grpc-Status"), strconv.Itoa(int(connect.CodeUnimplemented)))
+				assert.Equal(t, response.Header.Get("Grpc-Message"), "unknown service acme.foo.v1.FooService")
+
+				client := connect.NewClient[pingv1.PingRequest, pingv1.PingResponse](
+					server.Client(),
+					server.URL()+"/acme.foo.v1.FooService/Bar",
+					connect.WithGRPC(),
+				)
+				_, err := client.CallUnary(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeUnimplemented)
+				assert.True(t, strings.Contains(err.Error(), "unknown service"))
+			})
+			t.Run("fallback", func(t *testing.T) {
+				t.Parallel()
+				response := postToMux(t, server.Client(), server.URL()+"/acme.foo.v1.FooService/Bar", "application/json")
+				assert.Equal(t, response.StatusCode, http.StatusTeapot)
+				response = postToMux(t, server.Client(), server.URL()+"/"+pingv1connect.PingServiceName+"/Unknown", "application/proto")
+				if mux.name == "service" {
+					// The service's own handler answers Connect requests.
+					assert.Equal(t, response.StatusCode, http.StatusNotFound)
+				} else {
+					assert.Equal(t, response.StatusCode, http.StatusTeapot)
+				}
+			})
+		})
+	}
+}
+
+func TestMuxRegistration(t *testing.T) {
+	t.Parallel()
+	handler := http.NotFoundHandler()
+	mux := connect.NewMux(nil)
+	mux.Handle("/acme.foo.v1.FooService/Bar", handler)
+	mux.HandleService("/acme.foo.v1.BazService/", handler)
+	assert.Panics(t, func() { mux.Handle("/acme.foo.v1.FooService/Bar", handler) })
+	assert.Panics(t, func() { mux.Handle("/acme.foo.v1.BazService/Qux", handler) })
+	assert.Panics(t, func() { mux.HandleService("/acme.foo.v1.FooService/", handler) })
+	assert.Panics(t, func() { mux.HandleService("/acme.foo.v1.BazService/", handler) })
+	assert.Panics(t, func() { mux.Handle("acme.foo.v1.FooService/Bar", handler) })
+	assert.Panics(t, func() { mux.Handle("/acme.foo.v1.FooService/", handler) })
+	assert.Panics(t, func() { mux.HandleService("/acme.foo.v1.QuxService", handler) })
+	assert.Equal(t, mux.Services(), []string{"acme.foo.v1.BazService", "acme.foo.v1.FooService"})
+}
+
+func postToMux(t *testing.T, client *http.Client, url, contentType string) *http.Response {
+	t.Helper()
+	request, err := http.NewRequestWithContext(t.Context(), http.MethodPost, url, strings.NewReader(""))
+	assert.Nil(t, err)
+	request.Header.Set("Content-Type", contentType)
+	response, err := client.Do(request)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = response.Body.Close() })
+	return response
+}
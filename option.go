@@ -14,6 +14,15 @@
 
 package scalpel
 
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
 // A ClientOption configures a [Client].
 //
 // In addition to any options grouped in the documentation below, remember that
@@ -22,14 +31,161 @@ type ClientOption interface {
 	applyToClient(*clientConfig)
 }
 
+// WithAcceptCompression makes a compression algorithm available to a client.
+// Clients ask servers to compress responses using any of the registered
+// algorithms. The first registered algorithm is treated as the least
+// preferred, and the last registered algorithm is the most preferred.
+//
+// It's safe to use this option liberally: servers will ignore any
+// compression algorithms they don't support. To compress requests, pair this
+// option with [WithSendCompression]. To remove support for a
+// previously-registered compression algorithm, use WithAcceptCompression with
+// nil decompressor and compressor constructors.
+//
+// Clients accept gzipped responses by default, using a compressor backed by the
+// standard library's [gzip] package with the default compression level. Use
+// [WithSendGzip] to compress requests with gzip.
+//
+// Calling WithAcceptCompression with an empty name is a no-op.
+func WithAcceptCompression(
+	name string,
+	newDecompressor func() Decompressor,
+	newCompressor func() Compressor,
+) ClientOption {
+	return &compressionOption{
+		Name:            name,
+		CompressionPool: newCompressionPool(newDecompressor, newCompressor),
+	}
+}
+
 // WithClientOptions composes multiple ClientOptions into one.
 func WithClientOptions(options ...ClientOption) ClientOption {
 	return &clientOptionsOption{options}
 }
 
+// WithConnect configures clients to use the Connect protocol. Unary Connect
+// calls are plain HTTP requests with unenveloped bodies and JSON errors, so
+// they're easy to inspect and replay with tools like cURL. Like gRPC-Web, the
+// Connect protocol works over HTTP/1.1.
+//
+// By default, clients use the gRPC protocol.
+func WithConnect() ClientOption {
+	return &connectOption{}
+}
+
 // WithGRPC configures clients to use the HTTP/2 gRPC protocol.
 func WithGRPC() ClientOption {
-	return &grpcOption{}
+	return &grpcOption{web: false}
+}
+
+// WithGRPCWeb configures clients to use the gRPC-Web protocol. Unlike gRPC,
+// gRPC-Web carries trailers in the response body, so it works over HTTP/1.1.
+func WithGRPCWeb() ClientOption {
+	return &grpcOption{web: true}
+}
+
+// WithHTTPGet allows Connect-protocol clients to use HTTP GET requests for
+// side-effect free unary RPC calls. Only procedures with the
+// [IdempotencyNoSideEffects] level are sent as GETs. The level is set with
+// [WithIdempotency], or taken from a Protobuf schema:
+//
+//	rpc Ping(PingRequest) returns (PingResponse) {
+//	  option idempotency_level = NO_SIDE_EFFECTS;
+//	}
+//
+// The gRPC and gRPC-Web protocols are POST-only, so this option has no effect
+// when combined with [WithGRPC] or [WithGRPCWeb].
+//
+// Using HTTP GET requests makes it easier to take advantage of CDNs, caching
+// reverse proxies, and browsers' built-in caching. Note, however, that servers
+// don't automatically set any cache headers; you can set cache headers using
+// interceptors or by adding headers in individual procedure implementations.
+//
+// By default, all requests are made as HTTP POSTs.
+func WithHTTPGet() ClientOption {
+	return &enableGetOption{}
+}
+
+// WithHTTPGetMaxURLSize sets the maximum allowable URL length for GET requests
+// made using the Connect protocol. It has no effect on gRPC or gRPC-Web
+// clients, since those protocols are POST-only.
+//
+// Limiting the URL size is useful as most user agents, proxies, and servers
+// have limits on the allowable length of a URL. For example, Apache and Nginx
+// limit the size of a request line to around 8 KiB, meaning that maximum
+// length of a URL is a bit smaller than this. If you run into URL size
+// limitations imposed by your network infrastructure and don't know the
+// maximum allowable size, or if you'd prefer to be cautious from the start, a
+// 4096 byte (4 KiB) limit works with most common proxies and CDNs.
+//
+// If fallback is set to true and the URL would be longer than the configured
+// maximum value, the request will be sent as an HTTP POST instead. If fallback
+// is set to false, the request will fail with [CodeResourceExhausted].
+//
+// By default, Connect-protocol clients with GET requests enabled may send a
+// URL of any size.
+func WithHTTPGetMaxURLSize(bytes int, fallback bool) ClientOption {
+	return &getURLMaxBytesOption{Max: bytes, Fallback: fallback}
+}
+
+// WithHedgingPolicy configures the client to hedge calls to idempotent unary
+// methods using the supplied [HedgingPolicy]. The message is marshaled once
+// and shared by every attempt. As with retries, interceptors see a single
+// call: use [CallInfo].Attempts to see how many copies of the request were
+// sent. Hedged requests include the Grpc-Previous-Rpc-Attempts header.
+//
+// For idempotent methods, hedging replaces any [RetryPolicy]. By default,
+// clients don't hedge.
+func WithHedgingPolicy(policy HedgingPolicy) ClientOption {
+	policy.NonFatalCodes = slices.Clone(policy.NonFatalCodes)
+	return &hedgingPolicyOption{policy: &policy}
+}
+
+// WithLoadBalancing configures the client to spread requests across the
+// addresses returned by the [Resolver], using the [Balancer] to choose an
+// address for each request. If the balancer is nil, the client uses
+// [NewRoundRobinBalancer]. The path of the client's URL is kept, but its
+// scheme and host are replaced by the chosen address.
+//
+// Addresses whose requests fail with [CodeUnavailable], either because the
+// server couldn't be reached or because it reported that code, are skipped
+// for ten seconds. If every address has failed, the client uses them all.
+//
+// Clients configured with the same option share their view of each
+// address's load, so construct the option once for each pool of servers.
+func WithLoadBalancing(resolver Resolver, balancer Balancer) ClientOption {
+	return &loadBalancingOption{balancer: newLoadBalancer(resolver, balancer)}
+}
+
+// WithRetryPolicy configures the client to automatically retry failed calls
+// using the supplied [RetryPolicy]. Retries are invisible to interceptors,
+// which see a single call; use [CallInfo].Attempts to see how many attempts
+// were made. Retried requests include the Grpc-Previous-Rpc-Attempts header.
+//
+// By default, clients don't retry.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	policy.RetryableCodes = slices.Clone(policy.RetryableCodes)
+	return &retryPolicyOption{policy: &policy}
+}
+
+// WithSendCompression configures the client to use the specified algorithm to
+// compress request messages. If the algorithm has not been registered using
+// [WithAcceptCompression], the client will return errors at runtime.
+//
+// Because some servers don't support compression, clients default to sending
+// uncompressed requests.
+func WithSendCompression(name string) ClientOption {
+	return &sendCompressionOption{Name: name}
+}
+
+// WithSendGzip configures the client to gzip requests. Since clients have
+// access to a gzip compressor by default, WithSendGzip doesn't require
+// [WithSendCompression].
+//
+// Some servers don't support gzip, so clients default to sending uncompressed
+// requests.
+func WithSendGzip() ClientOption {
+	return WithSendCompression(compressionGzip)
 }
 
 // A HandlerOption configures a [Handler].
@@ -40,6 +196,109 @@ type HandlerOption interface {
 	applyToHandler(*handlerConfig)
 }
 
+// WithCompression configures handlers to support a compression algorithm.
+// Clients may send messages compressed with that algorithm and/or request
+// compressed responses. The [Compressor] and [Decompressor] produced by the
+// supplied constructors must use the same algorithm. Internally, Scalpel pools
+// compressors and decompressors.
+//
+// By default, handlers support gzip using the standard library's
+// [compress/gzip] package at the default compression level. To remove support for
+// a previously-registered compression algorithm, use WithCompression with nil
+// decompressor and compressor constructors.
+//
+// Calling WithCompression with an empty name is a no-op.
+func WithCompression(
+	name string,
+	newDecompressor func() Decompressor,
+	newCompressor func() Compressor,
+) HandlerOption {
+	return &compressionOption{
+		Name:            name,
+		CompressionPool: newCompressionPool(newDecompressor, newCompressor),
+	}
+}
+
+// WithRecover configures the Handler to recover from panics in its
+// implementation. The supplied function receives the context, [Spec], request
+// headers, the recovered value, and the stack trace of the panic; it may log
+// the panic, emit metrics, or execute other error-handling logic. The RPC then
+// fails with CodeInternal, so clients always receive a well-formed error
+// rather than a reset stream. The function must be safe to call concurrently,
+// and may be nil.
+//
+// To preserve compatibility with [net/http]'s semantics, handlers don't
+// recover from panics with [http.ErrAbortHandler]. Panics in goroutines
+// started by the implementation aren't recovered.
+//
+// By default, handlers don't recover from panics, and they propagate to the
+// [http.Server].
+func WithRecover(handle func(ctx context.Context, spec Spec, header http.Header, recovered any, stack []byte)) HandlerOption {
+	return &recoverOption{Handle: handle}
+}
+
+// WithRequireConnectProtocolHeader configures the Handler to require requests
+// using the Connect RPC protocol to include the Connect-Protocol-Version
+// header. This ensures that HTTP proxies and net/http middleware can easily
+// identify valid Connect requests, even if they use a common Content-Type like
+// application/json. However, it makes ad-hoc requests with tools like cURL
+// more laborious. Streaming requests are not affected by this option.
+//
+// This option has no effect if the client uses the gRPC or gRPC-Web protocols.
+func WithRequireConnectProtocolHeader() HandlerOption {
+	return &requireConnectProtocolHeaderOption{}
+}
+
+// WithMaxTimeout limits how long the handler runs, even if the client asks
+// for a longer timeout or sends none at all. Client deadlines later than the
+// maximum are clamped, and the handler's context is canceled once the maximum
+// elapses. Use [CallInfo.DeadlineClamped] to check whether a call's deadline
+// was shortened.
+//
+// Setting WithMaxTimeout to zero, the default, trusts the client's timeout.
+// Use [WithConditionalHandlerOptions] to set different limits for each
+// procedure.
+func WithMaxTimeout(timeout time.Duration) HandlerOption {
+	return &maxTimeoutOption{Max: timeout}
+}
+
+// WithConcurrencyLimit sheds load by limiting the number of RPCs in flight.
+// Handlers configured with the same [ConcurrencyLimiter] share its limit, so
+// passing a limiter to a generated service handler constructor limits the
+// service as a whole. To limit each procedure separately, construct a limiter
+// for each one, for example:
+//
+//	scalpel.WithConditionalHandlerOptions(func(scalpel.Spec) []scalpel.HandlerOption {
+//		limiter := scalpel.NewConcurrencyLimiter(scalpel.ConcurrencyPolicy{Limit: 100})
+//		return []scalpel.HandlerOption{scalpel.WithConcurrencyLimit(limiter)}
+//	})
+//
+// Repeated WithConcurrencyLimit options are applied in order, and an RPC must
+// be admitted by every limiter before it runs. RPCs that aren't admitted fail
+// with the limiter's error code and never reach interceptors.
+func WithConcurrencyLimit(limiter *ConcurrencyLimiter) HandlerOption {
+	return &concurrencyLimitOption{Limiter: limiter}
+}
+
+// WithDefaultTimeout sets the timeout for requests that don't include one.
+// The handler's context is canceled once it elapses, as if the client had sent
+// the timeout itself, so it's still subject to [WithMaxTimeout].
+//
+// Setting WithDefaultTimeout to zero, the default, leaves requests without a
+// timeout unbounded. Use [WithConditionalHandlerOptions] to set different
+// defaults for each procedure.
+func WithDefaultTimeout(timeout time.Duration) HandlerOption {
+	return &defaultTimeoutOption{Default: timeout}
+}
+
+// WithTranscoder adds unary handlers with google.api.http annotations to the
+// [Transcoder], so they can also be called as plain HTTP/JSON endpoints. The
+// handlers must have a Protobuf schema, which generated code sets with
+// [WithSchema]. Handlers for streaming procedures aren't added.
+func WithTranscoder(transcoder *Transcoder) HandlerOption {
+	return &transcoderOption{Transcoder: transcoder}
+}
+
 // WithHandlerOptions composes multiple HandlerOptions into one.
 func WithHandlerOptions(options ...HandlerOption) HandlerOption {
 	return &handlerOptionsOption{options}
@@ -73,6 +332,15 @@ func WithSchema(schema any) Option {
 	return &schemaOption{Schema: schema}
 }
 
+// WithIdempotency declares the idempotency of the procedure, which is exposed
+// as [Spec.IdempotencyLevel]. This can determine whether a procedure call can
+// safely be hedged or sent as an HTTP GET request. It's typically added by
+// generated code, but procedures with a Protobuf schema don't need it: their
+// level is taken from the schema's idempotency_level option.
+func WithIdempotency(idempotencyLevel IdempotencyLevel) Option {
+	return &idempotencyOption{idempotencyLevel: idempotencyLevel}
+}
+
 // WithRequestInitializer provides a function that initializes a new message.
 // It may be used to dynamically construct request messages. It is called on
 // server receives to construct the message to be unmarshaled into. The message
@@ -103,6 +371,17 @@ func WithCodec(codec Codec) Option {
 	return &codecOption{Codec: codec}
 }
 
+// WithCompressMinBytes sets a minimum size threshold for compression:
+// regardless of compressor configuration, messages smaller than the configured
+// minimum are sent uncompressed.
+//
+// The default minimum is zero. Setting a minimum compression threshold may
+// improve overall performance, because the CPU cost of compressing very small
+// messages usually isn't worth the small reduction in network I/O.
+func WithCompressMinBytes(minBytes int) Option {
+	return &compressMinBytesOption{Min: minBytes}
+}
+
 // WithReadMaxBytes limits the performance impact of pathologically large
 // messages sent by the other party. For handlers, WithReadMaxBytes limits the size
 // of a message that the client can send. For clients, WithReadMaxBytes limits the
@@ -132,6 +411,102 @@ func WithSendMaxBytes(maxBytes int) Option {
 	return &sendMaxBytesOption{Max: maxBytes}
 }
 
+// WithInterceptors configures a client or handler's interceptor stack. Repeated
+// WithInterceptors options are applied in order, so
+//
+//	WithInterceptors(A) + WithInterceptors(B, C) == WithInterceptors(A, B, C)
+//
+// Unary interceptors compose like an onion. The first interceptor provided is
+// the outermost layer of the onion: it acts first on the context and request,
+// and last on the response and error.
+//
+// Stream interceptors also behave like an onion: the first interceptor
+// provided is the outermost wrapper for the [StreamingClientConn] or
+// [StreamingHandlerConn]. It's the first to see sent messages and the last to
+// see received messages.
+//
+// Applied to client and handler, WithInterceptors(A, B, ..., Y, Z) produces:
+//
+//	 client.Send()       client.Receive()
+//	       |                   ^
+//	       v                   |
+//	    A ---                 --- A
+//	    B ---                 --- B
+//	    : ...                 ... :
+//	    Y ---                 --- Y
+//	    Z ---                 --- Z
+//	       |                   ^
+//	       v                   |
+//	  = = = = = = = = = = = = = = = =
+//	               network
+//	  = = = = = = = = = = = = = = = =
+//	       |                   ^
+//	       v                   |
+//	    A ---                 --- A
+//	    B ---                 --- B
+//	    : ...                 ... :
+//	    Y ---                 --- Y
+//	    Z ---                 --- Z
+//	       |                   ^
+//	       v                   |
+//	handler.Receive()   handler.Send()
+//	       |                   ^
+//	       |                   |
+//	       '-> handler logic >-'
+//
+// Note that in clients, Send handles the request message(s) and Receive
+// handles the response message(s). For handlers, it's the reverse. Depending
+// on your interceptor's logic, you may need to wrap one method in clients and
+// the other in handlers.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return &interceptorsOption{interceptors}
+}
+
+// WithProtoJSON configures a client to send JSON-encoded data instead of
+// binary Protobuf, using the "application/json" content type for unary Connect
+// calls and "application/grpc+json" or a similar variant otherwise. It uses the
+// standard Protobuf JSON mapping as implemented by
+// [google.golang.org/protobuf/encoding/protojson]: fields are named using
+// lowerCamelCase, zero values are omitted, enums are emitted as strings, etc.
+//
+// Handlers accept JSON by default. Applied to a handler, WithProtoJSON
+// replaces the default JSON codec with one using the supplied
+// [ProtoJSONOption]s.
+func WithProtoJSON(options ...ProtoJSONOption) Option {
+	codec := &protoJSONCodec{name: codecNameJSON}
+	for _, option := range options {
+		option.applyToProtoJSON(codec)
+	}
+	return &protoJSONOption{codec: codec}
+}
+
+// A ProtoJSONOption configures the JSON codec installed by [WithProtoJSON].
+type ProtoJSONOption interface {
+	applyToProtoJSON(*protoJSONCodec)
+}
+
+// WithProtoJSONEmitUnpopulated configures the JSON codec to emit fields that
+// have their zero value, which is often easier to read when debugging.
+func WithProtoJSONEmitUnpopulated() ProtoJSONOption {
+	return &protoJSONEmitUnpopulatedOption{}
+}
+
+// WithProtoJSONRejectUnknown configures the JSON codec to return an error when
+// it unmarshals a field that isn't in the schema. By default, unknown fields
+// are discarded so that clients and servers don't need to use exactly the
+// same version of the schema.
+func WithProtoJSONRejectUnknown() ProtoJSONOption {
+	return &protoJSONRejectUnknownOption{}
+}
+
+// WithStatsHandler adds a [StatsHandler] to a client or handler, so that it
+// observes the lifecycle of every RPC. Repeated WithStatsHandler options are
+// applied in order: each handler's TagRPC receives the context returned by
+// the previous one, and events are sent to the handlers in the same order.
+func WithStatsHandler(handler StatsHandler) Option {
+	return &statsHandlerOption{Handler: handler}
+}
+
 // WithOptions composes multiple Options into one.
 func WithOptions(options ...Option) Option {
 	return &optionsOption{options}
@@ -149,6 +524,18 @@ func (o *schemaOption) applyToHandler(config *handlerConfig) {
 	config.Schema = o.Schema
 }
 
+type idempotencyOption struct {
+	idempotencyLevel IdempotencyLevel
+}
+
+func (o *idempotencyOption) applyToClient(config *clientConfig) {
+	config.IdempotencyLevel = o.idempotencyLevel
+}
+
+func (o *idempotencyOption) applyToHandler(config *handlerConfig) {
+	config.IdempotencyLevel = o.idempotencyLevel
+}
+
 type initializerOption struct {
 	Initializer func(spec Spec, message any) error
 }
@@ -200,6 +587,51 @@ func (o *codecOption) applyToHandler(config *handlerConfig) {
 	config.Codecs[o.Codec.Name()] = o.Codec
 }
 
+type compressionOption struct {
+	Name            string
+	CompressionPool *compressionPool
+}
+
+func (o *compressionOption) applyToClient(config *clientConfig) {
+	o.apply(&config.CompressionNames, config.CompressionPools)
+}
+
+func (o *compressionOption) applyToHandler(config *handlerConfig) {
+	o.apply(&config.CompressionNames, config.CompressionPools)
+}
+
+func (o *compressionOption) apply(configuredNames *[]string, configuredPools map[string]*compressionPool) {
+	if o.Name == "" {
+		return
+	}
+	if o.CompressionPool == nil {
+		delete(configuredPools, o.Name)
+		var names []string
+		for _, name := range *configuredNames {
+			if name == o.Name {
+				continue
+			}
+			names = append(names, name)
+		}
+		*configuredNames = names
+		return
+	}
+	configuredPools[o.Name] = o.CompressionPool
+	*configuredNames = append(*configuredNames, o.Name)
+}
+
+type compressMinBytesOption struct {
+	Min int
+}
+
+func (o *compressMinBytesOption) applyToClient(config *clientConfig) {
+	config.CompressMinBytes = o.Min
+}
+
+func (o *compressMinBytesOption) applyToHandler(config *handlerConfig) {
+	config.CompressMinBytes = o.Min
+}
+
 type readMaxBytesOption struct {
 	Max int
 }
@@ -234,11 +666,151 @@ func (o *handlerOptionsOption) applyToHandler(config *handlerConfig) {
 	}
 }
 
+type connectOption struct{}
+
+func (o *connectOption) applyToClient(config *clientConfig) {
+	config.Protocol = &protocolConnect{}
+}
+
+type enableGetOption struct{}
+
+func (o *enableGetOption) applyToClient(config *clientConfig) {
+	config.EnableGet = true
+}
+
+type getURLMaxBytesOption struct {
+	Max      int
+	Fallback bool
+}
+
+func (o *getURLMaxBytesOption) applyToClient(config *clientConfig) {
+	config.GetURLMaxBytes = o.Max
+	config.GetUseFallback = o.Fallback
+}
+
+type recoverOption struct {
+	Handle func(context.Context, Spec, http.Header, any, []byte)
+}
+
+func (o *recoverOption) applyToHandler(config *handlerConfig) {
+	handle := o.Handle
+	if handle == nil {
+		handle = func(context.Context, Spec, http.Header, any, []byte) {}
+	}
+	config.RecoverPanic = handle
+}
+
+type requireConnectProtocolHeaderOption struct{}
+
+func (o *requireConnectProtocolHeaderOption) applyToHandler(config *handlerConfig) {
+	config.RequireConnectProtocolHeader = true
+}
+
+type maxTimeoutOption struct {
+	Max time.Duration
+}
+
+func (o *maxTimeoutOption) applyToHandler(config *handlerConfig) {
+	config.MaxTimeout = o.Max
+}
+
+type concurrencyLimitOption struct {
+	Limiter *ConcurrencyLimiter
+}
+
+func (o *concurrencyLimitOption) applyToHandler(config *handlerConfig) {
+	if o.Limiter != nil {
+		config.ConcurrencyLimiters = append(config.ConcurrencyLimiters, o.Limiter)
+	}
+}
+
+type defaultTimeoutOption struct {
+	Default time.Duration
+}
+
+func (o *defaultTimeoutOption) applyToHandler(config *handlerConfig) {
+	config.DefaultTimeout = o.Default
+}
+
+type transcoderOption struct {
+	Transcoder *Transcoder
+}
+
+func (o *transcoderOption) applyToHandler(config *handlerConfig) {
+	config.Transcoder = o.Transcoder
+}
+
+type hedgingPolicyOption struct {
+	policy *HedgingPolicy
+}
+
+func (o *hedgingPolicyOption) applyToClient(config *clientConfig) {
+	config.HedgingPolicy = o.policy
+}
+
+type loadBalancingOption struct {
+	balancer *loadBalancer
+}
+
+func (o *loadBalancingOption) applyToClient(config *clientConfig) {
+	config.LoadBalancer = o.balancer
+}
+
+type retryPolicyOption struct {
+	policy *RetryPolicy
+}
+
+func (o *retryPolicyOption) applyToClient(config *clientConfig) {
+	config.RetryPolicy = o.policy
+}
+
 type grpcOption struct {
+	web bool
 }
 
 func (o *grpcOption) applyToClient(config *clientConfig) {
-	config.Protocol = &protocolGRPC{}
+	config.Protocol = &protocolGRPC{web: o.web}
+}
+
+type interceptorsOption struct {
+	Interceptors []Interceptor
+}
+
+func (o *interceptorsOption) applyToClient(config *clientConfig) {
+	config.Interceptor = o.chainWith(config.Interceptor)
+}
+
+func (o *interceptorsOption) applyToHandler(config *handlerConfig) {
+	config.Interceptor = o.chainWith(config.Interceptor)
+}
+
+func (o *interceptorsOption) chainWith(current Interceptor) Interceptor {
+	if len(o.Interceptors) == 0 {
+		return current
+	}
+	if current == nil && len(o.Interceptors) == 1 {
+		return o.Interceptors[0]
+	}
+	if current == nil && len(o.Interceptors) > 1 {
+		return newChain(o.Interceptors)
+	}
+	return newChain(append([]Interceptor{current}, o.Interceptors...))
+}
+
+type statsHandlerOption struct {
+	Handler StatsHandler
+}
+
+func (o *statsHandlerOption) applyToClient(config *clientConfig) {
+	if o.Handler != nil {
+		config.StatsHandlers = append(config.StatsHandlers, o.Handler)
+	}
+}
+
+func (o *statsHandlerOption) applyToHandler(config *handlerConfig) {
+	if o.Handler != nil {
+		config.StatsHandlers = append(config.StatsHandlers, o.Handler)
+	}
 }
 
 type optionsOption struct {
@@ -257,10 +829,59 @@ func (o *optionsOption) applyToHandler(config *handlerConfig) {
 	}
 }
 
+type sendCompressionOption struct {
+	Name string
+}
+
+func (o *sendCompressionOption) applyToClient(config *clientConfig) {
+	config.RequestCompressionName = o.Name
+}
+
+func withGzip() Option {
+	return &compressionOption{
+		Name: compressionGzip,
+		CompressionPool: newCompressionPool(
+			func() Decompressor { return &gzip.Reader{} },
+			func() Compressor { return gzip.NewWriter(io.Discard) },
+		),
+	}
+}
+
 func withProtoBinaryCodec() Option {
 	return WithCodec(&protoBinaryCodec{})
 }
 
+func withProtoJSONCodecs() HandlerOption {
+	return WithProtoJSON()
+}
+
+type protoJSONOption struct {
+	codec *protoJSONCodec
+}
+
+func (o *protoJSONOption) applyToClient(config *clientConfig) {
+	config.Codec = o.codec
+}
+
+func (o *protoJSONOption) applyToHandler(config *handlerConfig) {
+	withCharset := *o.codec
+	withCharset.name = codecNameJSONCharsetUTF8
+	config.Codecs[codecNameJSON] = o.codec
+	config.Codecs[codecNameJSONCharsetUTF8] = &withCharset
+}
+
+type protoJSONEmitUnpopulatedOption struct{}
+
+func (o *protoJSONEmitUnpopulatedOption) applyToProtoJSON(codec *protoJSONCodec) {
+	codec.emitUnpopulated = true
+}
+
+type protoJSONRejectUnknownOption struct{}
+
+func (o *protoJSONRejectUnknownOption) applyToProtoJSON(codec *protoJSONCodec) {
+	codec.rejectUnknown = true
+}
+
 type conditionalHandlerOptions struct {
 	conditional func(spec Spec) []HandlerOption
 }
@@ -16,6 +16,9 @@ package scalpel
 
 import (
 	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // extractProtoPath returns the trailing portion of the URL's path,
@@ -40,3 +43,27 @@ func extractProtoPath(path string) string {
 	}
 	return "/" + pkg + "/" + method
 }
+
+// idempotencyLevel returns the configured level or, if none was configured,
+// the level from the idempotency_level option of a Protobuf schema.
+func idempotencyLevel(configured IdempotencyLevel, schema any) IdempotencyLevel {
+	if configured != IdempotencyUnknown {
+		return configured
+	}
+	method, ok := schema.(protoreflect.MethodDescriptor)
+	if !ok {
+		return IdempotencyUnknown
+	}
+	options, ok := method.Options().(*descriptorpb.MethodOptions)
+	if !ok {
+		return IdempotencyUnknown
+	}
+	switch options.GetIdempotencyLevel() {
+	case descriptorpb.MethodOptions_NO_SIDE_EFFECTS:
+		return IdempotencyNoSideEffects
+	case descriptorpb.MethodOptions_IDEMPOTENT:
+		return IdempotencyIdempotent
+	default:
+		return IdempotencyUnknown
+	}
+}
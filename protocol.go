@@ -26,9 +26,12 @@ import (
 	"strings"
 )
 
-// The name of the supported protocol.
+// The names of the Connect, gRPC, and gRPC-Web protocols (as exposed by
+// [Peer].Protocol).
 const (
-	ProtocolGRPC = "grpc"
+	ProtocolConnect = "connect"
+	ProtocolGRPC    = "grpc"
+	ProtocolGRPCWeb = "grpcweb"
 )
 
 const (
@@ -76,6 +79,8 @@ type protocolHandlerParams struct {
 	BufferPool                   *bufferPool
 	ReadMaxBytes                 int
 	SendMaxBytes                 int
+	CompressionPools             readOnlyCompressionPools
+	CompressMinBytes             int
 	RequireConnectProtocolHeader bool
 }
 
@@ -111,15 +116,20 @@ type protocolHandler interface {
 // Protocol implementations should take care to use the supplied Spec rather
 // than constructing their own, since new fields may have been added.
 type protocolClientParams struct {
-	Codec          Codec
-	HTTPClient     HTTPClient
-	URL            *url.URL
-	BufferPool     *bufferPool
-	ReadMaxBytes   int
-	SendMaxBytes   int
-	EnableGet      bool
-	GetURLMaxBytes int
-	GetUseFallback bool
+	CompressionName  string
+	CompressionPools readOnlyCompressionPools
+	Codec            Codec
+	CompressMinBytes int
+	HTTPClient       HTTPClient
+	URL              *url.URL
+	BufferPool       *bufferPool
+	ReadMaxBytes     int
+	SendMaxBytes     int
+	EnableGet        bool
+	GetURLMaxBytes   int
+	GetUseFallback   bool
+	RetryPolicy      *RetryPolicy
+	HedgingPolicy    *HedgingPolicy
 	// The gRPC family of protocols always needs access to a Protobuf codec to
 	// marshal and unmarshal errors.
 	Protobuf Codec
@@ -273,6 +283,10 @@ func sortedAllowMethodValue(handlers []protocolHandler) string {
 	return strings.Join(allow, ", ")
 }
 
+func isCommaOrSpace(c rune) bool {
+	return c == ',' || c == ' '
+}
+
 func discard(reader io.Reader) (int64, error) {
 	if lr, ok := reader.(*io.LimitedReader); ok {
 		return io.Copy(io.Discard, lr)
@@ -340,6 +354,51 @@ func canonicalizeContentTypeSlow(contentType string) string {
 	return mime.FormatMediaType(base, params)
 }
 
+// negotiateCompression determines and validates the request compression and
+// response compression using the available compressors and protocol-specific
+// Content-Encoding and Accept-Encoding headers.
+func negotiateCompression( //nolint:nonamedreturns
+	availableCompressors readOnlyCompressionPools,
+	sent, accept string,
+) (requestCompression, responseCompression string, clientVisibleErr *Error) {
+	requestCompression = compressionIdentity
+	if sent != "" && sent != compressionIdentity {
+		// We default to identity, so we only care if the client sends something
+		// other than the empty string or compressIdentity.
+		if availableCompressors.Contains(sent) {
+			requestCompression = sent
+		} else {
+			// To comply with
+			// https://github.com/grpc/grpc/blob/master/doc/compression.md and the
+			// Connect protocol, we should return CodeUnimplemented and specify
+			// acceptable compression(s) (in addition to setting the a
+			// protocol-specific accept-encoding header).
+			return "", "", errorf(
+				CodeUnimplemented,
+				"unknown compression %q: supported encodings are %v",
+				sent, availableCompressors.CommaSeparatedNames(),
+			)
+		}
+	}
+	// Support asymmetric compression. This logic follows
+	// https://github.com/grpc/grpc/blob/master/doc/compression.md and common
+	// sense.
+	responseCompression = requestCompression
+	// If we're not already planning to compress the response, check whether the
+	// client requested a compression algorithm we support.
+	if responseCompression == compressionIdentity && accept != "" {
+		for _, name := range strings.FieldsFunc(accept, isCommaOrSpace) {
+			if availableCompressors.Contains(name) {
+				// We found a mutually supported compression algorithm. Unlike standard
+				// HTTP, there's no preference weighting, so can bail out immediately.
+				responseCompression = name
+				break
+			}
+		}
+	}
+	return requestCompression, responseCompression, nil
+}
+
 func httpToCode(httpCode int) Code {
 	// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
 	// Note that this is NOT the inverse of the gRPC-to-HTTP or Connect-to-HTTP
@@ -15,12 +15,16 @@
 package scalpel
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
+	"net/textproto"
 	"runtime"
 	"strconv"
 	"strings"
@@ -39,8 +43,14 @@ const (
 
 	grpcFlagEnvelopeTrailer = 0b10000000
 
-	grpcContentTypeDefault = "application/grpc"
-	grpcContentTypePrefix  = grpcContentTypeDefault + "+"
+	grpcContentTypeDefault        = "application/grpc"
+	grpcWebContentTypeDefault     = "application/grpc-web"
+	grpcWebTextContentTypeDefault = "application/grpc-web-text"
+	grpcContentTypePrefix         = grpcContentTypeDefault + "+"
+	grpcWebContentTypePrefix      = grpcWebContentTypeDefault + "+"
+	grpcWebTextContentTypePrefix  = grpcWebTextContentTypeDefault + "+"
+
+	headerXUserAgent = "X-User-Agent"
 
 	upperhex = "0123456789ABCDEF"
 )
@@ -67,11 +77,15 @@ var (
 )
 
 type protocolGRPC struct {
+	web bool
 }
 
 // NewHandler implements protocol, so it must return an interface.
 func (g *protocolGRPC) NewHandler(params *protocolHandlerParams) protocolHandler {
 	bare, prefix := grpcContentTypeDefault, grpcContentTypePrefix
+	if g.web {
+		bare, prefix = grpcWebContentTypeDefault, grpcWebContentTypePrefix
+	}
 	contentTypes := make(map[string]struct{})
 	for _, name := range params.Codecs.Names() {
 		contentTypes[canonicalizeContentType(prefix+name)] = struct{}{}
@@ -79,8 +93,19 @@ func (g *protocolGRPC) NewHandler(params *protocolHandlerParams) protocolHandler
 	if params.Codecs.Get(codecNameProto) != nil {
 		contentTypes[bare] = struct{}{}
 	}
+	if g.web {
+		// gRPC-Web handlers also accept the base64-encoded text variant used by
+		// browsers that can't read binary streaming responses.
+		for _, name := range params.Codecs.Names() {
+			contentTypes[canonicalizeContentType(grpcWebTextContentTypePrefix+name)] = struct{}{}
+		}
+		if params.Codecs.Get(codecNameProto) != nil {
+			contentTypes[grpcWebTextContentTypeDefault] = struct{}{}
+		}
+	}
 	return &grpcHandler{
 		protocolHandlerParams: *params,
+		web:                   g.web,
 		accept:                contentTypes,
 	}
 }
@@ -88,8 +113,12 @@ func (g *protocolGRPC) NewHandler(params *protocolHandlerParams) protocolHandler
 // NewClient implements protocol, so it must return an interface.
 func (g *protocolGRPC) NewClient(params *protocolClientParams) (protocolClient, error) {
 	peer := newPeerForURL(params.URL, ProtocolGRPC)
+	if g.web {
+		peer = newPeerForURL(params.URL, ProtocolGRPCWeb)
+	}
 	return &grpcClient{
 		protocolClientParams: *params,
+		web:                  g.web,
 		peer:                 peer,
 	}, nil
 }
@@ -97,6 +126,7 @@ func (g *protocolGRPC) NewClient(params *protocolClientParams) (protocolClient,
 type grpcHandler struct {
 	protocolHandlerParams
 
+	web    bool
 	accept map[string]struct{}
 }
 
@@ -132,9 +162,14 @@ func (g *grpcHandler) NewConn(
 	request *http.Request,
 ) (handlerConnCloser, bool) {
 	ctx := request.Context()
+	stats := statsFromContext(ctx, false /* client */)
 	// We need to parse metadata before entering the interceptor stack; we'll
 	// send the error to the client later on.
-	var failed *Error
+	requestCompression, responseCompression, failed := negotiateCompression(
+		g.CompressionPools,
+		getHeaderCanonical(request.Header, grpcHeaderCompression),
+		getHeaderCanonical(request.Header, grpcHeaderAcceptCompression),
+	)
 	if failed == nil {
 		failed = checkServerStreamsCanFlush(g.Spec, responseWriter)
 	}
@@ -148,25 +183,45 @@ func (g *grpcHandler) NewConn(
 	// skip the normalization in Header.Set.
 	header := responseWriter.Header()
 	header[headerContentType] = []string{getHeaderCanonical(request.Header, headerContentType)}
+	header[grpcHeaderAcceptCompression] = []string{g.CompressionPools.CommaSeparatedNames()}
+	if responseCompression != compressionIdentity {
+		header[grpcHeaderCompression] = []string{responseCompression}
+	}
 
-	codecName := grpcCodecForContentType(getHeaderCanonical(request.Header, headerContentType))
+	contentType := getHeaderCanonical(request.Header, headerContentType)
+	codecName := grpcCodecForContentType(g.web, contentType)
 	codec := g.Codecs.Get(codecName) // handler.go guarantees this is not nil
 	protocolName := ProtocolGRPC
+	if g.web {
+		protocolName = ProtocolGRPCWeb
+	}
+	var (
+		requestBody  io.Reader = request.Body
+		responseBody io.Writer = responseWriter
+	)
+	if g.web && grpcWebIsTextContentType(contentType) {
+		requestBody = &grpcWebTextReader{reader: request.Body}
+		responseBody = &grpcWebTextWriter{writer: responseWriter}
+	}
 	conn := wrapHandlerConnWithCodedErrors(&grpcHandlerConn{
 		spec: g.Spec,
 		peer: Peer{
 			Addr:     request.RemoteAddr,
 			Protocol: protocolName,
 		},
+		web:        g.web,
 		bufferPool: g.BufferPool,
 		protobuf:   g.Codecs.Protobuf(), // for errors
 		marshaler: grpcMarshaler{
 			envelopeWriter: envelopeWriter{
-				ctx:          ctx,
-				sender:       writeSender{writer: responseWriter},
-				codec:        codec,
-				bufferPool:   g.BufferPool,
-				sendMaxBytes: g.SendMaxBytes,
+				ctx:              ctx,
+				sender:           writeSender{writer: responseBody},
+				compressionPool:  g.CompressionPools.Get(responseCompression),
+				codec:            codec,
+				compressMinBytes: g.CompressMinBytes,
+				bufferPool:       g.BufferPool,
+				sendMaxBytes:     g.SendMaxBytes,
+				stats:            stats,
 			},
 		},
 		responseWriter:  responseWriter,
@@ -175,12 +230,15 @@ func (g *grpcHandler) NewConn(
 		request:         request,
 		unmarshaler: grpcUnmarshaler{
 			envelopeReader: envelopeReader{
-				ctx:          ctx,
-				reader:       request.Body,
-				codec:        codec,
-				bufferPool:   g.BufferPool,
-				readMaxBytes: g.ReadMaxBytes,
+				ctx:             ctx,
+				reader:          requestBody,
+				codec:           codec,
+				compressionPool: g.CompressionPools.Get(requestCompression),
+				bufferPool:      g.BufferPool,
+				readMaxBytes:    g.ReadMaxBytes,
+				stats:           stats,
 			},
+			web: g.web,
 		},
 	})
 	if failed != nil {
@@ -194,6 +252,7 @@ func (g *grpcHandler) NewConn(
 type grpcClient struct {
 	protocolClientParams
 
+	web  bool
 	peer Peer
 }
 
@@ -207,14 +266,29 @@ func (g *grpcClient) WriteRequestHeader(_ StreamType, header http.Header) {
 	if getHeaderCanonical(header, headerUserAgent) == "" {
 		header[headerUserAgent] = []string{defaultGrpcUserAgent}
 	}
-	header[headerContentType] = []string{grpcContentTypeForCodecName(g.Codec.Name())}
+	if g.web && getHeaderCanonical(header, headerXUserAgent) == "" {
+		// The gRPC-Web pseudo-specification seems to require X-User-Agent rather
+		// than User-Agent for all clients, even if they're not browser-based. This
+		// is very odd for a backend client, so we'll split the difference and set
+		// both.
+		header[headerXUserAgent] = []string{defaultGrpcUserAgent}
+	}
+	header[headerContentType] = []string{grpcContentTypeForCodecName(g.web, g.Codec.Name())}
 	// gRPC handles compression on a per-message basis, so we don't want to
 	// compress the whole stream. By default, http.Client will ask the server
 	// to gzip the stream if we don't set Accept-Encoding.
-	header["Accept-Encoding"] = []string{"identity"}
-	// The gRPC-HTTP2 specification requires this - it flushes out proxies that
-	// don't support HTTP trailers.
-	header["Te"] = []string{"trailers"}
+	header["Accept-Encoding"] = []string{compressionIdentity}
+	if g.CompressionName != "" && g.CompressionName != compressionIdentity {
+		header[grpcHeaderCompression] = []string{g.CompressionName}
+	}
+	if acceptCompression := g.CompressionPools.CommaSeparatedNames(); acceptCompression != "" {
+		header[grpcHeaderAcceptCompression] = []string{acceptCompression}
+	}
+	if !g.web {
+		// The gRPC-HTTP2 specification requires this - it flushes out proxies that
+		// don't support HTTP trailers.
+		header["Te"] = []string{"trailers"}
+	}
 }
 
 func (g *grpcClient) NewConn(
@@ -222,6 +296,7 @@ func (g *grpcClient) NewConn(
 	spec Spec,
 	header http.Header,
 ) streamingClientConn {
+	stats := statsFromContext(ctx, true /* client */)
 	if deadline, ok := ctx.Deadline(); ok {
 		encodedDeadline := grpcEncodeTimeout(time.Until(deadline))
 		header[grpcHeaderTimeout] = []string{encodedDeadline}
@@ -232,20 +307,26 @@ func (g *grpcClient) NewConn(
 		g.URL,
 		spec,
 		header,
+		g.RetryPolicy,
+		g.HedgingPolicy,
 	)
 	conn := &grpcClientConn{
-		spec:       spec,
-		peer:       g.Peer(),
-		duplexCall: duplexCall,
-		bufferPool: g.BufferPool,
-		protobuf:   g.Protobuf,
+		spec:             spec,
+		peer:             g.Peer(),
+		duplexCall:       duplexCall,
+		compressionPools: g.CompressionPools,
+		bufferPool:       g.BufferPool,
+		protobuf:         g.Protobuf,
 		marshaler: grpcMarshaler{
 			envelopeWriter: envelopeWriter{
-				ctx:          ctx,
-				sender:       duplexCall,
-				codec:        g.Codec,
-				bufferPool:   g.BufferPool,
-				sendMaxBytes: g.SendMaxBytes,
+				ctx:              ctx,
+				sender:           duplexCall,
+				compressionPool:  g.CompressionPools.Get(g.CompressionName),
+				codec:            g.Codec,
+				compressMinBytes: g.CompressMinBytes,
+				bufferPool:       g.BufferPool,
+				sendMaxBytes:     g.SendMaxBytes,
+				stats:            stats,
 			},
 		},
 		unmarshaler: grpcUnmarshaler{
@@ -255,32 +336,42 @@ func (g *grpcClient) NewConn(
 				codec:        g.Codec,
 				bufferPool:   g.BufferPool,
 				readMaxBytes: g.ReadMaxBytes,
+				stats:        stats,
 			},
 		},
 		responseHeader:  make(http.Header),
 		responseTrailer: make(http.Header),
 	}
 	duplexCall.SetValidateResponse(conn.validateResponse)
-	conn.readTrailers = func(_ *grpcUnmarshaler, call *duplexHTTPCall) http.Header {
-		// To access HTTP trailers, we need to read the body to EOF.
-		_, _ = discard(call)
-		return call.ResponseTrailer()
+	duplexCall.SetPeekError(conn.peekError)
+	if g.web {
+		conn.unmarshaler.web = true
+		conn.readTrailers = func(unmarshaler *grpcUnmarshaler, _ *duplexHTTPCall) http.Header {
+			return unmarshaler.WebTrailer()
+		}
+	} else {
+		conn.readTrailers = func(_ *grpcUnmarshaler, call *duplexHTTPCall) http.Header {
+			// To access HTTP trailers, we need to read the body to EOF.
+			_, _ = discard(call)
+			return call.ResponseTrailer()
+		}
 	}
 	return wrapClientConnWithCodedErrors(conn)
 }
 
-// grpcClientConn works for gRPC.
+// grpcClientConn works for both gRPC and gRPC-Web.
 type grpcClientConn struct {
-	spec            Spec
-	peer            Peer
-	duplexCall      *duplexHTTPCall
-	bufferPool      *bufferPool
-	protobuf        Codec // for errors
-	marshaler       grpcMarshaler
-	unmarshaler     grpcUnmarshaler
-	responseHeader  http.Header
-	responseTrailer http.Header
-	readTrailers    func(*grpcUnmarshaler, *duplexHTTPCall) http.Header
+	spec             Spec
+	peer             Peer
+	duplexCall       *duplexHTTPCall
+	compressionPools readOnlyCompressionPools
+	bufferPool       *bufferPool
+	protobuf         Codec // for errors
+	marshaler        grpcMarshaler
+	unmarshaler      grpcUnmarshaler
+	responseHeader   http.Header
+	responseTrailer  http.Header
+	readTrailers     func(*grpcUnmarshaler, *duplexHTTPCall) http.Header
 }
 
 func (cc *grpcClientConn) Spec() Spec {
@@ -338,7 +429,7 @@ func (cc *grpcClientConn) Receive(msg any) error {
 		return serverErr
 	}
 
-	// See if the server sent an explicit error in the HTTP trailers.
+	// See if the server sent an explicit error in the HTTP or gRPC-Web trailers.
 	serverErr := grpcErrorForTrailer(cc.protobuf, cc.responseTrailer)
 	if serverErr != nil && (errors.Is(err, io.EOF) || !errors.Is(serverErr, errTrailersWithoutGRPCStatus)) {
 		// We've either:
@@ -380,19 +471,65 @@ func (cc *grpcClientConn) onRequestSend(fn func(*http.Request)) {
 }
 
 func (cc *grpcClientConn) validateResponse(response *http.Response) *Error {
+	// Retried calls validate each attempt's response, so discard any metadata
+	// from earlier attempts.
+	clear(cc.responseHeader)
 	if err := grpcValidateResponse(
 		response,
 		cc.responseHeader,
+		cc.compressionPools,
+		cc.unmarshaler.web,
 		cc.marshaler.codec.Name(),
 	); err != nil {
 		return err
 	}
+	compression := getHeaderCanonical(response.Header, grpcHeaderCompression)
+	cc.unmarshaler.compressionPool = cc.compressionPools.Get(compression)
 	return nil
 }
 
+// peekError returns the error in a trailers-only response, which carries the
+// status in the HTTP headers.
+//
+// Servers using net/http can't send trailers-only gRPC responses, so they
+// send an empty body followed by trailers instead. For unary and client
+// streaming calls, we peek at the body to find those errors too. Server and
+// bidirectional streams may send headers and then wait indefinitely before
+// their first message, so peeking would delay their response headers.
+func (cc *grpcClientConn) peekError(response *http.Response) *Error {
+	status := response.Header
+	if getHeaderCanonical(status, grpcHeaderStatus) == "" {
+		if cc.unmarshaler.web || cc.spec.StreamType&StreamTypeServer != 0 {
+			return nil
+		}
+		var peeked [1]byte
+		bytesRead, err := response.Body.Read(peeked[:])
+		if bytesRead > 0 || !errors.Is(err, io.EOF) {
+			response.Body = &peekedBody{
+				Reader: io.MultiReader(bytes.NewReader(peeked[:bytesRead]), response.Body),
+				Closer: response.Body,
+			}
+			return nil
+		}
+		status = response.Trailer
+	}
+	serverErr := grpcErrorForTrailer(cc.protobuf, status)
+	if serverErr != nil {
+		serverErr.meta = status.Clone()
+	}
+	return serverErr
+}
+
+// peekedBody is a response body that's had some of its data read ahead.
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
 type grpcHandlerConn struct {
 	spec            Spec
 	peer            Peer
+	web             bool
 	bufferPool      *bufferPool
 	protobuf        Codec // for errors
 	marshaler       grpcMarshaler
@@ -470,6 +607,21 @@ func (hc *grpcHandlerConn) Close(err error) (retErr error) {
 	)
 	mergeHeaders(mergedTrailers, hc.responseTrailer)
 	grpcErrorToTrailer(mergedTrailers, hc.protobuf, err)
+	if hc.web && !hc.wroteToBody && len(hc.responseHeader) == 0 {
+		// We're using gRPC-Web, we haven't yet written to the body, and there are no
+		// custom headers. That means we can send a "trailers-only" response and send
+		// trailing metadata as HTTP headers (instead of as trailers).
+		mergeHeaders(hc.responseWriter.Header(), mergedTrailers)
+		return nil
+	}
+	if hc.web {
+		// We're using gRPC-Web and we've already sent the headers, so we write
+		// trailing metadata to the HTTP body.
+		if err := hc.marshaler.MarshalWebTrailers(mergedTrailers); err != nil {
+			return err
+		}
+		return nil // must be a literal nil: nil *Error is a non-nil error
+	}
 	// We're using standard gRPC. Even if we haven't written to the body and
 	// we're sending a "trailers-only" response, we must send trailing metadata
 	// as HTTP trailers. (If we had frame-level control of the HTTP/2 layer, we
@@ -523,6 +675,7 @@ func (m *grpcMarshaler) MarshalWebTrailers(trailer http.Header) *Error {
 type grpcUnmarshaler struct {
 	envelopeReader
 
+	web        bool
 	webTrailer http.Header
 }
 
@@ -538,7 +691,28 @@ func (u *grpcUnmarshaler) Unmarshal(message any) *Error {
 	data := env.Data
 	u.last.Data = nil // don't keep a reference to it
 	defer u.bufferPool.Put(data)
-	return errorf(CodeInternal, "protocol error: invalid envelope flags %d", env.Flags)
+	if !u.web || !env.IsSet(grpcFlagEnvelopeTrailer) {
+		return errorf(CodeInternal, "protocol error: invalid envelope flags %d", env.Flags)
+	}
+
+	// Per the gRPC-Web specification, trailers should be encoded as an HTTP/1
+	// headers block _without_ the terminating newline. To make the headers
+	// parseable by net/textproto, we need to add the newline.
+	if err := data.WriteByte('\n'); err != nil {
+		return errorf(CodeInternal, "unmarshal web trailers: %w", err)
+	}
+	bufferedReader := bufio.NewReader(data)
+	mimeReader := textproto.NewReader(bufferedReader)
+	mimeHeader, mimeErr := mimeReader.ReadMIMEHeader()
+	if mimeErr != nil {
+		return errorf(
+			CodeInternal,
+			"gRPC-Web protocol error: trailers invalid: %w",
+			mimeErr,
+		)
+	}
+	u.webTrailer = http.Header(mimeHeader)
+	return errSpecialEnvelope
 }
 
 func (u *grpcUnmarshaler) WebTrailer() http.Header {
@@ -548,17 +722,33 @@ func (u *grpcUnmarshaler) WebTrailer() http.Header {
 func grpcValidateResponse(
 	response *http.Response,
 	header http.Header,
+	availableCompressors readOnlyCompressionPools,
+	web bool,
 	codecName string,
 ) *Error {
 	if response.StatusCode != http.StatusOK {
 		return errorf(httpToCode(response.StatusCode), "HTTP status %v", response.Status)
 	}
 	if err := grpcValidateResponseContentType(
+		web,
 		codecName,
 		getHeaderCanonical(response.Header, headerContentType),
 	); err != nil {
 		return err
 	}
+	if compression := getHeaderCanonical(response.Header, grpcHeaderCompression); compression != "" &&
+		compression != compressionIdentity &&
+		!availableCompressors.Contains(compression) {
+		// Per https://github.com/grpc/grpc/blob/master/doc/compression.md, we
+		// should return CodeInternal and specify acceptable compression(s) (in
+		// addition to setting the Grpc-Accept-Encoding header).
+		return errorf(
+			CodeInternal,
+			"unknown encoding %q: accepted encodings are %v",
+			compression,
+			availableCompressors.CommaSeparatedNames(),
+		)
+	}
 	// The response is valid, so we should expose the headers.
 	mergeHeaders(header, response.Header)
 	return nil
@@ -695,16 +885,33 @@ func grpcTimeoutUnitLookup(unit byte) (time.Duration, error) {
 	}
 }
 
-func grpcCodecForContentType(contentType string) string {
-	if contentType == grpcContentTypeDefault {
+func grpcCodecForContentType(web bool, contentType string) string {
+	if (!web && contentType == grpcContentTypeDefault) || (web && contentType == grpcWebContentTypeDefault) {
 		// implicitly protobuf
 		return codecNameProto
 	}
+	if web && grpcWebIsTextContentType(contentType) {
+		if contentType == grpcWebTextContentTypeDefault {
+			return codecNameProto
+		}
+		return strings.TrimPrefix(contentType, grpcWebTextContentTypePrefix)
+	}
 	prefix := grpcContentTypePrefix
+	if web {
+		prefix = grpcWebContentTypePrefix
+	}
 	return strings.TrimPrefix(contentType, prefix)
 }
 
-func grpcContentTypeForCodecName(name string) string {
+func grpcWebIsTextContentType(contentType string) bool {
+	return contentType == grpcWebTextContentTypeDefault ||
+		strings.HasPrefix(contentType, grpcWebTextContentTypePrefix)
+}
+
+func grpcContentTypeForCodecName(web bool, name string) string {
+	if web {
+		return grpcWebContentTypePrefix + name
+	}
 	if name == codecNameProto {
 		// For compatibility with Google Cloud Platform's frontends, prefer an
 		// implicit default codec.
@@ -857,9 +1064,12 @@ func validateHex(input string) error {
 	return nil
 }
 
-func grpcValidateResponseContentType(requestCodecName string, responseContentType string) *Error {
+func grpcValidateResponseContentType(web bool, requestCodecName string, responseContentType string) *Error {
 	// Responses must have valid content-type that indicates same codec as the request.
 	bare, prefix := grpcContentTypeDefault, grpcContentTypePrefix
+	if web {
+		bare, prefix = grpcWebContentTypeDefault, grpcWebContentTypePrefix
+	}
 	if responseContentType == prefix+requestCodecName ||
 		(requestCodecName == codecNameProto && responseContentType == bare) {
 		return nil
@@ -880,3 +1090,61 @@ func grpcValidateResponseContentType(requestCodecName string, responseContentTyp
 		expectedContentType,
 	)
 }
+
+// grpcWebTextReader decodes a base64-encoded gRPC-Web request body. Clients
+// may encode each chunk of the stream separately, so the body may be a
+// concatenation of padded base64 strings; we decode it one four-byte quantum
+// at a time to handle padding in the middle of the stream.
+type grpcWebTextReader struct {
+	reader  io.Reader
+	encoded [4]byte
+	pending int // bytes buffered in encoded
+	decoded []byte
+	err     error
+}
+
+func (r *grpcWebTextReader) Read(data []byte) (int, error) {
+	for len(r.decoded) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := r.reader.Read(r.encoded[r.pending:])
+		r.pending += n
+		if r.pending == len(r.encoded) {
+			var quantum [3]byte
+			size, decodeErr := base64.StdEncoding.Decode(quantum[:], r.encoded[:])
+			if decodeErr != nil {
+				return 0, errorf(CodeInvalidArgument, "gRPC-Web text protocol error: %w", decodeErr)
+			}
+			r.decoded = append(r.decoded[:0], quantum[:size]...)
+			r.pending = 0
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && r.pending != 0 {
+				err = errorf(CodeInvalidArgument, "gRPC-Web text protocol error: %w", io.ErrUnexpectedEOF)
+			}
+			r.err = err
+		}
+	}
+	n := copy(data, r.decoded)
+	r.decoded = r.decoded[n:]
+	return n, nil
+}
+
+// grpcWebTextWriter base64-encodes each write to a gRPC-Web text response.
+// Every write is padded independently, which gRPC-Web clients support.
+type grpcWebTextWriter struct {
+	writer io.Writer
+}
+
+func (w *grpcWebTextWriter) Write(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(encoded, data)
+	if _, err := w.writer.Write(encoded); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
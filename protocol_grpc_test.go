@@ -15,8 +15,10 @@
 package scalpel
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/http/httptest"
@@ -32,7 +34,7 @@ import (
 
 func TestGRPCHandlerSender(t *testing.T) {
 	t.Parallel()
-	newConn := func() *grpcHandlerConn {
+	newConn := func(web bool) *grpcHandlerConn {
 		responseWriter := httptest.NewRecorder()
 		protobufCodec := &protoBinaryCodec{}
 		bufferPool := newBufferPool()
@@ -44,6 +46,7 @@ func TestGRPCHandlerSender(t *testing.T) {
 		assert.Nil(t, err)
 		return &grpcHandlerConn{
 			spec:       Spec{},
+			web:        web,
 			bufferPool: bufferPool,
 			protobuf:   protobufCodec,
 			marshaler: grpcMarshaler{
@@ -66,9 +69,13 @@ func TestGRPCHandlerSender(t *testing.T) {
 			},
 		}
 	}
+	t.Run("web", func(t *testing.T) {
+		t.Parallel()
+		testGRPCHandlerConnMetadata(t, newConn(true))
+	})
 	t.Run("http2", func(t *testing.T) {
 		t.Parallel()
-		testGRPCHandlerConnMetadata(t, newConn())
+		testGRPCHandlerConnMetadata(t, newConn(false))
 	})
 }
 
@@ -172,6 +179,58 @@ func TestGRPCPercentEncoding(t *testing.T) {
 	roundtrip("fiancée")
 }
 
+func TestGRPCWebTrailerMarshalling(t *testing.T) {
+	t.Parallel()
+	responseWriter := httptest.NewRecorder()
+	marshaler := grpcMarshaler{
+		envelopeWriter: envelopeWriter{
+			sender:     writeSender{writer: responseWriter},
+			bufferPool: newBufferPool(),
+		},
+	}
+	trailer := http.Header{}
+	trailer.Add("grpc-status", "0")
+	trailer.Add("Grpc-Message", "Foo")
+	trailer.Add("User-Provided", "bar")
+	err := marshaler.MarshalWebTrailers(trailer)
+	assert.Nil(t, err)
+	responseWriter.Body.Next(5) // skip flags and message length
+	marshalled := responseWriter.Body.String()
+	assert.Equal(t, marshalled, "grpc-message: Foo\r\ngrpc-status: 0\r\nuser-provided: bar\r\n")
+}
+
+func TestGRPCWebTextReaderWriter(t *testing.T) {
+	t.Parallel()
+	t.Run("round_trip", func(t *testing.T) {
+		t.Parallel()
+		var encoded bytes.Buffer
+		writer := &grpcWebTextWriter{writer: &encoded}
+		// Each write is padded independently, so the reader must handle
+		// several concatenated base64 segments.
+		for _, chunk := range []string{"a", "bc", "def", "ghij"} {
+			n, err := writer.Write([]byte(chunk))
+			assert.Nil(t, err)
+			assert.Equal(t, n, len(chunk))
+		}
+		assert.Equal(t, encoded.String(), "YQ==YmM=ZGVmZ2hpag==")
+		decoded, err := io.ReadAll(&grpcWebTextReader{reader: &encoded})
+		assert.Nil(t, err)
+		assert.Equal(t, string(decoded), "abcdefghij")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := io.ReadAll(&grpcWebTextReader{reader: strings.NewReader("!!!!")})
+		assert.NotNil(t, err)
+		assert.Equal(t, CodeOf(err), CodeInvalidArgument)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+		_, err := io.ReadAll(&grpcWebTextReader{reader: strings.NewReader("YWJjZA")})
+		assert.NotNil(t, err)
+		assert.Equal(t, CodeOf(err), CodeInvalidArgument)
+	})
+}
+
 func BenchmarkGRPCPercentEncoding(b *testing.B) {
 	input := "Hello, 世界"
 	want := "Hello, %E4%B8%96%E7%95%8C"
@@ -211,6 +270,7 @@ func BenchmarkGRPCTimeoutEncoding(b *testing.B) {
 func TestGRPCValidateResponseContentType(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
+		web                 bool
 		codecName           string
 		responseContentType string
 		expectCode          Code
@@ -224,6 +284,59 @@ func TestGRPCValidateResponseContentType(t *testing.T) {
 			codecName:           codecNameProto,
 			responseContentType: "application/grpc+proto",
 		},
+		{
+			codecName:           codecNameJSON,
+			responseContentType: "application/grpc+json",
+		},
+		{
+			codecName:           codecNameProto,
+			web:                 true,
+			responseContentType: "application/grpc-web",
+		},
+		{
+			codecName:           codecNameProto,
+			web:                 true,
+			responseContentType: "application/grpc-web+proto",
+		},
+		{
+			codecName:           codecNameJSON,
+			web:                 true,
+			responseContentType: "application/grpc-web+json",
+		},
+		// Mismatched response codec
+		{
+			codecName:           codecNameProto,
+			responseContentType: "application/grpc+json",
+			expectCode:          CodeInternal,
+		},
+		{
+			codecName:           codecNameJSON,
+			responseContentType: "application/grpc",
+			expectCode:          CodeInternal,
+		},
+		{
+			codecName:           codecNameJSON,
+			responseContentType: "application/grpc+proto",
+			expectCode:          CodeInternal,
+		},
+		{
+			codecName:           codecNameProto,
+			web:                 true,
+			responseContentType: "application/grpc-web+json",
+			expectCode:          CodeInternal,
+		},
+		{
+			codecName:           codecNameJSON,
+			web:                 true,
+			responseContentType: "application/grpc-web",
+			expectCode:          CodeInternal,
+		},
+		{
+			codecName:           codecNameJSON,
+			web:                 true,
+			responseContentType: "application/grpc-web+proto",
+			expectCode:          CodeInternal,
+		},
 		// Disallowed content-types
 		{
 			codecName:           codecNameProto,
@@ -240,6 +353,46 @@ func TestGRPCValidateResponseContentType(t *testing.T) {
 			responseContentType: "application/grpc-web+proto",
 			expectCode:          CodeUnknown,
 		},
+		{
+			codecName:           codecNameJSON,
+			responseContentType: "application/json",
+			expectCode:          CodeUnknown,
+		},
+		{
+			codecName:           codecNameJSON,
+			responseContentType: "application/grpc-web+json",
+			expectCode:          CodeUnknown,
+		},
+		{
+			codecName:           codecNameProto,
+			web:                 true,
+			responseContentType: "application/proto",
+			expectCode:          CodeUnknown,
+		},
+		{
+			codecName:           codecNameProto,
+			web:                 true,
+			responseContentType: "application/grpc",
+			expectCode:          CodeUnknown,
+		},
+		{
+			codecName:           codecNameProto,
+			web:                 true,
+			responseContentType: "application/grpc+proto",
+			expectCode:          CodeUnknown,
+		},
+		{
+			codecName:           codecNameJSON,
+			web:                 true,
+			responseContentType: "application/json",
+			expectCode:          CodeUnknown,
+		},
+		{
+			codecName:           codecNameJSON,
+			web:                 true,
+			responseContentType: "application/grpc+json",
+			expectCode:          CodeUnknown,
+		},
 		{
 			codecName:           codecNameProto,
 			responseContentType: "some/garbage",
@@ -248,10 +401,14 @@ func TestGRPCValidateResponseContentType(t *testing.T) {
 	}
 	for _, testCase := range testCases {
 		protocol := ProtocolGRPC
+		if testCase.web {
+			protocol = ProtocolGRPCWeb
+		}
 		testCaseName := fmt.Sprintf("%s_%s->%s", protocol, testCase.codecName, testCase.responseContentType)
 		t.Run(testCaseName, func(t *testing.T) {
 			t.Parallel()
 			err := grpcValidateResponseContentType(
+				testCase.web,
 				testCase.codecName,
 				testCase.responseContentType,
 			)
@@ -0,0 +1,323 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxy forwards RPCs to upstream servers without decoding them. It
+// works with the gRPC, gRPC-Web, and Connect protocols, and with all four
+// types of RPC.
+//
+// Streaming bodies are forwarded frame by frame with [scalpel.FrameReader]
+// and [scalpel.FrameWriter], so messages are never decompressed or
+// unmarshaled and the proxy doesn't need generated code for the services it
+// serves. Headers, trailers, timeouts, and cancellation are propagated in both
+// directions.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/envelope"
+)
+
+const (
+	grpcHeaderTimeout    = "Grpc-Timeout"
+	connectHeaderTimeout = "Connect-Timeout-Ms"
+)
+
+// hopHeaders are the hop-by-hop headers, which apply to a single connection
+// and must not be forwarded. See RFC 9110, section 7.6.1.
+var hopHeaders = []string{ //nolint:gochecknoglobals
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// An Upstream is a server that RPCs are forwarded to.
+type Upstream struct {
+	// HTTPClient sends requests to the upstream. Bidirectional streaming
+	// requires a client that supports HTTP/2.
+	HTTPClient scalpel.HTTPClient
+	// BaseURL is the upstream's URL, like "https://api.acme.com/grpc". The
+	// procedure is appended to it.
+	BaseURL string
+}
+
+// A RouteFunc picks the upstream for an RPC. The procedure is the request's
+// URL path, like "/acme.foo.v1.FooService/Bar". If it returns a nil Upstream
+// and a nil error, the client gets CodeUnimplemented. Errors are sent to the
+// client as-is, so they should usually be [*scalpel.Error] values with an
+// appropriate code.
+type RouteFunc func(*http.Request) (*Upstream, error)
+
+// A Handler is an [http.Handler] that forwards every RPC it receives to the
+// upstream chosen by its RouteFunc. It's not tied to any procedure or
+// service, so it's typically mounted at the root of a server.
+type Handler struct {
+	route        RouteFunc
+	readMaxBytes int
+	errorWriter  *scalpel.ErrorWriter
+}
+
+// NewHandler constructs a Handler.
+func NewHandler(route RouteFunc, options ...Option) *Handler {
+	handler := &Handler{
+		route:       route,
+		errorWriter: scalpel.NewErrorWriter(),
+	}
+	for _, option := range options {
+		option.apply(handler)
+	}
+	return handler
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *Handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	upstream, err := h.route(request)
+	if err == nil && upstream == nil {
+		err = scalpel.NewError(scalpel.CodeUnimplemented, fmt.Errorf("no upstream for %s", request.URL.Path))
+	}
+	if err != nil {
+		_ = h.errorWriter.Write(responseWriter, request, err)
+		return
+	}
+	ctx, cancel := context.WithCancel(request.Context())
+	defer cancel()
+	header := request.Header.Clone()
+	removeHopHeaders(header)
+	if request.Header.Get("Te") == "trailers" {
+		header.Set("Te", "trailers")
+	}
+	if timeout, ok := parseTimeout(request.Header); ok {
+		// Propagate the deadline, less the time spent in the proxy.
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if deadline, ok := ctx.Deadline(); ok {
+			setTimeout(header, time.Until(deadline))
+		}
+	}
+
+	var (
+		body          = request.Body
+		contentLength = request.ContentLength
+		requestErr    = make(chan error, 1)
+	)
+	if envelope.IsEnveloped(request.Header.Get("Content-Type")) && request.Body != nil {
+		// Forward request frames as they arrive, so streams work in both
+		// directions at once.
+		pipeReader, pipeWriter := io.Pipe()
+		defer pipeReader.Close()
+		go func() {
+			err := copyFrames(pipeWriter, request.Body, h.readMaxBytes, nil)
+			requestErr <- err
+			_ = pipeWriter.CloseWithError(err)
+		}()
+		body, contentLength = pipeReader, -1
+		// HTTP/1.1 handlers can't read the request body once they've started
+		// writing the response unless full duplex is enabled.
+		_ = http.NewResponseController(responseWriter).EnableFullDuplex()
+	}
+	outbound, err := http.NewRequestWithContext(ctx, request.Method, strings.TrimSuffix(upstream.BaseURL, "/")+request.URL.RequestURI(), body)
+	if err != nil {
+		_ = h.errorWriter.Write(responseWriter, request, scalpel.NewError(scalpel.CodeInternal, err))
+		return
+	}
+	outbound.Header = header
+	outbound.ContentLength = contentLength
+	response, err := upstream.HTTPClient.Do(outbound)
+	if err != nil {
+		_ = h.errorWriter.Write(responseWriter, request, upstreamError(ctx, requestErr, err))
+		return
+	}
+	defer response.Body.Close()
+
+	responseHeader := responseWriter.Header()
+	for key, values := range response.Header {
+		responseHeader[key] = values
+	}
+	removeHopHeaders(responseHeader)
+	responseHeader.Del("Content-Length")
+	responseWriter.WriteHeader(response.StatusCode)
+	flush := func() { _ = http.NewResponseController(responseWriter).Flush() }
+	flush()
+	if envelope.IsEnveloped(response.Header.Get("Content-Type")) {
+		err = copyFrames(responseWriter, response.Body, 0, flush)
+	} else {
+		// gRPC-Web text responses may be streams, so they're flushed as
+		// they're read.
+		_, err = io.Copy(&flushWriter{writer: responseWriter, flush: flush}, response.Body)
+	}
+	if err != nil {
+		// The status has already been sent, so the only way to tell the
+		// client that the response is incomplete is to abort it.
+		panic(http.ErrAbortHandler) //nolint:forbidigo
+	}
+	for key, values := range response.Trailer {
+		responseHeader[http.TrailerPrefix+key] = values
+	}
+}
+
+// An Option configures a [Handler].
+type Option interface {
+	apply(*Handler)
+}
+
+// WithReadMaxBytes limits the size of each request frame. Larger frames fail
+// the RPC with CodeResourceExhausted. Setting WithReadMaxBytes to zero, the
+// default, allows frames of any size.
+func WithReadMaxBytes(maxBytes int) Option {
+	return &readMaxBytesOption{Max: maxBytes}
+}
+
+type readMaxBytesOption struct {
+	Max int
+}
+
+func (o *readMaxBytesOption) apply(handler *Handler) {
+	handler.readMaxBytes = o.Max
+}
+
+// copyFrames copies frames from src to dst until the end of src, calling
+// flush after each frame if it's not nil.
+func copyFrames(dst io.Writer, src io.Reader, readMaxBytes int, flush func()) error {
+	reader := scalpel.NewFrameReader(src, readMaxBytes)
+	writer := scalpel.NewFrameWriter(dst)
+	for {
+		frame, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := writer.Write(frame); err != nil {
+			return err
+		}
+		if flush != nil {
+			flush()
+		}
+	}
+}
+
+// upstreamError explains why the upstream request failed. Errors reading the
+// request take precedence, since they cause the upstream request to fail.
+func upstreamError(ctx context.Context, requestErr <-chan error, err error) error {
+	select {
+	case err := <-requestErr:
+		var connectErr *scalpel.Error
+		if errors.As(err, &connectErr) {
+			return connectErr
+		}
+	default:
+	}
+	switch ctxErr := ctx.Err(); {
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		return scalpel.NewError(scalpel.CodeDeadlineExceeded, err)
+	case errors.Is(ctxErr, context.Canceled):
+		return scalpel.NewError(scalpel.CodeCanceled, err)
+	}
+	return scalpel.NewError(scalpel.CodeUnavailable, err)
+}
+
+// flushWriter flushes after every write.
+type flushWriter struct {
+	writer io.Writer
+	flush  func()
+}
+
+func (w *flushWriter) Write(data []byte) (int, error) {
+	n, err := w.writer.Write(data)
+	w.flush()
+	return n, err
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, key := range header.Values("Connection") {
+		for _, name := range strings.Split(key, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, key := range hopHeaders {
+		header.Del(key)
+	}
+}
+
+// parseTimeout parses the gRPC or Connect timeout header, if any.
+func parseTimeout(header http.Header) (time.Duration, bool) {
+	if value := header.Get(connectHeaderTimeout); value != "" {
+		millis, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || millis < 0 || len(value) > 10 {
+			return 0, false
+		}
+		return time.Duration(millis) * time.Millisecond, true
+	}
+	value := header.Get(grpcHeaderTimeout)
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{
+		'n': time.Nanosecond,
+		'u': time.Microsecond,
+		'm': time.Millisecond,
+		'S': time.Second,
+		'M': time.Minute,
+		'H': time.Hour,
+	}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+	count, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || count < 0 || unit == time.Hour && count > int64(time.Duration(1<<63-1)/time.Hour) {
+		return 0, false
+	}
+	return time.Duration(count) * unit, true
+}
+
+// setTimeout replaces the timeout header that the client sent.
+func setTimeout(header http.Header, timeout time.Duration) {
+	timeout = max(timeout, 0)
+	if header.Get(connectHeaderTimeout) != "" {
+		header.Set(connectHeaderTimeout, strconv.FormatInt(timeout.Milliseconds(), 10))
+		return
+	}
+	// gRPC timeouts have at most 8 digits, so use the finest unit that fits.
+	for _, unit := range []struct {
+		size time.Duration
+		name string
+	}{
+		{time.Nanosecond, "n"},
+		{time.Microsecond, "u"},
+		{time.Millisecond, "m"},
+		{time.Second, "S"},
+		{time.Minute, "M"},
+	} {
+		if timeout < unit.size*1e8 {
+			header.Set(grpcHeaderTimeout, strconv.FormatInt(int64(timeout/unit.size), 10)+unit.name)
+			return
+		}
+	}
+	header.Set(grpcHeaderTimeout, strconv.FormatInt(int64(timeout/time.Hour), 10)+"H")
+}
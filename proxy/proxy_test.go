@@ -0,0 +1,275 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"github.com/agentio/scalpel/proxy"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+	mux := connect.NewMux(nil)
+	mux.HandleService(pingv1connect.NewPingServiceHandler(newPingService()))
+	upstream := memhttptest.NewServer(t, mux)
+	proxyServer := memhttptest.NewServer(t, proxy.NewHandler(
+		func(request *http.Request) (*proxy.Upstream, error) {
+			if !strings.HasPrefix(request.URL.Path, "/"+pingv1connect.PingServiceName+"/") {
+				return nil, nil //nolint:nilnil
+			}
+			return &proxy.Upstream{HTTPClient: upstream.Client(), BaseURL: upstream.URL()}, nil
+		},
+		proxy.WithReadMaxBytes(1024),
+	))
+	protocols := map[string][]connect.ClientOption{
+		"connect":  nil,
+		"grpc":     {connect.WithGRPC()},
+		"grpcweb":  {connect.WithGRPCWeb()},
+		"get":      {connect.WithHTTPGet()},
+		"compress": {connect.WithSendGzip()},
+	}
+	for name, options := range protocols {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			client := pingv1connect.NewPingServiceClient(proxyServer.Client(), proxyServer.URL(), options...)
+			t.Run("unary", func(t *testing.T) {
+				t.Parallel()
+				request := connect.NewRequest(&pingv1.PingRequest{Number: 42, Text: "hello"})
+				request.Header().Set("Echo", "header")
+				response, err := client.Ping(t.Context(), request)
+				assert.Nil(t, err)
+				assert.Equal(t, response.Msg.GetNumber(), 42)
+				assert.Equal(t, response.Msg.GetText(), "hello")
+				assert.Equal(t, response.Header().Get("Echo"), "header")
+				assert.Equal(t, response.Trailer().Get("Echo"), "trailer")
+			})
+			t.Run("error", func(t *testing.T) {
+				t.Parallel()
+				_, err := client.Fail(t.Context(), connect.NewRequest(&pingv1.FailRequest{Code: int32(connect.CodeAborted)}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeAborted)
+				var connectErr *connect.Error
+				assert.True(t, errors.As(err, &connectErr))
+				assert.Equal(t, connectErr.Message(), "fail")
+				assert.Equal(t, connectErr.Meta().Get("Reason"), "requested")
+			})
+			t.Run("client_stream", func(t *testing.T) {
+				t.Parallel()
+				stream := client.Sum(t.Context())
+				for i := int64(1); i <= 4; i++ {
+					assert.Nil(t, stream.Send(&pingv1.SumRequest{Number: i}))
+				}
+				response, err := stream.CloseAndReceive()
+				assert.Nil(t, err)
+				assert.Equal(t, response.Msg.GetSum(), 10)
+			})
+			t.Run("server_stream", func(t *testing.T) {
+				t.Parallel()
+				stream, err := client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{Number: 3}))
+				assert.Nil(t, err)
+				var got []int64
+				for stream.Receive() {
+					got = append(got, stream.Msg().GetNumber())
+				}
+				assert.Nil(t, stream.Err())
+				assert.Equal(t, got, []int64{1, 2, 3})
+				assert.Equal(t, stream.ResponseTrailer().Get("Echo"), "trailer")
+				assert.Nil(t, stream.Close())
+			})
+			t.Run("bidi_stream", func(t *testing.T) {
+				t.Parallel()
+				stream := client.CumSum(t.Context())
+				// Each response arrives before the next request is sent, so
+				// frames must be forwarded as soon as they're read.
+				for i, want := range []int64{1, 3, 6} {
+					assert.Nil(t, stream.Send(&pingv1.CumSumRequest{Number: int64(i + 1)}))
+					response, err := stream.Receive()
+					assert.Nil(t, err)
+					assert.Equal(t, response.GetSum(), want)
+				}
+				assert.Nil(t, stream.CloseRequest())
+				_, err := stream.Receive()
+				assert.ErrorIs(t, err, io.EOF)
+				assert.Nil(t, stream.CloseResponse())
+			})
+			t.Run("deadline", func(t *testing.T) {
+				t.Parallel()
+				ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+				defer cancel()
+				request := connect.NewRequest(&pingv1.PingRequest{Text: "deadline"})
+				response, err := client.Ping(ctx, request)
+				assert.Nil(t, err)
+				remaining, err := time.ParseDuration(response.Header().Get("Remaining"))
+				assert.Nil(t, err)
+				assert.True(t, remaining > 0 && remaining <= time.Minute)
+				ctx, cancel = context.WithTimeout(t.Context(), 10*time.Millisecond)
+				defer cancel()
+				_, err = client.Ping(ctx, connect.NewRequest(&pingv1.PingRequest{Text: "block"}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeDeadlineExceeded)
+			})
+			t.Run("read_max_bytes", func(t *testing.T) {
+				t.Parallel()
+				if name != "grpc" {
+					t.Skip("only enveloped requests are limited")
+				}
+				_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Text: strings.Repeat("x", 2048)}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeResourceExhausted)
+			})
+		})
+	}
+	t.Run("grpcweb_text", func(t *testing.T) {
+		t.Parallel()
+		// The client doesn't send gRPC-Web text, so build the request by hand.
+		payload, err := proto.Marshal(&pingv1.PingRequest{Number: 42, Text: "hello"})
+		assert.Nil(t, err)
+		envelope := make([]byte, 5, 5+len(payload))
+		binary.BigEndian.PutUint32(envelope[1:5], uint32(len(payload)))
+		envelope = append(envelope, payload...)
+		request, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodPost,
+			proxyServer.URL()+pingv1connect.PingServicePingProcedure,
+			strings.NewReader(base64.StdEncoding.EncodeToString(envelope)),
+		)
+		assert.Nil(t, err)
+		request.Header.Set("Content-Type", "application/grpc-web-text+proto")
+		response, err := proxyServer.Client().Do(request)
+		assert.Nil(t, err)
+		defer response.Body.Close()
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assert.Equal(t, response.Header.Get("Content-Type"), "application/grpc-web-text+proto")
+		encoded, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+		// The response is a series of independently padded base64 segments.
+		var decoded []byte
+		for i := 0; i+4 <= len(encoded); i += 4 {
+			quantum, err := base64.StdEncoding.DecodeString(string(encoded[i : i+4]))
+			assert.Nil(t, err)
+			decoded = append(decoded, quantum...)
+		}
+		assert.True(t, len(decoded) >= 5)
+		assert.Equal(t, decoded[0], byte(0))
+		size := binary.BigEndian.Uint32(decoded[1:5])
+		var msg pingv1.PingResponse
+		assert.Nil(t, proto.Unmarshal(decoded[5:5+size], &msg))
+		assert.Equal(t, msg.GetNumber(), 42)
+		assert.Equal(t, msg.GetText(), "hello")
+		trailer := decoded[5+size:]
+		assert.True(t, len(trailer) >= 5)
+		assert.Equal(t, trailer[0], byte(0x80))
+		assert.True(t, strings.Contains(strings.ToLower(string(trailer[5:])), "grpc-status: 0"))
+	})
+	t.Run("no_upstream", func(t *testing.T) {
+		t.Parallel()
+		response, err := proxyServer.Client().Post(proxyServer.URL()+"/acme.v1.UnknownService/Method", "application/json", strings.NewReader("{}"))
+		assert.Nil(t, err)
+		assert.Nil(t, response.Body.Close())
+		assert.Equal(t, response.StatusCode, http.StatusNotImplemented)
+	})
+}
+
+func TestProxyRouteError(t *testing.T) {
+	t.Parallel()
+	proxyServer := memhttptest.NewServer(t, proxy.NewHandler(func(*http.Request) (*proxy.Upstream, error) {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("forbidden"))
+	}))
+	client := pingv1connect.NewPingServiceClient(proxyServer.Client(), proxyServer.URL(), connect.WithGRPC())
+	_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+	assert.Equal(t, connect.CodeOf(err), connect.CodePermissionDenied)
+
+	// Upstreams that can't be reached are unavailable.
+	proxyServer = memhttptest.NewServer(t, proxy.NewHandler(func(*http.Request) (*proxy.Upstream, error) {
+		return &proxy.Upstream{HTTPClient: http.DefaultClient, BaseURL: "http://127.0.0.1:0"}, nil
+	}))
+	client = pingv1connect.NewPingServiceClient(proxyServer.Client(), proxyServer.URL())
+	_, err = client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+	assert.Equal(t, connect.CodeOf(err), connect.CodeUnavailable)
+}
+
+func newPingService() *pingv1connect.FakePingService {
+	return &pingv1connect.FakePingService{
+		PingFunc: func(ctx context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+			response := connect.NewResponse(&pingv1.PingResponse{
+				Number: request.Msg.GetNumber(),
+				Text:   request.Msg.GetText(),
+			})
+			switch request.Msg.GetText() {
+			case "deadline":
+				if deadline, ok := ctx.Deadline(); ok {
+					response.Header().Set("Remaining", time.Until(deadline).String())
+				}
+			case "block":
+				<-ctx.Done()
+				return nil, connect.NewError(connect.CodeDeadlineExceeded, ctx.Err())
+			}
+			response.Header().Set("Echo", request.Header().Get("Echo"))
+			response.Trailer().Set("Echo", "trailer")
+			return response, nil
+		},
+		FailFunc: func(_ context.Context, request *connect.Request[pingv1.FailRequest]) (*connect.Response[pingv1.FailResponse], error) {
+			err := connect.NewError(connect.Code(request.Msg.GetCode()), errors.New("fail"))
+			err.Meta().Set("Reason", "requested")
+			return nil, err
+		},
+		SumFunc: func(_ context.Context, stream *connect.ClientStream[pingv1.SumRequest]) (*connect.Response[pingv1.SumResponse], error) {
+			var sum int64
+			for stream.Receive() {
+				sum += stream.Msg().GetNumber()
+			}
+			if err := stream.Err(); err != nil {
+				return nil, err
+			}
+			return connect.NewResponse(&pingv1.SumResponse{Sum: sum}), nil
+		},
+		CountUpFunc: func(_ context.Context, request *connect.Request[pingv1.CountUpRequest], stream *connect.ServerStream[pingv1.CountUpResponse]) error {
+			for i := int64(1); i <= request.Msg.GetNumber(); i++ {
+				if err := stream.Send(&pingv1.CountUpResponse{Number: i}); err != nil {
+					return err
+				}
+			}
+			stream.ResponseTrailer().Set("Echo", "trailer")
+			return nil
+		},
+		CumSumFunc: func(_ context.Context, stream *connect.BidiStream[pingv1.CumSumRequest, pingv1.CumSumResponse]) error {
+			var sum int64
+			for {
+				request, err := stream.Receive()
+				if errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				sum += request.GetNumber()
+				if err := stream.Send(&pingv1.CumSumResponse{Sum: sum}); err != nil {
+					return err
+				}
+			}
+		},
+	}
+}
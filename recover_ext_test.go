@@ -0,0 +1,120 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+)
+
+type panicPingServer struct {
+	pingv1connect.UnimplementedPingServiceHandler
+
+	panicWith any
+}
+
+func (s *panicPingServer) Ping(
+	context.Context,
+	*connect.Request[pingv1.PingRequest],
+) (*connect.Response[pingv1.PingResponse], error) {
+	panic(s.panicWith) //nolint:forbidigo
+}
+
+func (s *panicPingServer) CountUp(
+	_ context.Context,
+	_ *connect.Request[pingv1.CountUpRequest],
+	stream *connect.ServerStream[pingv1.CountUpResponse],
+) error {
+	if err := stream.Send(&pingv1.CountUpResponse{}); err != nil {
+		return err
+	}
+	panic(s.panicWith) //nolint:forbidigo
+}
+
+func TestWithRecover(t *testing.T) {
+	t.Parallel()
+	var (
+		mu        sync.Mutex
+		recovered []any
+		stacks    []string
+	)
+	handle := func(_ context.Context, spec connect.Spec, header http.Header, r any, stack []byte) {
+		assert.True(t, strings.HasPrefix(spec.Procedure, "/connect.ping.v1.PingService/"))
+		assert.NotNil(t, header)
+		mu.Lock()
+		defer mu.Unlock()
+		recovered = append(recovered, r)
+		stacks = append(stacks, string(stack))
+	}
+	assertHandled := func(err error) {
+		t.Helper()
+		assert.Equal(t, connect.CodeOf(err), connect.CodeInternal)
+		// Errors sent by the handler, rather than a reset stream.
+		assert.True(t, strings.Contains(err.Error(), "handler panicked"))
+	}
+	assertNotHandled := func(err error) {
+		t.Helper()
+		// When HTTP/2 handlers panic, net/http sends an RST_STREAM frame with code
+		// INTERNAL_ERROR. We should be mapping this back to CodeInternal.
+		assert.Equal(t, connect.CodeOf(err), connect.CodeInternal)
+		assert.False(t, strings.Contains(err.Error(), "handler panicked"))
+	}
+	drainStream := func(stream *connect.ServerStreamForClient[pingv1.CountUpResponse]) error {
+		t.Helper()
+		defer stream.Close()
+		assert.True(t, stream.Receive())  // expect one response msg
+		assert.False(t, stream.Receive()) // expect panic before second response msg
+		return stream.Err()
+	}
+	pinger := &panicPingServer{}
+	mux := http.NewServeMux()
+	mux.Handle(pingv1connect.NewPingServiceHandler(pinger, connect.WithRecover(handle)))
+	server := memhttptest.NewServer(t, mux)
+	for _, option := range []connect.ClientOption{
+		connect.WithClientOptions(),
+		connect.WithGRPC(),
+		connect.WithGRPCWeb(),
+	} {
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), option)
+		pinger.panicWith = 42
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assertHandled(err)
+		stream, err := client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{}))
+		assert.Nil(t, err)
+		assertHandled(drainStream(stream))
+
+		pinger.panicWith = http.ErrAbortHandler
+		_, err = client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+		assertNotHandled(err)
+		stream, err = client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{}))
+		assert.Nil(t, err)
+		assertNotHandled(drainStream(stream))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, recovered, []any{42, 42, 42, 42, 42, 42})
+	for _, stack := range stacks {
+		assert.True(t, strings.Contains(stack, "panicPingServer"))
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reflection
+
+import (
+	"net/http"
+	"testing"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	reflectionv1 "github.com/agentio/scalpel/internal/gen/connectext/grpc/reflection/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestReflection(t *testing.T) {
+	t.Parallel()
+	t.Run("static", func(t *testing.T) {
+		t.Parallel()
+		testReflector(t, NewStaticReflector(pingv1connect.PingServiceName, ReflectV1ServiceName), serviceURLPathV1, true)
+	})
+	t.Run("v1alpha", func(t *testing.T) {
+		t.Parallel()
+		testReflector(t, NewStaticReflector(pingv1connect.PingServiceName, ReflectV1ServiceName), serviceURLPathV1Alpha, true)
+	})
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+		reflector := NewReflector(
+			NamerFunc(func() []string {
+				return []string{pingv1connect.PingServiceName, ReflectV1ServiceName}
+			}),
+			WithExtensionResolver(protoregistry.GlobalTypes),
+			WithDescriptorResolver(protoregistry.GlobalFiles),
+		)
+		// The global registry only has the connectext-prefixed reflection
+		// descriptors, so the canonical service name can't be resolved.
+		testReflector(t, reflector, serviceURLPathV1, false)
+	})
+}
+
+func testReflector(t *testing.T, reflector *Reflector, servicePath string, canonical bool) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle(NewHandlerV1(reflector))
+	mux.Handle(NewHandlerV1Alpha(reflector))
+	server := memhttptest.NewServer(t, mux)
+	client := connect.NewClient[reflectionv1.ServerReflectionRequest, reflectionv1.ServerReflectionResponse](
+		server.Client(),
+		server.URL()+servicePath+methodName,
+	)
+	stream := client.CallBidiStream(t.Context())
+	t.Cleanup(func() {
+		assert.Nil(t, stream.CloseRequest())
+		assert.Nil(t, stream.CloseResponse())
+	})
+	call := func(request *reflectionv1.ServerReflectionRequest) *reflectionv1.ServerReflectionResponse {
+		t.Helper()
+		request.Host = "some-host"
+		assert.Nil(t, stream.Send(request))
+		response, err := stream.Receive()
+		assert.Nil(t, err)
+		assert.Equal(t, response.GetValidHost(), "some-host")
+		return response
+	}
+	fileNames := func(response *reflectionv1.ServerReflectionResponse) []string {
+		t.Helper()
+		assert.Nil(t, response.GetErrorResponse())
+		var names []string
+		for _, data := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			var file descriptorpb.FileDescriptorProto
+			assert.Nil(t, proto.Unmarshal(data, &file))
+			names = append(names, file.GetName())
+		}
+		return names
+	}
+	assertNotFound := func(response *reflectionv1.ServerReflectionResponse) {
+		t.Helper()
+		assert.Equal(t, response.GetErrorResponse().GetErrorCode(), int32(connect.CodeNotFound))
+		assert.NotZero(t, response.GetErrorResponse().GetErrorMessage())
+	}
+
+	response := call(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	})
+	var services []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Equal(t, services, []string{pingv1connect.PingServiceName, ReflectV1ServiceName})
+
+	pingFile := pingv1.File_connect_ping_v1_ping_proto.Path()
+	response = call(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: pingFile},
+	})
+	assert.Equal(t, fileNames(response)[0], pingFile)
+	response = call(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: "missing.proto"},
+	})
+	assertNotFound(response)
+
+	// Files are only sent once per stream.
+	response = call(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: pingv1connect.PingServiceName,
+		},
+	})
+	assert.Equal(t, fileNames(response), []string{pingFile})
+	for symbol, file := range map[string]string{
+		ReflectV1ServiceName:    "grpc/reflection/v1/reflection.proto",
+		"grpc.health.v1.Health": "grpc/health/v1/health.proto",
+	} {
+		response = call(&reflectionv1.ServerReflectionRequest{
+			MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{
+				FileContainingSymbol: symbol,
+			},
+		})
+		if !canonical {
+			assertNotFound(response)
+			continue
+		}
+		// Served from the embedded descriptors, using canonical names.
+		assert.Equal(t, fileNames(response), []string{file})
+	}
+	response = call(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "connect.ping.v1.Missing",
+		},
+	})
+	assertNotFound(response)
+
+	response = call(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingExtension{
+			FileContainingExtension: &reflectionv1.ExtensionRequest{
+				ContainingType:  "connect.ping.v1.PingRequest",
+				ExtensionNumber: 42,
+			},
+		},
+	})
+	assertNotFound(response)
+	response = call(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_AllExtensionNumbersOfType{
+			AllExtensionNumbersOfType: "connect.ping.v1.PingRequest",
+		},
+	})
+	assert.Equal(t, response.GetAllExtensionNumbersResponse().GetBaseTypeName(), "connect.ping.v1.PingRequest")
+	assert.Equal(t, len(response.GetAllExtensionNumbersResponse().GetExtensionNumber()), 0)
+}
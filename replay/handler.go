@@ -0,0 +1,280 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/envelope"
+)
+
+// A Handler is an [http.Handler] that replays recorded exchanges. It's not
+// tied to any procedure or service, so it's typically mounted at the root of
+// a test server.
+//
+// By default, matching is strict: a request is served by the first unused
+// exchange with the same procedure, HTTP method, and request bytes, and each
+// exchange is served at most once. Requests that don't match any exchange
+// fail with CodeNotFound. For looser matching, use [WithLenientMatching].
+//
+// Response frames are sent as soon as the request frames that preceded them
+// in the recording have arrived, so streams interleave as they did when they
+// were recorded.
+type Handler struct {
+	exchanges   []*Exchange
+	lenient     bool
+	errorWriter *scalpel.ErrorWriter
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// NewHandler constructs a Handler that replays the exchanges.
+func NewHandler(exchanges []*Exchange, options ...Option) *Handler {
+	handler := &Handler{
+		exchanges:   exchanges,
+		errorWriter: scalpel.NewErrorWriter(),
+		used:        make([]bool, len(exchanges)),
+	}
+	for _, option := range options {
+		option.apply(handler)
+	}
+	return handler
+}
+
+// Unused returns the exchanges that haven't been replayed, which is useful
+// to check that a test made every call it was expected to.
+func (h *Handler) Unused() []*Exchange {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var unused []*Exchange
+	for i, exchange := range h.exchanges {
+		if !h.used[i] {
+			unused = append(unused, exchange)
+		}
+	}
+	return unused
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *Handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	replay := &replayer{
+		handler:        h,
+		request:        request,
+		responseWriter: responseWriter,
+	}
+	if envelope.IsEnveloped(request.Header.Get("Content-Type")) {
+		replay.frames = scalpel.NewFrameReader(request.Body, 0)
+		// Streams are replayed in both directions at once.
+		_ = http.NewResponseController(responseWriter).EnableFullDuplex()
+	} else {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			_ = h.errorWriter.Write(responseWriter, request, err)
+			return
+		}
+		replay.body, replay.eof = body, true
+	}
+	if err := replay.run(); err != nil {
+		if replay.exchange != nil {
+			// The response has started, so the only way to fail it is to
+			// abort it.
+			panic(http.ErrAbortHandler) //nolint:forbidigo
+		}
+		_ = h.errorWriter.Write(responseWriter, request, err)
+	}
+}
+
+// An Option configures a [Handler].
+type Option interface {
+	apply(*Handler)
+}
+
+// WithLenientMatching makes the Handler match requests by procedure and
+// HTTP method alone. Exchanges with matching request bytes are still
+// preferred, but any exchange for the procedure is used if none match, and
+// exchanges may be replayed any number of times. This suits tests whose
+// requests vary from run to run: for example, if they contain timestamps.
+func WithLenientMatching() Option {
+	return &lenientMatchingOption{}
+}
+
+type lenientMatchingOption struct{}
+
+func (o *lenientMatchingOption) apply(handler *Handler) {
+	handler.lenient = true
+}
+
+// find returns the exchange to replay for a request, given the request frames
+// or body received so far. If commit is true, the exchange is marked as used.
+func (h *Handler) find(request *http.Request, frames []scalpel.Frame, body []byte, eof bool, commit bool) *Exchange {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fallback := -1
+	for i, exchange := range h.exchanges {
+		if exchange.Procedure != request.URL.Path || exchange.Method != request.Method {
+			continue
+		}
+		if h.lenient {
+			if fallback < 0 {
+				fallback = i
+			}
+		} else if h.used[i] {
+			continue
+		}
+		if exchange.Query == request.URL.RawQuery && requestMatches(exchange, frames, body, eof) {
+			if commit {
+				h.used[i] = true
+			}
+			return exchange
+		}
+	}
+	if fallback < 0 {
+		return nil
+	}
+	if commit {
+		h.used[fallback] = true
+	}
+	return h.exchanges[fallback]
+}
+
+// requestMatches reports whether the request received so far is consistent
+// with the exchange: that is, whether it's a prefix of the recorded request,
+// or all of it once the request has ended.
+func requestMatches(exchange *Exchange, frames []scalpel.Frame, body []byte, eof bool) bool {
+	if !bytes.Equal(exchange.RequestBody, body) {
+		return false
+	}
+	if len(frames) > len(exchange.Request) || eof && len(frames) != len(exchange.Request) {
+		return false
+	}
+	for i, frame := range frames {
+		if frame.Flags != exchange.Request[i].Flags || !bytes.Equal(frame.Data, exchange.Request[i].Data) {
+			return false
+		}
+	}
+	return true
+}
+
+// replayer replays a single exchange.
+type replayer struct {
+	handler        *Handler
+	request        *http.Request
+	responseWriter http.ResponseWriter
+
+	frames   *scalpel.FrameReader // nil for unenveloped requests
+	received []scalpel.Frame
+	body     []byte
+	eof      bool
+
+	exchange *Exchange // set once the response has started
+	sent     int       // response frames sent
+}
+
+func (r *replayer) run() error {
+	for {
+		exchange := r.exchange
+		if exchange == nil {
+			exchange = r.handler.find(r.request, r.received, r.body, r.eof, false)
+			if exchange == nil {
+				return scalpel.NewError(scalpel.CodeNotFound, fmt.Errorf("no recorded exchange matches %s %s", r.request.Method, r.request.URL.Path))
+			}
+		} else if !r.handler.lenient && !requestMatches(exchange, r.received, r.body, r.eof) {
+			return errors.New("request diverged from recording")
+		}
+		if r.sent < len(exchange.Response) && (r.eof || len(r.received) >= requestFramesBefore(exchange, r.sent)) {
+			if err := r.start(); err != nil {
+				return err
+			}
+			if err := scalpel.NewFrameWriter(r.responseWriter).Write(scalpel.Frame{
+				Flags: r.exchange.Response[r.sent].Flags,
+				Data:  r.exchange.Response[r.sent].Data,
+			}); err != nil {
+				return err
+			}
+			_ = http.NewResponseController(r.responseWriter).Flush()
+			r.sent++
+			continue
+		}
+		if r.eof {
+			return r.finish()
+		}
+		frame, err := r.frames.Read()
+		if errors.Is(err, io.EOF) {
+			r.eof = true
+		} else if err != nil {
+			return err
+		} else {
+			r.received = append(r.received, frame)
+		}
+	}
+}
+
+// start commits to an exchange and sends its response headers.
+func (r *replayer) start() error {
+	if r.exchange != nil {
+		return nil
+	}
+	r.exchange = r.handler.find(r.request, r.received, r.body, r.eof, true)
+	if r.exchange == nil {
+		// Another request used the exchange first.
+		return scalpel.NewError(scalpel.CodeNotFound, fmt.Errorf("no recorded exchange matches %s %s", r.request.Method, r.request.URL.Path))
+	}
+	if r.exchange.Error != "" {
+		return errors.New(r.exchange.Error)
+	}
+	header := r.responseWriter.Header()
+	for key, values := range r.exchange.ResponseHeader {
+		header[key] = values
+	}
+	header.Del("Content-Length")
+	r.responseWriter.WriteHeader(r.exchange.Status)
+	return nil
+}
+
+func (r *replayer) finish() error {
+	if err := r.start(); err != nil {
+		return err
+	}
+	if len(r.exchange.ResponseBody) > 0 {
+		if _, err := r.responseWriter.Write(r.exchange.ResponseBody); err != nil {
+			return err
+		}
+	}
+	header := r.responseWriter.Header()
+	for key, values := range r.exchange.ResponseTrailer {
+		header[http.TrailerPrefix+key] = values
+	}
+	return nil
+}
+
+// requestFramesBefore returns the number of request frames that were sent
+// before the response frame was received.
+func requestFramesBefore(exchange *Exchange, responseFrame int) int {
+	offset := exchange.Response[responseFrame].Offset
+	count := 0
+	for _, frame := range exchange.Request {
+		if frame.Offset <= offset {
+			count++
+		}
+	}
+	return count
+}
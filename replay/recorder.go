@@ -0,0 +1,205 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/envelope"
+)
+
+// envelopePrefixLength is the size of each frame's flags and length.
+const envelopePrefixLength = 5
+
+// A Recorder is a [scalpel.HTTPClient] that records every RPC it sends. Each
+// RPC is written as a line of JSON once its response ends, whether because
+// the body was read to the end or because it was closed.
+type Recorder struct {
+	client scalpel.HTTPClient
+
+	mu      sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+// NewRecorder constructs a Recorder that sends requests with client and
+// writes the exchanges to writer. Writes are serialized, so one writer can be
+// shared by concurrent RPCs.
+func NewRecorder(client scalpel.HTTPClient, writer io.Writer) *Recorder {
+	return &Recorder{
+		client:  client,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// Do implements [scalpel.HTTPClient].
+func (r *Recorder) Do(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	exchange := &Exchange{
+		Version:       Version,
+		Procedure:     request.URL.Path,
+		Method:        request.Method,
+		Query:         request.URL.RawQuery,
+		RequestHeader: request.Header.Clone(),
+		Start:         start,
+	}
+	requestBody := &recordingReader{
+		start:     start,
+		enveloped: envelope.IsEnveloped(request.Header.Get("Content-Type")),
+	}
+	if request.Body != nil && request.Body != http.NoBody {
+		request = request.Clone(request.Context())
+		requestBody.reader = request.Body
+		request.Body = &recordingRequestBody{recordingReader: requestBody, closer: request.Body}
+		// Requests can't be replayed without losing the recording.
+		request.GetBody = nil
+	}
+	response, err := r.client.Do(request)
+	if err != nil {
+		exchange.Error = err.Error()
+		exchange.Duration = time.Since(start)
+		exchange.Request, exchange.RequestBody = requestBody.recorded()
+		r.write(exchange)
+		return nil, err
+	}
+	exchange.Status = response.StatusCode
+	exchange.ResponseHeader = response.Header.Clone()
+	responseBody := &recordingResponseBody{
+		recordingReader: &recordingReader{
+			reader:    response.Body,
+			start:     start,
+			enveloped: envelope.IsEnveloped(response.Header.Get("Content-Type")),
+		},
+		closer: response.Body,
+	}
+	responseBody.finish = func() {
+		exchange.Duration = time.Since(start)
+		exchange.Request, exchange.RequestBody = requestBody.recorded()
+		exchange.Response, exchange.ResponseBody = responseBody.recorded()
+		exchange.ResponseTrailer = response.Trailer.Clone()
+		r.write(exchange)
+	}
+	response.Body = responseBody
+	return response, nil
+}
+
+// Err returns the first error writing an exchange, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) write(exchange *Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.encoder.Encode(exchange); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// recordingReader keeps a copy of everything read from a body, and notes when
+// each frame is complete.
+type recordingReader struct {
+	reader    io.Reader
+	start     time.Time
+	enveloped bool
+
+	mu      sync.Mutex
+	data    bytes.Buffer
+	offsets []time.Duration
+	next    int // start of the next incomplete frame
+}
+
+func (r *recordingReader) Read(data []byte) (int, error) {
+	n, err := r.reader.Read(data)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data.Write(data[:n])
+	for r.enveloped {
+		rest := r.data.Bytes()[r.next:]
+		if len(rest) < envelopePrefixLength {
+			break
+		}
+		size := envelopePrefixLength + int(binary.BigEndian.Uint32(rest[1:envelopePrefixLength]))
+		if len(rest) < size {
+			break
+		}
+		r.next += size
+		r.offsets = append(r.offsets, time.Since(r.start))
+	}
+	return n, err
+}
+
+// recorded returns the frames or, for unenveloped bodies, the raw body read so
+// far.
+func (r *recordingReader) recorded() ([]Frame, []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enveloped {
+		return nil, bytes.Clone(r.data.Bytes())
+	}
+	reader := scalpel.NewFrameReader(bytes.NewReader(bytes.Clone(r.data.Bytes()[:r.next])), 0)
+	frames := make([]Frame, 0, len(r.offsets))
+	for _, offset := range r.offsets {
+		frame, err := reader.Read()
+		if err != nil {
+			break
+		}
+		frames = append(frames, toFrame(frame, offset))
+	}
+	return frames, nil
+}
+
+type recordingRequestBody struct {
+	*recordingReader
+
+	closer io.Closer
+}
+
+func (b *recordingRequestBody) Close() error {
+	return b.closer.Close()
+}
+
+type recordingResponseBody struct {
+	*recordingReader
+
+	closer io.Closer
+	once   sync.Once
+	finish func()
+}
+
+func (b *recordingResponseBody) Read(data []byte) (int, error) {
+	n, err := b.recordingReader.Read(data)
+	if errors.Is(err, io.EOF) {
+		// Trailers are available once the body has been read.
+		b.once.Do(b.finish)
+	}
+	return n, err
+}
+
+func (b *recordingResponseBody) Close() error {
+	err := b.closer.Close()
+	b.once.Do(b.finish)
+	return err
+}
@@ -0,0 +1,126 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay records RPCs and replays them, so tests can run against real
+// traffic without the real servers.
+//
+// A [Recorder] wraps a client's HTTPClient and writes each RPC to a file as an
+// [Exchange]. Later, a [Handler] serves the recorded responses to clients that
+// send the same requests. Recordings work with the gRPC, gRPC-Web, and Connect
+// protocols and with all four types of RPC. Messages are recorded frame by
+// frame, exactly as they were sent, so no codec or generated code is needed.
+// Bodies that aren't enveloped, like Connect unary and gRPC-Web text bodies,
+// are recorded whole.
+//
+// Recordings are JSON Lines files: each line is an Exchange, with message data
+// in base64. Every line has a version number, so files can be read after the
+// format changes.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentio/scalpel"
+)
+
+// Version is the version of the recording format written by this package.
+const Version = 1
+
+// maxLineBytes limits the size of each line of a recording.
+const maxLineBytes = 64 << 20
+
+// An Exchange is a single recorded RPC.
+type Exchange struct {
+	// Version is the version of the recording format.
+	Version int `json:"version"`
+	// Procedure is the request's URL path, like "/acme.foo.v1.FooService/Bar".
+	Procedure string `json:"procedure"`
+	// Method is the request's HTTP method.
+	Method string `json:"method"`
+	// Query is the request's raw query string. Connect GET requests carry
+	// their message in the query.
+	Query string `json:"query,omitempty"`
+	// RequestHeader holds the request headers.
+	RequestHeader http.Header `json:"requestHeader"`
+	// Request holds the request's frames for enveloped protocols: gRPC,
+	// gRPC-Web, and Connect streaming.
+	Request []Frame `json:"request,omitempty"`
+	// RequestBody holds the request body for Connect unary RPCs, which don't
+	// use envelopes, and for gRPC-Web text, whose envelopes are base64-encoded.
+	RequestBody []byte `json:"requestBody,omitempty"`
+	// Status is the response's HTTP status code.
+	Status int `json:"status"`
+	// ResponseHeader holds the response headers.
+	ResponseHeader http.Header `json:"responseHeader"`
+	// Response holds the response's frames for enveloped protocols.
+	Response []Frame `json:"response,omitempty"`
+	// ResponseBody holds the response body for Connect unary RPCs and gRPC-Web
+	// text.
+	ResponseBody []byte `json:"responseBody,omitempty"`
+	// ResponseTrailer holds the HTTP trailers. gRPC-Web and Connect streaming
+	// send their trailers in the last frame instead.
+	ResponseTrailer http.Header `json:"responseTrailer,omitempty"`
+	// Start is when the request was sent.
+	Start time.Time `json:"start"`
+	// Duration is the time from Start until the response ended.
+	Duration time.Duration `json:"duration"`
+	// Error is set if the RPC failed without a response: for example, if the
+	// server couldn't be reached.
+	Error string `json:"error,omitempty"`
+}
+
+// A Frame is a single enveloped message.
+type Frame struct {
+	Flags uint8  `json:"flags"`
+	Data  []byte `json:"data"`
+	// Offset is the time from the start of the RPC until the frame was
+	// complete: sent, for requests, or received, for responses. It determines
+	// how replayed request and response frames interleave.
+	Offset time.Duration `json:"offset"`
+}
+
+// ReadExchanges reads a recording. It returns an error if any exchange has an
+// unsupported version.
+func ReadExchanges(reader io.Reader) ([]*Exchange, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxLineBytes)
+	var exchanges []*Exchange
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		exchange := &Exchange{}
+		if err := json.Unmarshal(scanner.Bytes(), exchange); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if exchange.Version != Version {
+			return nil, fmt.Errorf("line %d: unsupported version %d", line, exchange.Version)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return exchanges, nil
+}
+
+func toFrame(frame scalpel.Frame, offset time.Duration) Frame {
+	return Frame{Flags: frame.Flags, Data: frame.Data, Offset: offset}
+}
@@ -0,0 +1,296 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"github.com/agentio/scalpel/replay"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+	mux := connect.NewMux(nil)
+	mux.HandleService(pingv1connect.NewPingServiceHandler(newPingService()))
+	upstream := memhttptest.NewServer(t, mux)
+	protocols := map[string][]connect.ClientOption{
+		"connect":  nil,
+		"grpc":     {connect.WithGRPC()},
+		"grpcweb":  {connect.WithGRPCWeb()},
+		"get":      {connect.WithHTTPGet()},
+		"compress": {connect.WithSendGzip()},
+	}
+	for name, options := range protocols {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var recording bytes.Buffer
+			recorder := replay.NewRecorder(upstream.Client(), &recording)
+			callAll(t, pingv1connect.NewPingServiceClient(recorder, upstream.URL(), options...))
+			assert.Nil(t, recorder.Err())
+
+			exchanges, err := replay.ReadExchanges(&recording)
+			assert.Nil(t, err)
+			assert.Equal(t, len(exchanges), 5)
+			handler := replay.NewHandler(exchanges)
+			server := memhttptest.NewServer(t, handler)
+			client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), options...)
+			callAll(t, client)
+			assert.Equal(t, len(handler.Unused()), 0)
+
+			// Each exchange is replayed once.
+			_, err = client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: 42, Text: "hello"}))
+			assert.Equal(t, connect.CodeOf(err), connect.CodeNotFound)
+		})
+	}
+}
+
+func TestReplayMatching(t *testing.T) {
+	t.Parallel()
+	mux := connect.NewMux(nil)
+	mux.HandleService(pingv1connect.NewPingServiceHandler(newPingService()))
+	upstream := memhttptest.NewServer(t, mux)
+	var recording bytes.Buffer
+	recorder := replay.NewRecorder(upstream.Client(), &recording)
+	client := pingv1connect.NewPingServiceClient(recorder, upstream.URL(), connect.WithGRPC())
+	for _, text := range []string{"first", "second"} {
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Text: text}))
+		assert.Nil(t, err)
+	}
+	exchanges, err := replay.ReadExchanges(&recording)
+	assert.Nil(t, err)
+	assert.Equal(t, len(exchanges), 2)
+	assert.Equal(t, exchanges[0].Procedure, pingv1connect.PingServicePingProcedure)
+	assert.Equal(t, len(exchanges[0].Request), 1)
+	assert.Equal(t, len(exchanges[0].Response), 1)
+	assert.Equal(t, exchanges[0].ResponseTrailer.Get("Grpc-Status"), "0")
+
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+		server := memhttptest.NewServer(t, replay.NewHandler(exchanges))
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC())
+		// Requests match by content, not by order.
+		response, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Text: "second"}))
+		assert.Nil(t, err)
+		assert.Equal(t, response.Msg.GetText(), "second")
+		_, err = client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Text: "third"}))
+		assert.Equal(t, connect.CodeOf(err), connect.CodeNotFound)
+		_, err = client.Sum(t.Context()).CloseAndReceive()
+		assert.Equal(t, connect.CodeOf(err), connect.CodeNotFound)
+	})
+	t.Run("lenient", func(t *testing.T) {
+		t.Parallel()
+		handler := replay.NewHandler(exchanges, replay.WithLenientMatching())
+		server := memhttptest.NewServer(t, handler)
+		client := pingv1connect.NewPingServiceClient(server.Client(), server.URL(), connect.WithGRPC())
+		for _, want := range []struct{ text, reply string }{
+			{"second", "second"},
+			{"second", "second"},
+			{"third", "first"}, // unmatched requests fall back to the first exchange
+		} {
+			response, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Text: want.text}))
+			assert.Nil(t, err)
+			assert.Equal(t, response.Msg.GetText(), want.reply)
+		}
+		assert.Equal(t, len(handler.Unused()), 0)
+		_, err := client.Sum(t.Context()).CloseAndReceive()
+		assert.Equal(t, connect.CodeOf(err), connect.CodeNotFound)
+	})
+}
+
+func TestReplayGRPCWebText(t *testing.T) {
+	t.Parallel()
+	mux := connect.NewMux(nil)
+	mux.HandleService(pingv1connect.NewPingServiceHandler(newPingService()))
+	upstream := memhttptest.NewServer(t, mux)
+	var recording bytes.Buffer
+	recorder := replay.NewRecorder(upstream.Client(), &recording)
+	// The client doesn't send gRPC-Web text, so send the requests by hand.
+	recorded := pingText(t, recorder, upstream.URL())
+	assert.Nil(t, recorder.Err())
+
+	exchanges, err := replay.ReadExchanges(&recording)
+	assert.Nil(t, err)
+	assert.Equal(t, len(exchanges), 1)
+	// Text bodies are base64-encoded, so they're recorded whole.
+	assert.Equal(t, len(exchanges[0].Request), 0)
+	assert.Equal(t, len(exchanges[0].Response), 0)
+	assert.True(t, len(exchanges[0].RequestBody) > 0)
+	assert.Equal(t, exchanges[0].ResponseBody, recorded)
+
+	handler := replay.NewHandler(exchanges)
+	server := memhttptest.NewServer(t, handler)
+	assert.Equal(t, pingText(t, server.Client(), server.URL()), recorded)
+	assert.Equal(t, len(handler.Unused()), 0)
+}
+
+func TestReadExchanges(t *testing.T) {
+	t.Parallel()
+	exchanges, err := replay.ReadExchanges(strings.NewReader(
+		`{"version":1,"procedure":"/a.v1.AService/Method","method":"POST","request":[{"flags":0,"data":"aGk=","offset":5}]}` + "\n\n",
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, len(exchanges), 1)
+	assert.Equal(t, exchanges[0].Request[0].Data, []byte("hi"))
+	_, err = replay.ReadExchanges(strings.NewReader(`{"version":2}`))
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "unsupported version 2"))
+	_, err = replay.ReadExchanges(strings.NewReader(`{"version":`))
+	assert.NotNil(t, err)
+}
+
+// callAll makes the same RPCs on every run, so they can be recorded and
+// replayed.
+func callAll(t *testing.T, client pingv1connect.PingServiceClient) {
+	t.Helper()
+	request := connect.NewRequest(&pingv1.PingRequest{Number: 42, Text: "hello"})
+	request.Header().Set("Echo", "header")
+	response, err := client.Ping(t.Context(), request)
+	assert.Nil(t, err)
+	assert.Equal(t, response.Msg.GetNumber(), 42)
+	assert.Equal(t, response.Header().Get("Echo"), "header")
+	assert.Equal(t, response.Trailer().Get("Echo"), "trailer")
+
+	_, err = client.Fail(t.Context(), connect.NewRequest(&pingv1.FailRequest{Code: int32(connect.CodeAborted)}))
+	assert.Equal(t, connect.CodeOf(err), connect.CodeAborted)
+	var connectErr *connect.Error
+	assert.True(t, errors.As(err, &connectErr))
+	assert.Equal(t, connectErr.Message(), "fail")
+
+	sum := client.Sum(t.Context())
+	for i := int64(1); i <= 4; i++ {
+		assert.Nil(t, sum.Send(&pingv1.SumRequest{Number: i}))
+	}
+	sumResponse, err := sum.CloseAndReceive()
+	assert.Nil(t, err)
+	assert.Equal(t, sumResponse.Msg.GetSum(), 10)
+
+	countUp, err := client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{Number: 3}))
+	assert.Nil(t, err)
+	var got []int64
+	for countUp.Receive() {
+		got = append(got, countUp.Msg().GetNumber())
+	}
+	assert.Nil(t, countUp.Err())
+	assert.Equal(t, got, []int64{1, 2, 3})
+	assert.Equal(t, countUp.ResponseTrailer().Get("Echo"), "trailer")
+	assert.Nil(t, countUp.Close())
+
+	// Each response arrives before the next request is sent, so replayed
+	// streams must interleave like the recorded one.
+	cumSum := client.CumSum(t.Context())
+	for i, want := range []int64{1, 3, 6} {
+		assert.Nil(t, cumSum.Send(&pingv1.CumSumRequest{Number: int64(i + 1)}))
+		response, err := cumSum.Receive()
+		assert.Nil(t, err)
+		assert.Equal(t, response.GetSum(), want)
+	}
+	assert.Nil(t, cumSum.CloseRequest())
+	_, err = cumSum.Receive()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Nil(t, cumSum.CloseResponse())
+}
+
+// pingText sends a gRPC-Web text Ping request and returns the response body,
+// which is still base64-encoded.
+func pingText(t *testing.T, client connect.HTTPClient, baseURL string) []byte {
+	t.Helper()
+	payload, err := proto.Marshal(&pingv1.PingRequest{Number: 42, Text: "hello"})
+	assert.Nil(t, err)
+	envelope := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(envelope[1:5], uint32(len(payload)))
+	envelope = append(envelope, payload...)
+	request, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		baseURL+pingv1connect.PingServicePingProcedure,
+		strings.NewReader(base64.StdEncoding.EncodeToString(envelope)),
+	)
+	assert.Nil(t, err)
+	request.Header.Set("Content-Type", "application/grpc-web-text+proto")
+	response, err := client.Do(request)
+	assert.Nil(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, response.StatusCode, http.StatusOK)
+	assert.Equal(t, response.Header.Get("Content-Type"), "application/grpc-web-text+proto")
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.True(t, len(body) > 0)
+	return body
+}
+
+func newPingService() *pingv1connect.FakePingService {
+	return &pingv1connect.FakePingService{
+		PingFunc: func(_ context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+			response := connect.NewResponse(&pingv1.PingResponse{
+				Number: request.Msg.GetNumber(),
+				Text:   request.Msg.GetText(),
+			})
+			response.Header().Set("Echo", request.Header().Get("Echo"))
+			response.Trailer().Set("Echo", "trailer")
+			return response, nil
+		},
+		FailFunc: func(_ context.Context, request *connect.Request[pingv1.FailRequest]) (*connect.Response[pingv1.FailResponse], error) {
+			return nil, connect.NewError(connect.Code(request.Msg.GetCode()), errors.New("fail"))
+		},
+		SumFunc: func(_ context.Context, stream *connect.ClientStream[pingv1.SumRequest]) (*connect.Response[pingv1.SumResponse], error) {
+			var sum int64
+			for stream.Receive() {
+				sum += stream.Msg().GetNumber()
+			}
+			if err := stream.Err(); err != nil {
+				return nil, err
+			}
+			return connect.NewResponse(&pingv1.SumResponse{Sum: sum}), nil
+		},
+		CountUpFunc: func(_ context.Context, request *connect.Request[pingv1.CountUpRequest], stream *connect.ServerStream[pingv1.CountUpResponse]) error {
+			for i := int64(1); i <= request.Msg.GetNumber(); i++ {
+				if err := stream.Send(&pingv1.CountUpResponse{Number: i}); err != nil {
+					return err
+				}
+			}
+			stream.ResponseTrailer().Set("Echo", "trailer")
+			return nil
+		},
+		CumSumFunc: func(_ context.Context, stream *connect.BidiStream[pingv1.CumSumRequest, pingv1.CumSumResponse]) error {
+			var sum int64
+			for {
+				request, err := stream.Receive()
+				if errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				sum += request.GetNumber()
+				if err := stream.Send(&pingv1.CumSumResponse{Sum: sum}); err != nil {
+					return err
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Resolver yields the set of server addresses for a load-balancing client.
+// Addresses are either host:port pairs, which use the scheme of the client's
+// URL, or URLs with a scheme and host, like "https://10.0.0.1:8443". The path
+// of the client's URL is used unchanged.
+//
+// Addresses is called for every request, so it must be cheap and safe to
+// call concurrently. Callers must not modify the returned slice.
+type Resolver interface {
+	Addresses() []string
+}
+
+// NewStaticResolver returns a [Resolver] for a fixed set of addresses.
+func NewStaticResolver(addresses ...string) Resolver {
+	return staticResolver(slices.Clone(addresses))
+}
+
+type staticResolver []string
+
+func (r staticResolver) Addresses() []string {
+	return r
+}
+
+// FileResolver is a [Resolver] that reads addresses from a file, one per
+// line, and reloads the file when it changes. Blank lines and lines starting
+// with "#" are ignored. If the file can't be read or parsed after it
+// changes, the resolver keeps the last good set of addresses.
+type FileResolver struct {
+	path string
+
+	mu        sync.RWMutex
+	addresses []string
+	modTime   time.Time
+	size      int64
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewFileResolver constructs a [FileResolver], checking the file for changes
+// at the supplied interval. The file must exist and be readable. Call Close
+// to stop watching the file.
+func NewFileResolver(path string, interval time.Duration) (*FileResolver, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("file resolver: interval must be positive, got %v", interval)
+	}
+	resolver := &FileResolver{
+		path: path,
+		stop: make(chan struct{}),
+	}
+	if err := resolver.reload(); err != nil {
+		return nil, err
+	}
+	go resolver.watch(interval)
+	return resolver, nil
+}
+
+// Addresses implements [Resolver].
+func (r *FileResolver) Addresses() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.addresses
+}
+
+// Close stops watching the file. The resolver continues to return the last
+// set of addresses.
+func (r *FileResolver) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	return nil
+}
+
+func (r *FileResolver) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			_ = r.reload()
+		}
+	}
+}
+
+// reload reads the file if it's changed since the last successful read.
+func (r *FileResolver) reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("file resolver: %w", err)
+	}
+	r.mu.RLock()
+	unchanged := info.ModTime().Equal(r.modTime) && info.Size() == r.size
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("file resolver: %w", err)
+	}
+	addresses, err := parseAddresses(data)
+	if err != nil {
+		return fmt.Errorf("file resolver: %s: %w", r.path, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addresses = addresses
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	return nil
+}
+
+func parseAddresses(data []byte) ([]string, error) {
+	var addresses []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		address := strings.TrimSpace(scanner.Text())
+		if address == "" || strings.HasPrefix(address, "#") {
+			continue
+		}
+		if strings.ContainsAny(address, " \t") {
+			return nil, fmt.Errorf("line %d: invalid address %q", line, address)
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, scanner.Err()
+}
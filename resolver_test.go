@@ -0,0 +1,49 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agentio/scalpel/internal/assert"
+)
+
+func TestFileResolver(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "addresses")
+	assert.Nil(t, os.WriteFile(path, []byte("# backends\nhttp://10.0.0.1:8080\n\n10.0.0.2:8080\n"), 0o600))
+	resolver, err := NewFileResolver(path, time.Millisecond)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = resolver.Close() })
+	assert.Equal(t, resolver.Addresses(), []string{"http://10.0.0.1:8080", "10.0.0.2:8080"})
+
+	assert.Nil(t, os.WriteFile(path, []byte("10.0.0.3:8080\n"), 0o600))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(resolver.Addresses()) != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, resolver.Addresses(), []string{"10.0.0.3:8080"})
+
+	// Invalid files are ignored.
+	assert.Nil(t, os.WriteFile(path, []byte("not an address\n"), 0o600))
+	assert.NotNil(t, resolver.reload())
+	assert.Equal(t, resolver.Addresses(), []string{"10.0.0.3:8080"})
+
+	_, err = NewFileResolver(filepath.Join(t.TempDir(), "missing"), time.Second)
+	assert.NotNil(t, err)
+}
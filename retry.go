@@ -0,0 +1,379 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	googlerpc "github.com/agentio/scalpel/internal/gen/connectext/google/rpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	grpcHeaderPreviousAttempts = "Grpc-Previous-Rpc-Attempts"
+	grpcHeaderRetryPushback    = "Grpc-Retry-Pushback-Ms"
+	retryInfoTypeName          = "google.rpc.RetryInfo"
+
+	// Like grpc-go, we never make more than five attempts, regardless of the
+	// configured policy.
+	retryMaxAttemptsLimit = 5
+	// defaultRetryBufferBytes is the default amount of a streaming request body
+	// that's buffered for replay.
+	defaultRetryBufferBytes = 256 * 1024 // 256 KiB
+)
+
+// A RetryPolicy configures automatic client retries. It's modeled on the
+// retryPolicy in gRPC's service config: see
+// https://github.com/grpc/proposal/blob/master/A6-client-retries.md.
+//
+// Clients only retry attempts that fail before the server has committed to a
+// response: transport errors, errors returned with the response headers (for
+// example, gRPC "trailers-only" responses), and unary Connect errors. Once the
+// client has received response headers for a successful stream, errors are
+// returned to the caller. Server and bidirectional streams may wait
+// indefinitely before their first message, so their errors are only retried if
+// they're in the response headers: gRPC servers that send an empty body and
+// trailers instead of a trailers-only response aren't retried. Requests on
+// client and bidirectional streams are buffered so they can be replayed; once
+// the buffer exceeds MaxBufferBytes, the call is no longer retried.
+//
+// Servers may ask clients to wait before retrying, either with gRPC's retry
+// pushback header or with a google.rpc.RetryInfo error detail. The server's
+// delay replaces the backoff; the pushback header takes precedence.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the original
+	// request. It must be greater than one; values greater than five are
+	// treated as five.
+	MaxAttempts int
+	// InitialBackoff and MaxBackoff bound the exponential backoff between
+	// attempts. The delay before retry n is chosen uniformly at random between
+	// zero and min(InitialBackoff*BackoffMultiplier^(n-1), MaxBackoff).
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BackoffMultiplier is the growth factor for the backoff. It must be
+	// greater than zero.
+	BackoffMultiplier float64
+	// RetryableCodes are the codes that trigger a retry. It must not be empty.
+	RetryableCodes []Code
+	// MaxBufferBytes limits the size of the streaming request body buffered
+	// for replay. If zero, it defaults to 256 KiB.
+	MaxBufferBytes int
+	// Throttle, if non-nil, limits retries when many attempts are failing. A
+	// single throttle is usually shared by all the clients for a server.
+	Throttle *RetryThrottle
+}
+
+func (p *RetryPolicy) validate() error {
+	if p.MaxAttempts < 2 {
+		return fmt.Errorf("retry policy: MaxAttempts must be greater than one, got %d", p.MaxAttempts)
+	}
+	if p.InitialBackoff <= 0 || p.MaxBackoff <= 0 {
+		return errors.New("retry policy: InitialBackoff and MaxBackoff must be positive")
+	}
+	if p.BackoffMultiplier <= 0 {
+		return fmt.Errorf("retry policy: BackoffMultiplier must be positive, got %v", p.BackoffMultiplier)
+	}
+	if len(p.RetryableCodes) == 0 {
+		return errors.New("retry policy: RetryableCodes must not be empty")
+	}
+	if p.MaxBufferBytes < 0 {
+		return fmt.Errorf("retry policy: MaxBufferBytes must not be negative, got %d", p.MaxBufferBytes)
+	}
+	return nil
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	return min(p.MaxAttempts, retryMaxAttemptsLimit)
+}
+
+func (p *RetryPolicy) maxBufferBytes() int {
+	if p.MaxBufferBytes == 0 {
+		return defaultRetryBufferBytes
+	}
+	return p.MaxBufferBytes
+}
+
+// backoff returns the delay before the given retry, counting from one.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	limit := float64(p.InitialBackoff) * math.Pow(p.BackoffMultiplier, float64(retry-1))
+	limit = min(limit, float64(p.MaxBackoff))
+	return time.Duration(rand.Float64() * limit) //nolint:gosec // jitter doesn't need a CSPRNG
+}
+
+// A RetryThrottle is a token bucket that disables retries when too many
+// attempts fail, following gRPC's retryThrottling configuration. The bucket
+// starts with maxTokens tokens. Every attempt that fails with a retryable code
+// removes one token, and every successful attempt adds tokenRatio tokens.
+// Clients only retry while more than half of the tokens remain.
+//
+// RetryThrottles are safe to use concurrently.
+type RetryThrottle struct {
+	mu         sync.Mutex
+	maxTokens  float64
+	tokenRatio float64
+	tokens     float64
+}
+
+// NewRetryThrottle constructs a RetryThrottle. Both arguments must be
+// positive.
+func NewRetryThrottle(maxTokens int, tokenRatio float64) *RetryThrottle {
+	return &RetryThrottle{
+		maxTokens:  float64(maxTokens),
+		tokenRatio: tokenRatio,
+		tokens:     float64(maxTokens),
+	}
+}
+
+// onFailure records a retryable failure and reports whether retries are
+// still allowed.
+func (t *RetryThrottle) onFailure() bool {
+	if t == nil {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens = max(t.tokens-1, 0)
+	return t.tokens > t.maxTokens/2
+}
+
+func (t *RetryThrottle) onSuccess() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens = min(t.tokens+t.tokenRatio, t.maxTokens)
+}
+
+// retryDelay decides whether a failed attempt should be retried. The attempt
+// argument counts from one. It returns the delay before the next attempt.
+func (p *RetryPolicy) retryDelay(ctx context.Context, err error, attempt int) (time.Duration, bool) {
+	if ctx.Err() != nil || !slices.Contains(p.RetryableCodes, CodeOf(err)) {
+		return 0, false
+	}
+	allowed := p.Throttle.onFailure()
+	if !allowed || attempt >= p.maxAttempts() {
+		return 0, false
+	}
+	if pushback, ok := retryPushback(err); ok {
+		return pushback, pushback >= 0
+	}
+	if delay, ok := retryInfoDelay(err); ok {
+		return delay, true
+	}
+	return p.backoff(attempt), true
+}
+
+// retryPushback extracts the server's requested delay from an error's
+// metadata. Negative or malformed values mean that the server doesn't want
+// the client to retry.
+func retryPushback(err error) (time.Duration, bool) {
+	connectErr, ok := asError(err)
+	if !ok {
+		return 0, false
+	}
+	values := connectErr.Meta().Values(grpcHeaderRetryPushback)
+	if len(values) == 0 {
+		return 0, false
+	}
+	millis, parseErr := strconv.ParseInt(values[0], 10 /* base */, 64 /* bitsize */)
+	if parseErr != nil || millis < 0 {
+		return -1, true
+	}
+	return time.Duration(millis) * time.Millisecond, true
+}
+
+// retryInfoDelay extracts the delay from a google.rpc.RetryInfo error detail.
+func retryInfoDelay(err error) (time.Duration, bool) {
+	connectErr, ok := asError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, detail := range connectErr.Details() {
+		if detail.Type() != retryInfoTypeName {
+			continue
+		}
+		var info googlerpc.RetryInfo
+		if err := proto.Unmarshal(detail.pbAny.GetValue(), &info); err != nil {
+			return 0, false
+		}
+		delay := info.GetRetryDelay()
+		if delay == nil || !delay.IsValid() {
+			return 0, false
+		}
+		return max(delay.AsDuration(), 0), true
+	}
+	return 0, false
+}
+
+// attemptsFromHeader returns the attempt number of a request, using the
+// Grpc-Previous-Rpc-Attempts header set by retrying clients.
+func attemptsFromHeader(header http.Header) int {
+	previous, err := strconv.Atoi(getHeaderCanonical(header, grpcHeaderPreviousAttempts))
+	if err != nil || previous < 0 {
+		return 1
+	}
+	return previous + 1
+}
+
+// replayBuffer records a streaming request body so that later attempts can
+// replay it. Each attempt reads the body through its own replayReader; only
+// the most recent reader is live. Once the body outgrows the limit or the
+// response is committed, the buffer stops recording and discards data as soon
+// as the live reader has consumed it.
+type replayBuffer struct {
+	source io.Reader
+	limit  int
+
+	mu         sync.Mutex
+	cond       sync.Cond
+	buf        []byte
+	start      int64 // offset of buf[0] in the body
+	replayable bool
+	reading    bool  // a reader is blocked on source
+	err        error // sticky error from source
+	live       *replayReader
+}
+
+func newReplayBuffer(source io.Reader, limit int) *replayBuffer {
+	buffer := &replayBuffer{
+		source:     source,
+		limit:      limit,
+		replayable: true,
+	}
+	buffer.cond.L = &buffer.mu
+	return buffer
+}
+
+// NewReader returns a reader for the next attempt. Readers for earlier
+// attempts are closed.
+func (b *replayBuffer) NewReader() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.replayable {
+		return nil, errors.New("request body is no longer replayable")
+	}
+	if b.live != nil {
+		b.live.closed = true
+	}
+	b.live = &replayReader{buffer: b, offset: b.start}
+	b.cond.Broadcast()
+	return b.live, nil
+}
+
+// Commit stops recording the body.
+func (b *replayBuffer) Commit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.replayable = false
+	b.trim()
+}
+
+// trim discards data the live reader has consumed, once the body is no
+// longer replayable. Callers must hold the lock.
+func (b *replayBuffer) trim() {
+	if b.replayable || b.live == nil {
+		return
+	}
+	consumed := int(b.live.offset - b.start)
+	b.buf = b.buf[consumed:]
+	b.start = b.live.offset
+	if len(b.buf) == 0 {
+		b.buf = nil
+	}
+}
+
+func (b *replayBuffer) end() int64 {
+	return b.start + int64(len(b.buf))
+}
+
+type replayReader struct {
+	buffer *replayBuffer
+	offset int64
+	closed bool
+}
+
+func (r *replayReader) Read(data []byte) (int, error) {
+	b := r.buffer
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		if r.closed {
+			return 0, io.ErrClosedPipe
+		}
+		if r.offset < b.end() {
+			n := copy(data, b.buf[r.offset-b.start:])
+			r.offset += int64(n)
+			b.trim()
+			return n, nil
+		}
+		if b.err != nil {
+			return 0, b.err
+		}
+		if !b.reading {
+			break
+		}
+		b.cond.Wait()
+	}
+	// Read from the source without holding the lock, so that an attempt that's
+	// been abandoned mid-read doesn't block its replacement.
+	b.reading = true
+	b.mu.Unlock()
+	n, err := b.source.Read(data)
+	b.mu.Lock()
+	b.reading = false
+	b.cond.Broadcast()
+	if err != nil {
+		b.err = err
+	}
+	if r.closed {
+		// This attempt was abandoned while reading. Keep the data for the live
+		// reader, even if that exceeds the limit.
+		b.buf = append(b.buf, data[:n]...)
+		return 0, io.ErrClosedPipe
+	}
+	if b.replayable && len(b.buf)+n > b.limit {
+		b.replayable = false
+	}
+	if b.replayable {
+		b.buf = append(b.buf, data[:n]...)
+	} else {
+		// The reader has consumed everything buffered, so the buffer starts
+		// again after the data just read.
+		b.buf = nil
+		b.start = r.offset + int64(n)
+	}
+	r.offset += int64(n)
+	b.trim()
+	return n, err
+}
+
+func (r *replayReader) Close() error {
+	r.buffer.mu.Lock()
+	defer r.buffer.mu.Unlock()
+	r.closed = true
+	r.buffer.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentio/scalpel/internal/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestReplayBuffer(t *testing.T) {
+	t.Parallel()
+	t.Run("replay", func(t *testing.T) {
+		t.Parallel()
+		buffer := newReplayBuffer(strings.NewReader("hello, world"), 1024)
+		first, err := buffer.NewReader()
+		assert.Nil(t, err)
+		data := make([]byte, 5)
+		_, err = io.ReadFull(first, data)
+		assert.Nil(t, err)
+		assert.Equal(t, string(data), "hello")
+		second, err := buffer.NewReader()
+		assert.Nil(t, err)
+		_, err = first.Read(data)
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		all, err := io.ReadAll(second)
+		assert.Nil(t, err)
+		assert.Equal(t, string(all), "hello, world")
+	})
+	t.Run("overflow", func(t *testing.T) {
+		t.Parallel()
+		buffer := newReplayBuffer(strings.NewReader("hello, world"), 4)
+		reader, err := buffer.NewReader()
+		assert.Nil(t, err)
+		all, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, string(all), "hello, world")
+		_, err = buffer.NewReader()
+		assert.NotNil(t, err)
+	})
+	t.Run("overflow_after_buffering", func(t *testing.T) {
+		t.Parallel()
+		buffer := newReplayBuffer(strings.NewReader("0123456789abcdef"), 10)
+		reader, err := buffer.NewReader()
+		assert.Nil(t, err)
+		data := make([]byte, 8)
+		for _, want := range []string{"01234567", "89abcdef"} {
+			n, err := reader.Read(data)
+			assert.Nil(t, err)
+			assert.Equal(t, string(data[:n]), want)
+		}
+		_, err = buffer.NewReader()
+		assert.NotNil(t, err)
+		n, err := reader.Read(data)
+		assert.Equal(t, n, 0)
+		assert.ErrorIs(t, err, io.EOF)
+	})
+	t.Run("commit", func(t *testing.T) {
+		t.Parallel()
+		buffer := newReplayBuffer(strings.NewReader("hello, world"), 1024)
+		reader, err := buffer.NewReader()
+		assert.Nil(t, err)
+		data := make([]byte, 5)
+		_, err = io.ReadFull(reader, data)
+		assert.Nil(t, err)
+		buffer.Commit()
+		assert.Equal(t, len(buffer.buf), 0)
+		_, err = buffer.NewReader()
+		assert.NotNil(t, err)
+		rest, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, string(rest), ", world")
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+	policy := RetryPolicy{
+		MaxAttempts:       10,
+		InitialBackoff:    10 * time.Millisecond,
+		MaxBackoff:        50 * time.Millisecond,
+		BackoffMultiplier: 2,
+		RetryableCodes:    []Code{CodeUnavailable},
+	}
+	assert.Nil(t, policy.validate())
+	assert.Equal(t, policy.maxAttempts(), retryMaxAttemptsLimit)
+	assert.Equal(t, policy.maxBufferBytes(), defaultRetryBufferBytes)
+	for retry := 1; retry < 10; retry++ {
+		limit := min(policy.InitialBackoff<<(retry-1), policy.MaxBackoff)
+		backoff := policy.backoff(retry)
+		assert.True(t, backoff >= 0 && backoff <= limit)
+	}
+	ctx := t.Context()
+	unavailable := NewError(CodeUnavailable, errors.New("oops"))
+	_, ok := policy.retryDelay(ctx, unavailable, 1)
+	assert.True(t, ok)
+	_, ok = policy.retryDelay(ctx, unavailable, retryMaxAttemptsLimit)
+	assert.False(t, ok)
+	_, ok = policy.retryDelay(ctx, NewError(CodeInternal, errors.New("oops")), 1)
+	assert.False(t, ok)
+
+	invalid := policy
+	invalid.RetryableCodes = nil
+	assert.NotNil(t, invalid.validate())
+	invalid = policy
+	invalid.MaxAttempts = 1
+	assert.NotNil(t, invalid.validate())
+}
+
+func TestRetryPushback(t *testing.T) {
+	t.Parallel()
+	newError := func(value string) error {
+		err := NewError(CodeUnavailable, errors.New("oops"))
+		err.Meta().Set(grpcHeaderRetryPushback, value)
+		return err
+	}
+	delay, ok := retryPushback(newError("100"))
+	assert.True(t, ok)
+	assert.Equal(t, delay, 100*time.Millisecond)
+	delay, ok = retryPushback(newError("-1"))
+	assert.True(t, ok)
+	assert.True(t, delay < 0)
+	delay, ok = retryPushback(newError("soon"))
+	assert.True(t, ok)
+	assert.True(t, delay < 0)
+	_, ok = retryPushback(NewError(CodeUnavailable, errors.New("oops")))
+	assert.False(t, ok)
+}
+
+func TestRetryInfoDelay(t *testing.T) {
+	t.Parallel()
+	newError := func(delay time.Duration) *Error {
+		duration, err := proto.Marshal(durationpb.New(delay))
+		assert.Nil(t, err)
+		// An unknown field, then google.rpc.RetryInfo.retry_delay.
+		value := protowire.AppendTag(nil, 2, protowire.VarintType)
+		value = protowire.AppendVarint(value, 1)
+		value = protowire.AppendTag(value, 1, protowire.BytesType)
+		value = protowire.AppendBytes(value, duration)
+		detail, err := NewErrorDetail(&anypb.Any{
+			TypeUrl: defaultAnyResolverPrefix + retryInfoTypeName,
+			Value:   value,
+		})
+		assert.Nil(t, err)
+		connectErr := NewError(CodeUnavailable, errors.New("oops"))
+		connectErr.AddDetail(detail)
+		return connectErr
+	}
+	delay, ok := retryInfoDelay(newError(1500 * time.Millisecond))
+	assert.True(t, ok)
+	assert.Equal(t, delay, 1500*time.Millisecond)
+	_, ok = retryInfoDelay(NewError(CodeUnavailable, errors.New("oops")))
+	assert.False(t, ok)
+
+	// The server's delay replaces the backoff, but the pushback header takes
+	// precedence.
+	policy := RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    time.Millisecond,
+		MaxBackoff:        time.Millisecond,
+		BackoffMultiplier: 1,
+		RetryableCodes:    []Code{CodeUnavailable},
+	}
+	err := newError(time.Minute)
+	delay, ok = policy.retryDelay(t.Context(), err, 1)
+	assert.True(t, ok)
+	assert.Equal(t, delay, time.Minute)
+	err.Meta().Set(grpcHeaderRetryPushback, "10")
+	delay, ok = policy.retryDelay(t.Context(), err, 1)
+	assert.True(t, ok)
+	assert.Equal(t, delay, 10*time.Millisecond)
+}
+
+func TestRetryThrottle(t *testing.T) {
+	t.Parallel()
+	throttle := NewRetryThrottle(4, 0.5)
+	assert.True(t, throttle.onFailure())  // 3 tokens
+	assert.False(t, throttle.onFailure()) // 2 tokens
+	throttle.onSuccess()                  // 2.5 tokens
+	assert.False(t, throttle.onFailure()) // 1.5 tokens
+	for range 10 {
+		throttle.onSuccess()
+	}
+	assert.True(t, throttle.onFailure())
+	var disabled *RetryThrottle
+	assert.True(t, disabled.onFailure())
+}
+
+func TestAttemptsFromHeader(t *testing.T) {
+	t.Parallel()
+	header := make(http.Header)
+	assert.Equal(t, attemptsFromHeader(header), 1)
+	header.Set(grpcHeaderPreviousAttempts, "2")
+	assert.Equal(t, attemptsFromHeader(header), 3)
+	header.Set(grpcHeaderPreviousAttempts, "many")
+	assert.Equal(t, attemptsFromHeader(header), 1)
+}
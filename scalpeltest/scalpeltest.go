@@ -0,0 +1,140 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scalpeltest runs HTTP handlers in memory for tests. Servers use
+// in-memory pipes rather than TCP, so tests need no ports and no network, and
+// they support HTTP/2 (via h2c) as well as HTTP/1.1.
+//
+// A typical test serves a generated service and calls it with a generated
+// client:
+//
+//	mux := scalpel.NewMux(nil)
+//	mux.HandleService(pingv1connect.NewPingServiceHandler(&pingServer{}))
+//	server := scalpeltest.NewServer(t, mux)
+//	client := scalpeltest.NewClient(server, pingv1connect.NewPingServiceClient, scalpel.WithGRPC())
+package scalpeltest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/memhttp"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+)
+
+// Server is an in-memory HTTP server. It's shut down automatically when the
+// test that started it completes.
+type Server struct {
+	server *memhttp.Server
+}
+
+// NewServer starts serving the handler in memory. The server is shut down
+// when the test and its subtests complete; if it can't shut down gracefully
+// within the cleanup timeout, the test fails.
+func NewServer(tb testing.TB, handler http.Handler, options ...Option) *Server {
+	tb.Helper()
+	var config serverConfig
+	for _, option := range options {
+		option.apply(&config)
+	}
+	var memhttpOptions []memhttp.Option
+	if config.CleanupTimeout > 0 {
+		memhttpOptions = append(memhttpOptions, memhttp.WithCleanupTimeout(config.CleanupTimeout))
+	}
+	return &Server{server: memhttptest.NewServer(tb, handler, memhttpOptions...)}
+}
+
+// URL returns the server's base URL, suitable for generated clients.
+func (s *Server) URL() string {
+	return s.server.URL()
+}
+
+// Client returns an HTTP client that connects to the server over HTTP/2
+// without TLS (h2c). It supports all RPC protocols and stream types.
+//
+// Each call returns a new client, which callers may reconfigure without
+// affecting other clients.
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+// ClientHTTP1 returns an HTTP client that connects to the server over
+// HTTP/1.1. Like browsers using HTTP/1.1, it can't make bidirectional
+// streaming calls.
+//
+// Each call returns a new client, which callers may reconfigure without
+// affecting other clients.
+func (s *Server) ClientHTTP1() *http.Client {
+	return &http.Client{Transport: s.server.TransportHTTP1()}
+}
+
+// Shutdown gracefully shuts down the server, without interrupting active
+// connections. It's only needed to test shutdown, since servers shut down
+// automatically when their test completes. See [http.Server.Shutdown] for
+// details.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Close closes the server immediately, interrupting active connections.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// NewClient constructs a generated client for the server, using an HTTP/2
+// client. Pass the generated constructor itself:
+//
+//	client := scalpeltest.NewClient(server, pingv1connect.NewPingServiceClient)
+func NewClient[T any](
+	server *Server,
+	newClient func(scalpel.HTTPClient, string, ...scalpel.ClientOption) T,
+	options ...scalpel.ClientOption,
+) T {
+	return newClient(server.Client(), server.URL(), options...)
+}
+
+// NewClientHTTP1 is like [NewClient], but uses an HTTP/1.1 client.
+func NewClientHTTP1[T any](
+	server *Server,
+	newClient func(scalpel.HTTPClient, string, ...scalpel.ClientOption) T,
+	options ...scalpel.ClientOption,
+) T {
+	return newClient(server.ClientHTTP1(), server.URL(), options...)
+}
+
+// An Option configures a Server.
+type Option interface {
+	apply(*serverConfig)
+}
+
+// WithCleanupTimeout sets how long a server waits for active connections to
+// finish when its test completes. The default is five seconds.
+func WithCleanupTimeout(timeout time.Duration) Option {
+	return &cleanupTimeoutOption{timeout: timeout}
+}
+
+type serverConfig struct {
+	CleanupTimeout time.Duration
+}
+
+type cleanupTimeoutOption struct {
+	timeout time.Duration
+}
+
+func (o *cleanupTimeoutOption) apply(config *serverConfig) {
+	config.CleanupTimeout = o.timeout
+}
@@ -0,0 +1,118 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpeltest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/scalpeltest"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	mux := connect.NewMux(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	mux.HandleService(pingv1connect.NewPingServiceHandler(pingServer{}))
+	server := scalpeltest.NewServer(t, mux, scalpeltest.WithCleanupTimeout(time.Second))
+
+	t.Run("generated_clients", func(t *testing.T) {
+		t.Parallel()
+		clients := []pingv1connect.PingServiceClient{
+			scalpeltest.NewClient(server, pingv1connect.NewPingServiceClient),
+			scalpeltest.NewClient(server, pingv1connect.NewPingServiceClient, connect.WithGRPC()),
+			scalpeltest.NewClientHTTP1(server, pingv1connect.NewPingServiceClient, connect.WithGRPCWeb()),
+		}
+		for _, client := range clients {
+			response, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: 42}))
+			assert.Nil(t, err)
+			assert.Equal(t, response.Msg.GetNumber(), 42)
+		}
+		// Bidirectional streaming needs HTTP/2.
+		stream := scalpeltest.NewClient(server, pingv1connect.NewPingServiceClient).CumSum(t.Context())
+		assert.Nil(t, stream.Send(&pingv1.CumSumRequest{Number: 1}))
+		assert.Nil(t, stream.Send(&pingv1.CumSumRequest{Number: 2}))
+		assert.Nil(t, stream.CloseRequest())
+		var sums []int64
+		for {
+			response, err := stream.Receive()
+			if err != nil {
+				break
+			}
+			sums = append(sums, response.GetSum())
+		}
+		assert.Equal(t, sums, []int64{1, 3})
+		assert.Nil(t, stream.CloseResponse())
+	})
+	t.Run("protocols", func(t *testing.T) {
+		t.Parallel()
+		for client, proto := range map[*http.Client]string{
+			server.Client():      "HTTP/2.0",
+			server.ClientHTTP1(): "HTTP/1.1",
+		} {
+			request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL()+"/", http.NoBody)
+			assert.Nil(t, err)
+			response, err := client.Do(request)
+			assert.Nil(t, err)
+			body, err := io.ReadAll(response.Body)
+			assert.Nil(t, err)
+			assert.Nil(t, response.Body.Close())
+			assert.Equal(t, string(body), proto)
+		}
+	})
+}
+
+func TestServerShutdown(t *testing.T) {
+	t.Parallel()
+	server := scalpeltest.NewServer(t, http.NotFoundHandler())
+	assert.Nil(t, server.Shutdown(t.Context()))
+	client := scalpeltest.NewClient(server, pingv1connect.NewPingServiceClient)
+	_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{}))
+	assert.NotNil(t, err)
+}
+
+type pingServer struct {
+	pingv1connect.UnimplementedPingServiceHandler
+}
+
+func (pingServer) Ping(_ context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+	return connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber()}), nil
+}
+
+func (pingServer) CumSum(_ context.Context, stream *connect.BidiStream[pingv1.CumSumRequest, pingv1.CumSumResponse]) error {
+	var sum int64
+	for {
+		request, err := stream.Receive()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		sum += request.GetNumber()
+		if err := stream.Send(&pingv1.CumSumResponse{Sum: sum}); err != nil {
+			return err
+		}
+	}
+}
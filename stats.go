@@ -0,0 +1,405 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A StatsHandler observes the lifecycle of RPCs at the transport level, much
+// like grpc-go's stats.Handler. It's a lower-level instrumentation point than
+// an [Interceptor]: it sees each attempt of retried and hedged calls, and the
+// exact number of bytes sent and received for every message. Metrics and
+// tracing integrations are usually built on top of it.
+//
+// StatsHandlers are configured with [WithStatsHandler], and must be safe to
+// call concurrently.
+type StatsHandler interface {
+	// TagRPC is called once at the start of each RPC, before any events. The
+	// returned context is passed to HandleRPC for all of the RPC's events. On
+	// the server, it's also the context passed to interceptors and the
+	// implementation.
+	TagRPC(ctx context.Context, spec Spec) context.Context
+	// HandleRPC processes an event. It's called synchronously on the
+	// goroutines that send and receive messages, so it should return quickly.
+	HandleRPC(ctx context.Context, event StatsEvent)
+}
+
+// A StatsEvent describes a point in an RPC's lifecycle. It's one of
+// *[StatsBegin], *[StatsOutHeader], *[StatsInHeader], *[StatsOutPayload],
+// *[StatsInPayload], *[StatsOutTrailer], *[StatsInTrailer], or *[StatsEnd].
+//
+// For each RPC, a [StatsHandler] receives StatsBegin first and StatsEnd last.
+// StatsBegin isn't sent for requests that handlers reject before they
+// determine the RPC protocol, for example because of an unsupported
+// compression algorithm.
+type StatsEvent interface {
+	// IsClient reports whether the event is from a client.
+	IsClient() bool
+
+	isStatsEvent()
+}
+
+// StatsBegin is the first event of an RPC.
+type StatsBegin struct {
+	Client    bool
+	BeginTime time.Time
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsBegin) IsClient() bool { return s.Client }
+
+func (*StatsBegin) isStatsEvent() {}
+
+// StatsOutHeader is sent when headers are sent: on the client, the request
+// headers of each attempt, and on the server, the response headers.
+type StatsOutHeader struct {
+	Client bool
+	Header http.Header
+	// Attempt is the attempt whose headers are being sent, counting from one.
+	// It's greater than one only for clients that retry or hedge calls, and is
+	// always one on the server.
+	Attempt int
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsOutHeader) IsClient() bool { return s.Client }
+
+func (*StatsOutHeader) isStatsEvent() {}
+
+// StatsInHeader is sent when headers are received: on the client, the response
+// headers, and on the server, the request headers.
+type StatsInHeader struct {
+	Client bool
+	Header http.Header
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsInHeader) IsClient() bool { return s.Client }
+
+func (*StatsInHeader) isStatsEvent() {}
+
+// StatsOutPayload is sent after a message is sent.
+type StatsOutPayload struct {
+	Client bool
+	// Length is the size of the marshaled message, before compression.
+	Length int
+	// WireLength is the number of bytes written to the network for the
+	// message, after compression and including any protocol framing.
+	WireLength int
+	// Compressed reports whether the message was compressed.
+	Compressed bool
+	SentTime   time.Time
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsOutPayload) IsClient() bool { return s.Client }
+
+func (*StatsOutPayload) isStatsEvent() {}
+
+// StatsInPayload is sent after a message is received and unmarshaled.
+type StatsInPayload struct {
+	Client bool
+	// Length is the size of the marshaled message, after decompression.
+	Length int
+	// WireLength is the number of bytes read from the network for the
+	// message, before decompression and including any protocol framing.
+	WireLength int
+	// Compressed reports whether the message was compressed.
+	Compressed bool
+	RecvTime   time.Time
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsInPayload) IsClient() bool { return s.Client }
+
+func (*StatsInPayload) isStatsEvent() {}
+
+// StatsOutTrailer is sent on the server before the response trailers are
+// written. Trailer holds the application's trailers, without the
+// protocol-specific status.
+type StatsOutTrailer struct {
+	Client  bool
+	Trailer http.Header
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsOutTrailer) IsClient() bool { return s.Client }
+
+func (*StatsOutTrailer) isStatsEvent() {}
+
+// StatsInTrailer is sent on the client once the response trailers have been
+// received.
+type StatsInTrailer struct {
+	Client  bool
+	Trailer http.Header
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsInTrailer) IsClient() bool { return s.Client }
+
+func (*StatsInTrailer) isStatsEvent() {}
+
+// StatsEnd is the last event of an RPC. On the client, it's sent when the
+// response is closed.
+type StatsEnd struct {
+	Client    bool
+	BeginTime time.Time
+	EndTime   time.Time
+	// Error is the error the RPC ended with, or nil if it succeeded.
+	Error error
+}
+
+// IsClient implements [StatsEvent].
+func (s *StatsEnd) IsClient() bool { return s.Client }
+
+// Code returns the code of the RPC's error, or zero if it succeeded.
+func (s *StatsEnd) Code() Code {
+	if s.Error == nil {
+		return 0
+	}
+	return CodeOf(s.Error)
+}
+
+// Duration returns how long the RPC took.
+func (s *StatsEnd) Duration() time.Duration {
+	return s.EndTime.Sub(s.BeginTime)
+}
+
+func (*StatsEnd) isStatsEvent() {}
+
+// rpcStats sends the events for a single RPC to its stats handlers. A nil
+// *rpcStats is valid and discards all events, so protocol code can record
+// events unconditionally.
+type rpcStats struct {
+	handlers  []StatsHandler
+	ctx       context.Context //nolint:containedctx
+	client    bool
+	beginTime time.Time
+	endOnce   sync.Once
+}
+
+// statsContextKey is the key used to pass an RPC's rpcStats to protocol
+// implementations.
+type statsContextKey struct{}
+
+// newRPCStats tags the context for a new RPC. If there are no handlers, it
+// returns the context unchanged and a nil *rpcStats.
+func newRPCStats(ctx context.Context, handlers []StatsHandler, spec Spec) (context.Context, *rpcStats) {
+	if len(handlers) == 0 {
+		return ctx, nil
+	}
+	for _, handler := range handlers {
+		ctx = handler.TagRPC(ctx, spec)
+	}
+	stats := &rpcStats{
+		handlers: handlers,
+		client:   spec.IsClient,
+	}
+	ctx = context.WithValue(ctx, statsContextKey{}, stats)
+	stats.ctx = ctx
+	return ctx, stats
+}
+
+// statsFromContext returns the rpcStats for the current RPC, if any. Handlers
+// often make client calls with their own context, so the side of the RPC must
+// match.
+func statsFromContext(ctx context.Context, client bool) *rpcStats {
+	stats, ok := ctx.Value(statsContextKey{}).(*rpcStats)
+	if !ok || stats.client != client {
+		return nil
+	}
+	return stats
+}
+
+func (s *rpcStats) handle(event StatsEvent) {
+	for _, handler := range s.handlers {
+		handler.HandleRPC(s.ctx, event)
+	}
+}
+
+func (s *rpcStats) begin() {
+	if s == nil {
+		return
+	}
+	s.beginTime = time.Now()
+	s.handle(&StatsBegin{Client: s.client, BeginTime: s.beginTime})
+}
+
+func (s *rpcStats) outHeader(header http.Header, attempt int) {
+	if s == nil {
+		return
+	}
+	s.handle(&StatsOutHeader{Client: s.client, Header: header, Attempt: attempt})
+}
+
+func (s *rpcStats) inHeader(header http.Header) {
+	if s == nil {
+		return
+	}
+	s.handle(&StatsInHeader{Client: s.client, Header: header})
+}
+
+func (s *rpcStats) outPayload(length, wireLength int, compressed bool) {
+	if s == nil {
+		return
+	}
+	s.handle(&StatsOutPayload{
+		Client:     s.client,
+		Length:     length,
+		WireLength: wireLength,
+		Compressed: compressed,
+		SentTime:   time.Now(),
+	})
+}
+
+func (s *rpcStats) inPayload(length, wireLength int, compressed bool) {
+	if s == nil {
+		return
+	}
+	s.handle(&StatsInPayload{
+		Client:     s.client,
+		Length:     length,
+		WireLength: wireLength,
+		Compressed: compressed,
+		RecvTime:   time.Now(),
+	})
+}
+
+func (s *rpcStats) outTrailer(trailer http.Header) {
+	if s == nil {
+		return
+	}
+	s.handle(&StatsOutTrailer{Client: s.client, Trailer: trailer})
+}
+
+func (s *rpcStats) inTrailer(trailer http.Header) {
+	if s == nil {
+		return
+	}
+	s.handle(&StatsInTrailer{Client: s.client, Trailer: trailer})
+}
+
+func (s *rpcStats) end(err error) {
+	if s == nil {
+		return
+	}
+	s.endOnce.Do(func() {
+		s.handle(&StatsEnd{
+			Client:    s.client,
+			BeginTime: s.beginTime,
+			EndTime:   time.Now(),
+			Error:     err,
+		})
+	})
+}
+
+// statsHandlerConn reports a handler's response headers, trailers, and the end
+// of the RPC. Payloads are reported by the protocol's marshalers.
+type statsHandlerConn struct {
+	handlerConnCloser
+
+	stats       *rpcStats
+	wroteHeader bool
+}
+
+func (hc *statsHandlerConn) Send(msg any) error {
+	hc.writeHeader()
+	return hc.handlerConnCloser.Send(msg)
+}
+
+func (hc *statsHandlerConn) Close(err error) error {
+	hc.writeHeader()
+	hc.stats.outTrailer(hc.ResponseTrailer())
+	closeErr := hc.handlerConnCloser.Close(err)
+	hc.stats.end(err)
+	return closeErr
+}
+
+func (hc *statsHandlerConn) getHTTPMethod() string {
+	if methoder, ok := hc.handlerConnCloser.(hasHTTPMethod); ok {
+		return methoder.getHTTPMethod()
+	}
+	return http.MethodPost
+}
+
+func (hc *statsHandlerConn) writeHeader() {
+	if !hc.wroteHeader {
+		hc.wroteHeader = true
+		hc.stats.outHeader(hc.ResponseHeader(), 1)
+	}
+}
+
+// statsClientConn reports a client's response trailers and the end of the
+// RPC. Headers are reported by the duplexHTTPCall, and payloads by the
+// protocol's marshalers.
+type statsClientConn struct {
+	streamingClientConn
+
+	stats *rpcStats
+
+	mu  sync.Mutex
+	err error
+}
+
+func (cc *statsClientConn) Send(msg any) error {
+	// Send returns io.EOF when the server has ended the stream, and the real
+	// error comes from Receive.
+	return cc.record(cc.streamingClientConn.Send(msg))
+}
+
+func (cc *statsClientConn) CloseRequest() error {
+	return cc.record(cc.streamingClientConn.CloseRequest())
+}
+
+func (cc *statsClientConn) Receive(msg any) error {
+	return cc.record(cc.streamingClientConn.Receive(msg))
+}
+
+func (cc *statsClientConn) CloseResponse() error {
+	err := cc.record(cc.streamingClientConn.CloseResponse())
+	cc.stats.inTrailer(cc.ResponseTrailer())
+	cc.mu.Lock()
+	rpcErr := cc.err
+	cc.mu.Unlock()
+	cc.stats.end(rpcErr)
+	return err
+}
+
+// record saves the first error that ends the RPC.
+func (cc *statsClientConn) record(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.err == nil {
+		cc.err = err
+	}
+	return err
+}
+
+// wrapClientConn wraps a client's conn to report the end of its lifecycle.
+func (s *rpcStats) wrapClientConn(conn streamingClientConn) streamingClientConn {
+	if s == nil {
+		return conn
+	}
+	return &statsClientConn{streamingClientConn: conn, stats: s}
+}
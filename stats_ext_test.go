@@ -0,0 +1,304 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	pingv1 "github.com/agentio/scalpel/internal/gen/connect/ping/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/ping/v1/pingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+)
+
+func TestStatsHandler(t *testing.T) {
+	t.Parallel()
+	newServer := func(t *testing.T, stats connect.StatsHandler, ping func(context.Context, *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error)) *memhttp.Server {
+		t.Helper()
+		mux := http.NewServeMux()
+		mux.Handle(pingv1connect.NewPingServiceHandler(
+			&pluggablePingServer{
+				ping: ping,
+				countUp: func(ctx context.Context, request *connect.Request[pingv1.CountUpRequest], stream *connect.ServerStream[pingv1.CountUpResponse]) error {
+					assert.Equal(t, ctx.Value(statsTagKey{}), "tagged")
+					for i := range request.Msg.GetNumber() {
+						if err := stream.Send(&pingv1.CountUpResponse{Number: i + 1}); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			connect.WithStatsHandler(stats),
+		))
+		return memhttptest.NewServer(t, mux)
+	}
+	echo := func(ctx context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+		assert.Equal(t, ctx.Value(statsTagKey{}), "tagged")
+		if request.Msg.GetNumber() < 0 {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("negative"))
+		}
+		return connect.NewResponse(&pingv1.PingResponse{Number: request.Msg.GetNumber(), Text: request.Msg.GetText()}), nil
+	}
+	protocols := []struct {
+		name   string
+		option connect.ClientOption
+	}{
+		{"connect", connect.WithClientOptions()},
+		{"grpc", connect.WithGRPC()},
+		{"grpcweb", connect.WithGRPCWeb()},
+	}
+	for _, protocol := range protocols {
+		t.Run(protocol.name, func(t *testing.T) {
+			t.Parallel()
+			t.Run("unary", func(t *testing.T) {
+				t.Parallel()
+				serverStats, clientStats := &recordingStatsHandler{}, &recordingStatsHandler{}
+				server := newServer(t, serverStats, echo)
+				client := pingv1connect.NewPingServiceClient(
+					server.Client(),
+					server.URL(),
+					protocol.option,
+					connect.WithSendGzip(),
+					connect.WithStatsHandler(clientStats),
+				)
+				text := strings.Repeat("hello ", 100)
+				_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: 42, Text: text}))
+				assert.Nil(t, err)
+				serverEvents := serverStats.wait(t, 1)
+				assert.Equal(t, serverStats.kinds(), []string{
+					"begin", "in_header", "in_payload", "out_header", "out_payload", "out_trailer", "end",
+				})
+				clientEvents := clientStats.wait(t, 1)
+				kinds := clientStats.kinds()
+				assert.Equal(t, kinds[0], "begin")
+				assert.Equal(t, kinds[len(kinds)-1], "end")
+				assert.Equal(t, len(kinds), 7)
+				assert.Equal(t, clientStats.specs[0].Procedure, pingv1connect.PingServicePingProcedure)
+				assert.True(t, clientStats.specs[0].IsClient)
+				assert.False(t, serverStats.specs[0].IsClient)
+
+				// Both sides agree on the size of each message.
+				sent := findEvent[*connect.StatsOutPayload](clientEvents)
+				received := findEvent[*connect.StatsInPayload](serverEvents)
+				assert.True(t, sent.Compressed)
+				assert.True(t, sent.WireLength < sent.Length)
+				assert.Equal(t, sent.Length, received.Length)
+				assert.Equal(t, sent.WireLength, received.WireLength)
+				assert.Equal(t, sent.Compressed, received.Compressed)
+				sent = findEvent[*connect.StatsOutPayload](serverEvents)
+				received = findEvent[*connect.StatsInPayload](clientEvents)
+				assert.Equal(t, sent.Length, received.Length)
+				assert.Equal(t, sent.WireLength, received.WireLength)
+
+				outHeader := findEvent[*connect.StatsOutHeader](clientEvents)
+				assert.Equal(t, outHeader.Attempt, 1)
+				end := findEvent[*connect.StatsEnd](clientEvents)
+				assert.Nil(t, end.Error)
+				assert.Equal(t, end.Code(), connect.Code(0))
+				assert.True(t, end.Duration() > 0)
+			})
+			t.Run("error", func(t *testing.T) {
+				t.Parallel()
+				serverStats, clientStats := &recordingStatsHandler{}, &recordingStatsHandler{}
+				server := newServer(t, serverStats, echo)
+				client := pingv1connect.NewPingServiceClient(
+					server.Client(),
+					server.URL(),
+					protocol.option,
+					connect.WithStatsHandler(clientStats),
+				)
+				_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: -1}))
+				assert.Equal(t, connect.CodeOf(err), connect.CodeFailedPrecondition)
+				serverEnd := findEvent[*connect.StatsEnd](serverStats.wait(t, 1))
+				assert.Equal(t, serverEnd.Code(), connect.CodeFailedPrecondition)
+				clientEnd := findEvent[*connect.StatsEnd](clientStats.wait(t, 1))
+				assert.Equal(t, clientEnd.Code(), connect.CodeFailedPrecondition)
+			})
+			t.Run("server_stream", func(t *testing.T) {
+				t.Parallel()
+				serverStats, clientStats := &recordingStatsHandler{}, &recordingStatsHandler{}
+				server := newServer(t, serverStats, echo)
+				client := pingv1connect.NewPingServiceClient(
+					server.Client(),
+					server.URL(),
+					protocol.option,
+					connect.WithStatsHandler(clientStats),
+				)
+				stream, err := client.CountUp(t.Context(), connect.NewRequest(&pingv1.CountUpRequest{Number: 3}))
+				assert.Nil(t, err)
+				for stream.Receive() {
+				}
+				assert.Nil(t, stream.Err())
+				assert.Nil(t, stream.Close())
+				serverStats.wait(t, 1)
+				assert.Equal(t, serverStats.kinds(), []string{
+					"begin", "in_header", "in_payload", "out_header",
+					"out_payload", "out_payload", "out_payload", "out_trailer", "end",
+				})
+				clientStats.wait(t, 1)
+				// The request message and the response headers race.
+				kinds := clientStats.kinds()
+				slices.Sort(kinds[2:4])
+				assert.Equal(t, kinds, []string{
+					"begin", "out_header", "in_header", "out_payload",
+					"in_payload", "in_payload", "in_payload", "in_trailer", "end",
+				})
+			})
+		})
+	}
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+		serverStats, clientStats := &recordingStatsHandler{}, &recordingStatsHandler{}
+		var once sync.Once
+		server := newServer(t, serverStats, func(ctx context.Context, request *connect.Request[pingv1.PingRequest]) (*connect.Response[pingv1.PingResponse], error) {
+			var err error
+			once.Do(func() {
+				err = connect.NewError(connect.CodeUnavailable, errors.New("try again"))
+			})
+			if err != nil {
+				return nil, err
+			}
+			return echo(ctx, request)
+		})
+		client := pingv1connect.NewPingServiceClient(
+			server.Client(),
+			server.URL(),
+			connect.WithGRPC(),
+			connect.WithStatsHandler(clientStats),
+			connect.WithRetryPolicy(connect.RetryPolicy{
+				MaxAttempts:       2,
+				InitialBackoff:    time.Millisecond,
+				MaxBackoff:        time.Millisecond,
+				BackoffMultiplier: 1,
+				RetryableCodes:    []connect.Code{connect.CodeUnavailable},
+			}),
+		)
+		_, err := client.Ping(t.Context(), connect.NewRequest(&pingv1.PingRequest{Number: 1}))
+		assert.Nil(t, err)
+		var attempts []int
+		for _, event := range clientStats.wait(t, 1) {
+			if header, ok := event.(*connect.StatsOutHeader); ok {
+				attempts = append(attempts, header.Attempt)
+			}
+		}
+		assert.Equal(t, attempts, []int{1, 2})
+		// The server sees each attempt as a separate RPC.
+		var codes []connect.Code
+		for _, event := range serverStats.wait(t, 2) {
+			if end, ok := event.(*connect.StatsEnd); ok {
+				codes = append(codes, end.Code())
+			}
+		}
+		assert.Equal(t, codes, []connect.Code{connect.CodeUnavailable, 0})
+	})
+}
+
+type statsTagKey struct{}
+
+// recordingStatsHandler records the events it receives.
+type recordingStatsHandler struct {
+	mu     sync.Mutex
+	specs  []connect.Spec
+	events []connect.StatsEvent
+	ends   int
+}
+
+func (h *recordingStatsHandler) TagRPC(ctx context.Context, spec connect.Spec) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.specs = append(h.specs, spec)
+	return context.WithValue(ctx, statsTagKey{}, "tagged")
+}
+
+func (h *recordingStatsHandler) HandleRPC(ctx context.Context, event connect.StatsEvent) {
+	if ctx.Value(statsTagKey{}) != "tagged" {
+		panic("stats handler called without tagged context") //nolint:forbidigo
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+	if _, ok := event.(*connect.StatsEnd); ok {
+		h.ends++
+	}
+}
+
+// wait waits for the given number of RPCs to end, since servers may finish
+// after clients receive their responses, and returns the events.
+func (h *recordingStatsHandler) wait(t *testing.T, rpcs int) []connect.StatsEvent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.mu.Lock()
+		ends := h.ends
+		events := h.events
+		h.mu.Unlock()
+		if ends >= rpcs {
+			return events
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d RPCs to end", rpcs)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (h *recordingStatsHandler) kinds() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	kinds := make([]string, 0, len(h.events))
+	for _, event := range h.events {
+		var kind string
+		switch event.(type) {
+		case *connect.StatsBegin:
+			kind = "begin"
+		case *connect.StatsOutHeader:
+			kind = "out_header"
+		case *connect.StatsInHeader:
+			kind = "in_header"
+		case *connect.StatsOutPayload:
+			kind = "out_payload"
+		case *connect.StatsInPayload:
+			kind = "in_payload"
+		case *connect.StatsOutTrailer:
+			kind = "out_trailer"
+		case *connect.StatsInTrailer:
+			kind = "in_trailer"
+		case *connect.StatsEnd:
+			kind = "end"
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+func findEvent[T connect.StatsEvent](events []connect.StatsEvent) T {
+	for _, event := range events {
+		if typed, ok := event.(T); ok {
+			return typed
+		}
+	}
+	var zero T
+	return zero
+}
@@ -0,0 +1,782 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// httpRuleFieldNumber is the field number of the google.api.http extension
+// to google.protobuf.MethodOptions.
+const httpRuleFieldNumber protowire.Number = 72295728
+
+// A Transcoder serves unary procedures as plain HTTP/JSON endpoints, using
+// the google.api.http annotations in their Protobuf schemas:
+//
+//	rpc GetBook(GetBookRequest) returns (GetBookResponse) {
+//	  option (google.api.http) = {
+//	    get: "/v1/{name=shelves/*/books/*}"
+//	    response_body: "book"
+//	  };
+//	}
+//
+// Path variables, query parameters, and the request body are mapped onto the
+// request message as described in the documentation for google.api.HttpRule.
+// The request is then passed to the procedure's [Handler] as a Connect
+// request, so interceptors, timeouts, and other handler options apply as
+// usual. Successful responses are rendered as JSON, and errors use the
+// Connect protocol's JSON error format and HTTP status codes.
+//
+// Handlers are added to a Transcoder with the [WithTranscoder] option, which
+// may be passed to generated NewXServiceHandler functions. Only handlers with
+// a [protoreflect.MethodDescriptor] schema and an HTTP rule are added: rules
+// on streaming procedures are ignored. Requests that don't match any rule are
+// passed to a fallback handler, usually the [Mux] or [http.ServeMux] that
+// serves the RPC protocols:
+//
+//	mux := scalpel.NewMux(nil)
+//	transcoder := scalpel.NewTranscoder(mux)
+//	mux.HandleService(libraryv1connect.NewLibraryServiceHandler(
+//	  &libraryServer{},
+//	  scalpel.WithTranscoder(transcoder),
+//	))
+//	http.ListenAndServe(":8080", transcoder)
+//
+// If more than one rule matches a request, the first one added wins.
+// Transcoders are safe to use concurrently.
+type Transcoder struct {
+	fallback    http.Handler
+	errorWriter *ErrorWriter
+
+	mu     sync.RWMutex
+	routes []*transcodingRoute
+}
+
+// NewTranscoder constructs a Transcoder. Requests that don't match any HTTP
+// rule are passed to the fallback handler. If fallback is nil, they get a 404
+// Not Found response.
+func NewTranscoder(fallback http.Handler) *Transcoder {
+	if fallback == nil {
+		fallback = http.NotFoundHandler()
+	}
+	return &Transcoder{
+		fallback:    fallback,
+		errorWriter: NewErrorWriter(),
+	}
+}
+
+// ServeHTTP implements [http.Handler].
+func (t *Transcoder) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	route, variables := t.match(request)
+	if route == nil {
+		t.fallback.ServeHTTP(responseWriter, request)
+		return
+	}
+	if err := route.serve(responseWriter, request, variables); err != nil {
+		setHeaderCanonical(responseWriter.Header(), headerContentType, connectUnaryContentTypeJSON)
+		_ = t.errorWriter.writeConnectUnary(responseWriter, err)
+	}
+}
+
+// handle adds the HTTP rules for a unary handler. It panics if the rules are
+// invalid, or if a rule is already registered.
+func (t *Transcoder) handle(handler *Handler, readMaxBytes int) {
+	method, ok := handler.spec.Schema.(protoreflect.MethodDescriptor)
+	if !ok || handler.spec.StreamType != StreamTypeUnary {
+		return
+	}
+	rules, err := httpRulesForMethod(method)
+	if err != nil {
+		panic(fmt.Sprintf("scalpel: invalid HTTP rule for %s: %v", handler.spec.Procedure, err)) //nolint:forbidigo
+	}
+	routes := make([]*transcodingRoute, 0, len(rules))
+	for _, rule := range rules {
+		route, err := newTranscodingRoute(handler, method, rule, readMaxBytes)
+		if err != nil {
+			panic(fmt.Sprintf("scalpel: invalid HTTP rule for %s: %v", handler.spec.Procedure, err)) //nolint:forbidigo
+		}
+		routes = append(routes, route)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, route := range routes {
+		for _, existing := range t.routes {
+			if existing.method == route.method && existing.pattern == route.pattern {
+				panic(fmt.Sprintf("scalpel: multiple registrations for %s %s", route.method, route.pattern)) //nolint:forbidigo
+			}
+		}
+	}
+	t.routes = append(t.routes, routes...)
+}
+
+func (t *Transcoder) match(request *http.Request) (*transcodingRoute, []transcodingVariable) {
+	path := request.URL.EscapedPath()
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, route := range t.routes {
+		if route.method != "*" && route.method != request.Method {
+			continue
+		}
+		if variables, ok := route.template.match(path); ok {
+			return route, variables
+		}
+	}
+	return nil, nil
+}
+
+// A transcodingRoute is a single HTTP binding for a unary procedure.
+type transcodingRoute struct {
+	method        string // an HTTP method, or "*" for any method
+	pattern       string
+	template      *pathTemplate
+	handler       *Handler
+	input         protoreflect.MessageType
+	output        protoreflect.MessageType
+	body          string                       // "", "*", or a field name
+	bodyField     protoreflect.FieldDescriptor // if body is a field name
+	responseField protoreflect.FieldDescriptor // nil to send the whole response
+	readMaxBytes  int
+}
+
+func newTranscodingRoute(handler *Handler, method protoreflect.MethodDescriptor, rule *httpRule, readMaxBytes int) (*transcodingRoute, error) {
+	if rule.method == "" || rule.pattern == "" {
+		return nil, errors.New("no HTTP method and path")
+	}
+	template, err := parsePathTemplate(rule.pattern)
+	if err != nil {
+		return nil, err
+	}
+	route := &transcodingRoute{
+		method:       rule.method,
+		pattern:      rule.pattern,
+		template:     template,
+		handler:      handler,
+		input:        messageTypeFor(method.Input()),
+		output:       messageTypeFor(method.Output()),
+		body:         rule.body,
+		readMaxBytes: readMaxBytes,
+	}
+	for i, variable := range template.variables {
+		fields, err := resolveFieldPath(method.Input(), variable.fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		if field := fields[len(fields)-1]; field.IsList() || field.IsMap() || field.Message() != nil {
+			return nil, fmt.Errorf("path variable %s must be a singular scalar field", variable.fieldPath)
+		}
+		template.variables[i].fields = fields
+	}
+	if rule.body != "" && rule.body != "*" {
+		route.bodyField = method.Input().Fields().ByName(protoreflect.Name(rule.body))
+		if route.bodyField == nil {
+			return nil, fmt.Errorf("unknown body field %q in %s", rule.body, method.Input().FullName())
+		}
+	}
+	if rule.responseBody != "" {
+		route.responseField = method.Output().Fields().ByName(protoreflect.Name(rule.responseBody))
+		if route.responseField == nil {
+			return nil, fmt.Errorf("unknown response_body field %q in %s", rule.responseBody, method.Output().FullName())
+		}
+	}
+	return route, nil
+}
+
+// serve transcodes the request, calls the handler, and transcodes its
+// response. Errors returned before the handler is called should be written
+// by the caller.
+func (r *transcodingRoute) serve(responseWriter http.ResponseWriter, request *http.Request, variables []transcodingVariable) error {
+	message, err := r.newRequestMessage(request, variables)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(message.Interface())
+	if err != nil {
+		return errorf(CodeInternal, "marshal request: %w", err)
+	}
+	// Call the handler with an equivalent Connect request, using the binary
+	// Protobuf codec so that the handler's JSON options don't matter.
+	inner := request.Clone(request.Context())
+	inner.Method = http.MethodPost
+	inner.URL.Path = r.handler.spec.Procedure
+	inner.URL.RawPath = ""
+	inner.URL.RawQuery = ""
+	inner.Body = io.NopCloser(bytes.NewReader(data))
+	inner.ContentLength = int64(len(data))
+	inner.GetBody = nil
+	delHeaderCanonical(inner.Header, headerContentLength)
+	delHeaderCanonical(inner.Header, connectUnaryHeaderCompression)
+	delHeaderCanonical(inner.Header, connectUnaryHeaderAcceptCompression)
+	setHeaderCanonical(inner.Header, headerContentType, connectUnaryContentTypePrefix+codecNameProto)
+	setHeaderCanonical(inner.Header, connectHeaderProtocolVersion, connectProtocolVersion)
+	recorder := &transcodingResponseWriter{header: make(http.Header)}
+	r.handler.ServeHTTP(recorder, inner)
+	if recorder.status == 0 {
+		// As with net/http, handlers that don't write anything succeed.
+		recorder.status = http.StatusOK
+	}
+
+	header := responseWriter.Header()
+	mergeNonProtocolHeaders(header, recorder.header)
+	if recorder.status != http.StatusOK {
+		// Errors are already in the Connect protocol's JSON format.
+		setHeaderCanonical(header, headerContentType, getHeaderCanonical(recorder.header, headerContentType))
+		responseWriter.WriteHeader(recorder.status)
+		_, _ = responseWriter.Write(recorder.body.Bytes())
+		return nil
+	}
+	response := r.output.New()
+	if err := proto.Unmarshal(recorder.body.Bytes(), response.Interface()); err != nil {
+		return errorf(CodeInternal, "unmarshal response: %w", err)
+	}
+	body, err := r.marshalResponse(response)
+	if err != nil {
+		return err
+	}
+	setHeaderCanonical(header, headerContentType, connectUnaryContentTypeJSON)
+	responseWriter.WriteHeader(http.StatusOK)
+	_, _ = responseWriter.Write(body)
+	return nil
+}
+
+// newRequestMessage builds the request message from the request body, then
+// the query parameters, then the path variables.
+func (r *transcodingRoute) newRequestMessage(request *http.Request, variables []transcodingVariable) (protoreflect.Message, error) {
+	message := r.input.New()
+	if r.body != "" {
+		data, err := r.readBody(request)
+		if err != nil {
+			return nil, err
+		}
+		if r.bodyField != nil && len(data) > 0 {
+			// Wrap the body so that protojson can unmarshal any type of field.
+			data = append([]byte(`{"`+r.bodyField.JSONName()+`":`), append(data, '}')...)
+		}
+		if len(data) > 0 {
+			if err := protojson.Unmarshal(data, message.Interface()); err != nil {
+				return nil, errorf(CodeInvalidArgument, "unmarshal request body: %w", err)
+			}
+		}
+	}
+	if r.body != "*" {
+		query, err := url.ParseQuery(request.URL.RawQuery)
+		if err != nil {
+			return nil, errorf(CodeInvalidArgument, "invalid query: %w", err)
+		}
+		for key, values := range query {
+			fields, err := resolveFieldPath(message.Descriptor(), key)
+			if err != nil {
+				return nil, errorf(CodeInvalidArgument, "invalid query parameter %q: %w", key, err)
+			}
+			if err := setFieldFromStrings(message, fields, values); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, variable := range variables {
+		if err := setFieldFromStrings(message, variable.fields, []string{variable.value}); err != nil {
+			return nil, err
+		}
+	}
+	return message, nil
+}
+
+func (r *transcodingRoute) readBody(request *http.Request) ([]byte, error) {
+	reader := request.Body
+	if reader == nil {
+		return nil, nil
+	}
+	if r.readMaxBytes > 0 && int64(r.readMaxBytes) < math.MaxInt64 {
+		reader = io.NopCloser(io.LimitReader(request.Body, int64(r.readMaxBytes)+1))
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		err = wrapIfMaxBytesError(err, "read request body")
+		err = wrapIfContextDone(request.Context(), err)
+		if connectErr, ok := asError(err); ok {
+			return nil, connectErr
+		}
+		return nil, errorf(CodeUnknown, "read request body: %w", err)
+	}
+	if r.readMaxBytes > 0 && len(data) > r.readMaxBytes {
+		return nil, errorf(CodeResourceExhausted, "request body is larger than configured max %d", r.readMaxBytes)
+	}
+	return data, nil
+}
+
+func (r *transcodingRoute) marshalResponse(response protoreflect.Message) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch field := r.responseField; {
+	case field == nil:
+		data, err = protojson.Marshal(response.Interface())
+	case field.Message() != nil && !field.IsList() && !field.IsMap():
+		data, err = protojson.Marshal(response.Get(field).Message().Interface())
+	default:
+		// protojson can't marshal a lone field, so marshal a message with only
+		// that field and unwrap it.
+		wrapper := r.output.New()
+		wrapper.Set(field, response.Get(field))
+		data, err = protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(wrapper.Interface())
+		if err == nil {
+			var fields map[string]json.RawMessage
+			err = json.Unmarshal(data, &fields)
+			data = fields[field.JSONName()]
+		}
+	}
+	if err != nil {
+		return nil, errorf(CodeInternal, "marshal response: %w", err)
+	}
+	return data, nil
+}
+
+// transcodingResponseWriter buffers a unary handler's response.
+type transcodingResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *transcodingResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *transcodingResponseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *transcodingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// messageTypeFor returns the registered Go type for the message, falling
+// back to a dynamic type.
+func messageTypeFor(desc protoreflect.MessageDescriptor) protoreflect.MessageType {
+	if messageType, err := protoregistry.GlobalTypes.FindMessageByName(desc.FullName()); err == nil {
+		return messageType
+	}
+	return dynamicpb.NewMessageType(desc)
+}
+
+// resolveFieldPath resolves a dotted path of field names, like "book.name".
+// Each name may be the field's Protobuf name or its JSON name.
+func resolveFieldPath(desc protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	names := strings.Split(path, ".")
+	fields := make([]protoreflect.FieldDescriptor, 0, len(names))
+	for i, name := range names {
+		field := desc.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			field = desc.Fields().ByJSONName(name)
+		}
+		if field == nil {
+			return nil, fmt.Errorf("unknown field %q in %s", name, desc.FullName())
+		}
+		fields = append(fields, field)
+		if i == len(names)-1 {
+			break
+		}
+		if field.Message() == nil || field.IsList() || field.IsMap() {
+			return nil, fmt.Errorf("field %s is not a singular message", field.FullName())
+		}
+		desc = field.Message()
+	}
+	return fields, nil
+}
+
+// setFieldFromStrings sets the field at the end of a path, creating any
+// intermediate messages. Repeated fields get all the values; singular fields
+// may only have one.
+func setFieldFromStrings(message protoreflect.Message, fields []protoreflect.FieldDescriptor, values []string) error {
+	for _, field := range fields[:len(fields)-1] {
+		message = message.Mutable(field).Message()
+	}
+	field := fields[len(fields)-1]
+	switch {
+	case field.IsMap():
+		return errorf(CodeInvalidArgument, "map field %s can't be set from the URL", field.FullName())
+	case field.IsList():
+		list := message.Mutable(field).List()
+		for _, text := range values {
+			value, err := parseFieldValue(field, list.NewElement(), text)
+			if err != nil {
+				return err
+			}
+			list.Append(value)
+		}
+		return nil
+	case len(values) != 1:
+		return errorf(CodeInvalidArgument, "field %s isn't repeated, but has %d values", field.FullName(), len(values))
+	default:
+		value, err := parseFieldValue(field, message.NewField(field), values[0])
+		if err != nil {
+			return err
+		}
+		message.Set(field, value)
+		return nil
+	}
+}
+
+// parseFieldValue parses text as a value of the field's kind. Message fields
+// are parsed with protojson, so well-known types like Timestamp use their
+// JSON representation. The zero value provides a mutable message for them.
+func parseFieldValue(field protoreflect.FieldDescriptor, zero protoreflect.Value, text string) (protoreflect.Value, error) {
+	value, err := parseScalarValue(field, zero, text)
+	if err != nil {
+		return protoreflect.Value{}, errorf(CodeInvalidArgument, "invalid value %q for field %s: %w", text, field.FullName(), err)
+	}
+	return value, nil
+}
+
+func parseScalarValue(field protoreflect.FieldDescriptor, zero protoreflect.Value, text string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		value, err := strconv.ParseBool(text)
+		return protoreflect.ValueOfBool(value), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		value, err := strconv.ParseInt(text, 10, 32)
+		return protoreflect.ValueOfInt32(int32(value)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		value, err := strconv.ParseInt(text, 10, 64)
+		return protoreflect.ValueOfInt64(value), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		value, err := strconv.ParseUint(text, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(value)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		value, err := strconv.ParseUint(text, 10, 64)
+		return protoreflect.ValueOfUint64(value), err
+	case protoreflect.FloatKind:
+		value, err := strconv.ParseFloat(text, 32)
+		return protoreflect.ValueOfFloat32(float32(value)), err
+	case protoreflect.DoubleKind:
+		value, err := strconv.ParseFloat(text, 64)
+		return protoreflect.ValueOfFloat64(value), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(text), nil
+	case protoreflect.BytesKind:
+		for _, encoding := range []*base64.Encoding{
+			base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding,
+		} {
+			if value, err := encoding.DecodeString(text); err == nil {
+				return protoreflect.ValueOfBytes(value), nil
+			}
+		}
+		return protoreflect.Value{}, errors.New("invalid base64")
+	case protoreflect.EnumKind:
+		if value := field.Enum().Values().ByName(protoreflect.Name(text)); value != nil {
+			return protoreflect.ValueOfEnum(value.Number()), nil
+		}
+		value, err := strconv.ParseInt(text, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(value)), err
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		message := zero.Message().Interface()
+		quoted, err := json.Marshal(text)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		if err := protojson.Unmarshal(quoted, message); err != nil {
+			// Some types, like BoolValue, don't accept quoted JSON.
+			if protojson.Unmarshal([]byte(text), message) != nil {
+				return protoreflect.Value{}, err
+			}
+		}
+		return zero, nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", field.Kind())
+}
+
+// An httpRule is the subset of google.api.HttpRule used for transcoding.
+type httpRule struct {
+	method             string
+	pattern            string
+	body               string
+	responseBody       string
+	additionalBindings []*httpRule
+}
+
+// httpRulesForMethod returns the method's HTTP rule, followed by its
+// additional bindings. It reads the extension from the encoded options, so it
+// works with any generated google.api.http extension, or none at all.
+func httpRulesForMethod(method protoreflect.MethodDescriptor) ([]*httpRule, error) {
+	options, err := proto.MarshalOptions{Deterministic: true}.Marshal(method.Options())
+	if err != nil {
+		return nil, err
+	}
+	var rule *httpRule
+	err = rangeBytesFields(options, func(number protowire.Number, value []byte) error {
+		if number != httpRuleFieldNumber {
+			return nil
+		}
+		if rule == nil {
+			rule = &httpRule{}
+		}
+		// Repeated occurrences of a message field are merged.
+		return rule.unmarshal(value)
+	})
+	if err != nil || rule == nil {
+		return nil, err
+	}
+	rules := []*httpRule{rule}
+	for _, binding := range rule.additionalBindings {
+		if len(binding.additionalBindings) > 0 {
+			return nil, errors.New("additional bindings may not be nested")
+		}
+		rules = append(rules, binding)
+	}
+	return rules, nil
+}
+
+func (r *httpRule) unmarshal(data []byte) error {
+	return rangeBytesFields(data, func(number protowire.Number, value []byte) error {
+		switch number {
+		case 2:
+			r.method, r.pattern = http.MethodGet, string(value)
+		case 3:
+			r.method, r.pattern = http.MethodPut, string(value)
+		case 4:
+			r.method, r.pattern = http.MethodPost, string(value)
+		case 5:
+			r.method, r.pattern = http.MethodDelete, string(value)
+		case 6:
+			r.method, r.pattern = http.MethodPatch, string(value)
+		case 7:
+			r.body = string(value)
+		case 8: // CustomHttpPattern
+			r.method, r.pattern = "", ""
+			return rangeBytesFields(value, func(number protowire.Number, value []byte) error {
+				switch number {
+				case 1:
+					r.method = string(value)
+				case 2:
+					r.pattern = string(value)
+				}
+				return nil
+			})
+		case 11:
+			binding := &httpRule{}
+			if err := binding.unmarshal(value); err != nil {
+				return err
+			}
+			r.additionalBindings = append(r.additionalBindings, binding)
+		case 12:
+			r.responseBody = string(value)
+		}
+		return nil
+	})
+}
+
+// rangeBytesFields calls f for each length-delimited field in an encoded
+// message, skipping fields of other types.
+func rangeBytesFields(data []byte, f func(protowire.Number, []byte) error) error {
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if wireType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := f(number, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A pathTemplate is a parsed google.api.http path template, like
+// "/v1/{name=shelves/*/books/*}:publish".
+type pathTemplate struct {
+	segments  []pathSegment
+	verb      string
+	variables []transcodingVariable
+}
+
+type pathSegment struct {
+	literal  string // empty for wildcards
+	wildcard bool   // "*", one segment
+	multi    bool   // "**", zero or more segments
+}
+
+// A transcodingVariable binds the path segments in [start, end) to a field.
+// When the template is matched, value holds the unescaped segments.
+type transcodingVariable struct {
+	fieldPath  string
+	fields     []protoreflect.FieldDescriptor
+	start, end int
+	value      string
+}
+
+func parsePathTemplate(template string) (*pathTemplate, error) {
+	path, ok := strings.CutPrefix(template, "/")
+	if !ok {
+		return nil, fmt.Errorf("path template %q must start with \"/\"", template)
+	}
+	parsed := &pathTemplate{}
+	if i := strings.LastIndexByte(path, ':'); i >= 0 && !strings.ContainsAny(path[i:], "/}") {
+		path, parsed.verb = path[:i], path[i+1:]
+		if parsed.verb == "" {
+			return nil, fmt.Errorf("path template %q has an empty verb", template)
+		}
+	}
+	seen := make(map[string]struct{})
+	for path != "" || len(parsed.segments) == 0 {
+		if strings.HasPrefix(path, "{") {
+			end := strings.IndexByte(path, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("path template %q has an unterminated variable", template)
+			}
+			fieldPath, segments, hasSegments := strings.Cut(path[1:end], "=")
+			if !hasSegments {
+				segments = "*"
+			}
+			if fieldPath == "" || strings.ContainsAny(segments, "{") {
+				return nil, fmt.Errorf("path template %q has an invalid variable", template)
+			}
+			if _, ok := seen[fieldPath]; ok {
+				return nil, fmt.Errorf("path template %q binds %s more than once", template, fieldPath)
+			}
+			seen[fieldPath] = struct{}{}
+			variable := transcodingVariable{fieldPath: fieldPath, start: len(parsed.segments)}
+			for _, segment := range strings.Split(segments, "/") {
+				parsed.segments = append(parsed.segments, newPathSegment(segment))
+			}
+			variable.end = len(parsed.segments)
+			parsed.variables = append(parsed.variables, variable)
+			path = path[end+1:]
+		} else {
+			segment, _, _ := strings.Cut(path, "/")
+			if strings.ContainsAny(segment, "{}=") {
+				return nil, fmt.Errorf("path template %q has an invalid segment %q", template, segment)
+			}
+			parsed.segments = append(parsed.segments, newPathSegment(segment))
+			path = path[len(segment):]
+		}
+		if path == "" {
+			break
+		}
+		if path, ok = strings.CutPrefix(path, "/"); !ok {
+			return nil, fmt.Errorf("path template %q is missing a \"/\" after a variable", template)
+		}
+		if path == "" {
+			return nil, fmt.Errorf("path template %q has a trailing \"/\"", template)
+		}
+	}
+	for i, segment := range parsed.segments {
+		if segment.literal == "" && !segment.wildcard && !segment.multi {
+			return nil, fmt.Errorf("path template %q has an empty segment", template)
+		}
+		if segment.multi && i != len(parsed.segments)-1 {
+			return nil, fmt.Errorf("path template %q has \"**\" before the last segment", template)
+		}
+	}
+	return parsed, nil
+}
+
+func newPathSegment(segment string) pathSegment {
+	switch segment {
+	case "*":
+		return pathSegment{wildcard: true}
+	case "**":
+		return pathSegment{multi: true}
+	default:
+		return pathSegment{literal: segment}
+	}
+}
+
+// match matches an escaped URL path against the template. If it matches, it
+// returns the template's variables with their values.
+func (t *pathTemplate) match(path string) ([]transcodingVariable, bool) {
+	path, ok := strings.CutPrefix(path, "/")
+	if !ok {
+		return nil, false
+	}
+	if t.verb != "" {
+		if path, ok = strings.CutSuffix(path, ":"+t.verb); !ok {
+			return nil, false
+		}
+	}
+	raw := strings.Split(path, "/")
+	segments := make([]string, len(raw))
+	for i, segment := range raw {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, false
+		}
+		segments[i] = unescaped
+	}
+	end := len(segments) // the end of the segments matched by "**"
+	for i, segment := range t.segments {
+		switch {
+		case segment.multi:
+			if i > len(segments) {
+				return nil, false
+			}
+		case i >= len(segments):
+			return nil, false
+		case segment.wildcard:
+			if segments[i] == "" {
+				return nil, false
+			}
+		case segments[i] != segment.literal:
+			return nil, false
+		}
+	}
+	if len(t.segments) > 0 && !t.segments[len(t.segments)-1].multi && len(segments) != len(t.segments) {
+		return nil, false
+	}
+	variables := make([]transcodingVariable, len(t.variables))
+	for i, variable := range t.variables {
+		if variable.end == len(t.segments) && t.segments[variable.end-1].multi {
+			variable.end = end
+		}
+		variable.value = strings.Join(segments[variable.start:variable.end], "/")
+		variables[i] = variable
+	}
+	return variables, true
+}
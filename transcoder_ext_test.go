@@ -0,0 +1,239 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	connect "github.com/agentio/scalpel"
+	"github.com/agentio/scalpel/internal/assert"
+	transcodingv1 "github.com/agentio/scalpel/internal/gen/connect/transcoding/v1"
+	"github.com/agentio/scalpel/internal/gen/generics/connect/transcoding/v1/transcodingv1connect"
+	"github.com/agentio/scalpel/internal/memhttp"
+	"github.com/agentio/scalpel/internal/memhttp/memhttptest"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTranscoder(t *testing.T) {
+	t.Parallel()
+	library := &transcodingv1connect.FakeLibraryService{
+		GetBookFunc: func(_ context.Context, request *connect.Request[transcodingv1.GetBookRequest]) (*connect.Response[transcodingv1.GetBookResponse], error) {
+			if request.Msg.GetName() != "shelves/1/books/2" {
+				err := connect.NewError(connect.CodeNotFound, errors.New("no such book"))
+				err.Meta().Set("Book-Name", request.Msg.GetName())
+				return nil, err
+			}
+			response := connect.NewResponse(&transcodingv1.GetBookResponse{Book: &transcodingv1.Book{
+				Name:  request.Msg.GetName(),
+				Title: "Dune",
+				Genre: transcodingv1.Genre_GENRE_FICTION,
+			}})
+			response.Header().Set("Echo", request.Header().Get("Echo"))
+			response.Trailer().Set("Result", "found")
+			return response, nil
+		},
+		ListBooksFunc: func(_ context.Context, request *connect.Request[transcodingv1.ListBooksRequest]) (*connect.Response[transcodingv1.ListBooksResponse], error) {
+			return connect.NewResponse(&transcodingv1.ListBooksResponse{Total: request.Msg.GetPageSize()}), nil
+		},
+		CreateBookFunc: func(_ context.Context, request *connect.Request[transcodingv1.CreateBookRequest]) (*connect.Response[transcodingv1.CreateBookResponse], error) {
+			book := proto.CloneOf(request.Msg.GetBook())
+			book.Name = request.Msg.GetParent() + "/books/3"
+			return connect.NewResponse(&transcodingv1.CreateBookResponse{Book: book}), nil
+		},
+		UpdateBookFunc: func(_ context.Context, request *connect.Request[transcodingv1.UpdateBookRequest]) (*connect.Response[transcodingv1.UpdateBookResponse], error) {
+			return connect.NewResponse(&transcodingv1.UpdateBookResponse{Book: request.Msg.GetBook()}), nil
+		},
+		DeleteBookFunc: func(context.Context, *connect.Request[transcodingv1.DeleteBookRequest]) (*connect.Response[transcodingv1.DeleteBookResponse], error) {
+			return connect.NewResponse(&transcodingv1.DeleteBookResponse{}), nil
+		},
+	}
+	mux := connect.NewMux(nil)
+	transcoder := connect.NewTranscoder(mux)
+	mux.HandleService(transcodingv1connect.NewLibraryServiceHandler(
+		library,
+		connect.WithTranscoder(transcoder),
+		connect.WithReadMaxBytes(1024),
+	))
+	server := memhttptest.NewServer(t, transcoder)
+
+	// Each subtest checks the request messages, so they run sequentially.
+	t.Run("get", func(t *testing.T) {
+		library.Recorder.Reset()
+		response, body := doTranscodedRequest(t, server, http.MethodGet, "/v1/shelves/1/books/2", "", "Echo", "hello")
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assert.Equal(t, response.Header.Get("Content-Type"), "application/json")
+		assert.Equal(t, response.Header.Get("Echo"), "hello")
+		assert.Equal(t, response.Header.Get("Trailer-Result"), "found")
+		// The response_body selects the book.
+		assert.Equal(t, body, map[string]any{
+			"name":  "shelves/1/books/2",
+			"title": "Dune",
+			"genre": "GENRE_FICTION",
+		})
+	})
+	t.Run("query", func(t *testing.T) {
+		library.Recorder.Reset()
+		response, body := doTranscodedRequest(t, server, http.MethodGet,
+			"/v1/shelves/1/books?pageSize=10&authors=Le+Guin&authors=Herbert&genre=GENRE_HISTORY", "")
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assert.Equal(t, body, map[string]any{"total": float64(10)})
+		assertTranscodedRequests(t, library, &transcodingv1.ListBooksRequest{
+			Parent:   "shelves/1",
+			PageSize: 10,
+			Authors:  []string{"Le Guin", "Herbert"},
+			Genre:    transcodingv1.Genre_GENRE_HISTORY,
+		})
+	})
+	t.Run("additional_binding", func(t *testing.T) {
+		library.Recorder.Reset()
+		response, _ := doTranscodedRequest(t, server, http.MethodGet, "/v1/books?page_size=5&genre=1", "")
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assertTranscodedRequests(t, library, &transcodingv1.ListBooksRequest{
+			PageSize: 5,
+			Genre:    transcodingv1.Genre_GENRE_FICTION,
+		})
+	})
+	t.Run("body_field", func(t *testing.T) {
+		library.Recorder.Reset()
+		response, body := doTranscodedRequest(t, server, http.MethodPost, "/v1/shelves/1/books", `{"title": "Dune", "pages": 412}`)
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assert.Equal(t, body, map[string]any{
+			"name":  "shelves/1/books/3",
+			"title": "Dune",
+			"pages": float64(412),
+		})
+		assertTranscodedRequests(t, library, &transcodingv1.CreateBookRequest{
+			Parent: "shelves/1",
+			Book:   &transcodingv1.Book{Title: "Dune", Pages: 412},
+		})
+	})
+	t.Run("nested_path_variable", func(t *testing.T) {
+		library.Recorder.Reset()
+		// The path variable takes precedence over the body.
+		response, body := doTranscodedRequest(t, server, http.MethodPatch, "/v1/shelves/1/books/2", `{"name": "ignored", "author": "Herbert"}`)
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assert.Equal(t, body, map[string]any{"name": "shelves/1/books/2", "author": "Herbert"})
+	})
+	t.Run("verb_and_body_star", func(t *testing.T) {
+		library.Recorder.Reset()
+		response, body := doTranscodedRequest(t, server, http.MethodPost, "/v1/shelves/1/books/2:delete", `{"force": true}`)
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assert.Equal(t, body, map[string]any{})
+		response, _ = doTranscodedRequest(t, server, http.MethodDelete, "/v1/shelves/1/books/%32?force=true", "")
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assertTranscodedRequests(t, library,
+			&transcodingv1.DeleteBookRequest{Name: "shelves/1/books/2", Force: true},
+			&transcodingv1.DeleteBookRequest{Name: "shelves/1/books/2", Force: true},
+		)
+	})
+	t.Run("errors", func(t *testing.T) {
+		library.Recorder.Reset()
+		response, body := doTranscodedRequest(t, server, http.MethodGet, "/v1/shelves/1/books/9", "")
+		assert.Equal(t, response.StatusCode, http.StatusNotFound)
+		assert.Equal(t, response.Header.Get("Content-Type"), "application/json")
+		assert.Equal(t, response.Header.Get("Book-Name"), "shelves/1/books/9")
+		assert.Equal(t, body, map[string]any{"code": "not_found", "message": "no such book"})
+
+		// Bad requests don't reach the handler.
+		library.Recorder.Reset()
+		for _, path := range []string{
+			"/v1/shelves/1/books?unknown=1",
+			"/v1/shelves/1/books?pageSize=ten",
+			"/v1/shelves/1/books?genre=GENRE_NOVEL",
+			"/v1/shelves/1/books?pageSize=1&pageSize=2",
+		} {
+			response, body = doTranscodedRequest(t, server, http.MethodGet, path, "")
+			assert.Equal(t, response.StatusCode, http.StatusBadRequest, assert.Sprintf("path %s", path))
+			assert.Equal(t, body["code"], "invalid_argument")
+		}
+		response, body = doTranscodedRequest(t, server, http.MethodPost, "/v1/shelves/1/books", `{"title": 1}`)
+		assert.Equal(t, response.StatusCode, http.StatusBadRequest)
+		assert.Equal(t, body["code"], "invalid_argument")
+		response, body = doTranscodedRequest(t, server, http.MethodPost, "/v1/shelves/1/books", `{"title": "`+strings.Repeat("x", 2048)+`"}`)
+		assert.Equal(t, response.StatusCode, http.StatusTooManyRequests)
+		assert.Equal(t, body["code"], "resource_exhausted")
+		assert.Zero(t, len(library.Recorder.Calls()))
+	})
+	t.Run("fallback", func(t *testing.T) {
+		library.Recorder.Reset()
+		// RPCs are passed to the mux.
+		client := transcodingv1connect.NewLibraryServiceClient(server.Client(), server.URL(), connect.WithGRPC())
+		response, err := client.GetBook(t.Context(), connect.NewRequest(&transcodingv1.GetBookRequest{Name: "shelves/1/books/2"}))
+		assert.Nil(t, err)
+		assert.Equal(t, response.Msg.GetBook().GetTitle(), "Dune")
+		// Rules on streaming methods are ignored, as are unmatched methods.
+		for method, path := range map[string]string{
+			http.MethodGet:  "/v1/shelves/1/books:watch",
+			http.MethodPut:  "/v1/shelves/1/books/2",
+			http.MethodPost: "/v1/shelves/1/books/2:archive",
+		} {
+			httpResponse, _ := doTranscodedRequest(t, server, method, path, "")
+			assert.Equal(t, httpResponse.StatusCode, http.StatusNotFound, assert.Sprintf("%s %s", method, path))
+		}
+	})
+}
+
+func TestTranscoderInvalidRules(t *testing.T) {
+	t.Parallel()
+	transcoder := connect.NewTranscoder(nil)
+	library := &transcodingv1connect.FakeLibraryService{}
+	_, _ = transcodingv1connect.NewLibraryServiceHandler(library, connect.WithTranscoder(transcoder))
+	// Registering the same rules twice panics.
+	assert.Panics(t, func() {
+		_, _ = transcodingv1connect.NewLibraryServiceHandler(library, connect.WithTranscoder(transcoder))
+	})
+	// Handlers without a schema are ignored.
+	connect.NewUnaryHandler(
+		transcodingv1connect.LibraryServiceGetBookProcedure,
+		library.GetBook,
+		connect.WithTranscoder(transcoder),
+	)
+}
+
+func doTranscodedRequest(tb testing.TB, server *memhttp.Server, method, path, body string, header ...string) (*http.Response, map[string]any) {
+	tb.Helper()
+	request, err := http.NewRequestWithContext(tb.Context(), method, server.URL()+path, strings.NewReader(body))
+	assert.Nil(tb, err)
+	for i := 0; i+1 < len(header); i += 2 {
+		request.Header.Set(header[i], header[i+1])
+	}
+	response, err := server.Client().Do(request)
+	assert.Nil(tb, err)
+	data, err := io.ReadAll(response.Body)
+	assert.Nil(tb, err)
+	assert.Nil(tb, response.Body.Close())
+	var decoded map[string]any
+	if response.StatusCode != http.StatusNotFound || response.Header.Get("Content-Type") == "application/json" {
+		assert.Nil(tb, json.Unmarshal(data, &decoded), assert.Sprintf("body %q", data))
+	}
+	return response, decoded
+}
+
+func assertTranscodedRequests(tb testing.TB, library *transcodingv1connect.FakeLibraryService, want ...proto.Message) {
+	tb.Helper()
+	calls := library.Recorder.Calls()
+	assert.Equal(tb, len(calls), len(want))
+	for i, call := range calls {
+		message, ok := call.Request.(proto.Message)
+		assert.True(tb, ok)
+		assert.Equal(tb, message, want[i])
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021-2025 The Connect Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalpel
+
+import (
+	"testing"
+
+	"github.com/agentio/scalpel/internal/assert"
+	transcodingv1 "github.com/agentio/scalpel/internal/gen/connect/transcoding/v1"
+	googleapi "github.com/agentio/scalpel/internal/gen/connectext/google/api"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPathTemplate(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		template string
+		path     string
+		want     map[string]string // nil if the path shouldn't match
+	}{
+		{template: "/v1/books", path: "/v1/books", want: map[string]string{}},
+		{template: "/v1/books", path: "/v1/books/1"},
+		{template: "/v1/books/{id}", path: "/v1/books/1", want: map[string]string{"id": "1"}},
+		{template: "/v1/books/{id}", path: "/v1/books/"},
+		{template: "/v1/books/{id}", path: "/v1/books/a%20b", want: map[string]string{"id": "a b"}},
+		{template: "/v1/{name=shelves/*/books/*}", path: "/v1/shelves/1/books/2", want: map[string]string{"name": "shelves/1/books/2"}},
+		{template: "/v1/{name=shelves/*/books/*}", path: "/v1/shelves/1/notes/2"},
+		{template: "/v1/{name=shelves/*}/books/{book.id}", path: "/v1/shelves/1/books/2", want: map[string]string{"name": "shelves/1", "book.id": "2"}},
+		{template: "/v1/{path=**}", path: "/v1/a/b/c", want: map[string]string{"path": "a/b/c"}},
+		{template: "/v1/files/{path=**}", path: "/v1/files", want: map[string]string{"path": ""}},
+		{template: "/v1/*/books", path: "/v1/anything/books", want: map[string]string{}},
+		{template: "/v1/{name=books/*}:publish", path: "/v1/books/1:publish", want: map[string]string{"name": "books/1"}},
+		{template: "/v1/{name=books/*}:publish", path: "/v1/books/1"},
+		{template: "/v1/{name=books/*}:publish", path: "/v1/books/1:archive"},
+	}
+	for _, testCase := range testCases {
+		template, err := parsePathTemplate(testCase.template)
+		assert.Nil(t, err, assert.Sprintf("template %q", testCase.template))
+		variables, ok := template.match(testCase.path)
+		assert.Equal(t, ok, testCase.want != nil, assert.Sprintf("%q matching %q", testCase.template, testCase.path))
+		if !ok {
+			continue
+		}
+		got := make(map[string]string, len(variables))
+		for _, variable := range variables {
+			got[variable.fieldPath] = variable.value
+		}
+		assert.Equal(t, got, testCase.want)
+	}
+	for _, invalid := range []string{
+		"v1/books",
+		"/v1//books",
+		"/v1/books/",
+		"/v1/{id",
+		"/v1/{=books/*}",
+		"/v1/{id}{name}",
+		"/v1/{id}/{id}",
+		"/v1/**/books",
+		"/v1/books:",
+		"/v1/{name={id}}",
+	} {
+		_, err := parsePathTemplate(invalid)
+		assert.NotNil(t, err, assert.Sprintf("template %q", invalid))
+	}
+}
+
+func TestHTTPRules(t *testing.T) {
+	t.Parallel()
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		method := transcodingv1.File_connect_transcoding_v1_transcoding_proto.
+			Services().ByName("LibraryService").Methods().ByName("DeleteBook")
+		rules, err := httpRulesForMethod(method)
+		assert.Nil(t, err)
+		assert.Equal(t, len(rules), 2)
+		assert.Equal(t, rules[0].method, "DELETE")
+		assert.Equal(t, rules[0].pattern, "/v1/{name=shelves/*/books/*}")
+		assert.Equal(t, rules[0].body, "")
+		assert.Equal(t, rules[1].method, "POST")
+		assert.Equal(t, rules[1].pattern, "/v1/{name=shelves/*/books/*}:delete")
+		assert.Equal(t, rules[1].body, "*")
+	})
+	t.Run("encoded", func(t *testing.T) {
+		t.Parallel()
+		data, err := proto.Marshal(&googleapi.HttpRule{
+			Pattern: &googleapi.HttpRule_Custom{Custom: &googleapi.CustomHttpPattern{
+				Kind: "HEAD",
+				Path: "/v1/books",
+			}},
+			ResponseBody: "books",
+		})
+		assert.Nil(t, err)
+		var rule httpRule
+		assert.Nil(t, rule.unmarshal(data))
+		assert.Equal(t, rule.method, "HEAD")
+		assert.Equal(t, rule.pattern, "/v1/books")
+		assert.Equal(t, rule.responseBody, "books")
+		assert.NotNil(t, rule.unmarshal([]byte{0x0a, 0x05}))
+	})
+}